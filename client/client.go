@@ -1,11 +1,18 @@
 package client
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
+
+	hamfts "hamfts/elasticsearch"
 )
 
 type Client struct {
@@ -13,8 +20,67 @@ type Client struct {
 	httpClient *http.Client
 }
 
+// APIError is returned for error responses. It matches the hamfts sentinel
+// errors with errors.Is, e.g. errors.Is(err, hamfts.ErrNotFound).
+type APIError struct {
+	Op     string // e.g. "get document"
+	Status int
+	Type   string // not_found, conflict, invalid_query, corrupt, ...
+	Reason string
+}
+
+func (e *APIError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("%s failed with status: %d", e.Op, e.Status)
+	}
+	return fmt.Sprintf("%s failed with status: %d: %s", e.Op, e.Status, e.Reason)
+}
+
+func (e *APIError) Is(target error) bool {
+	switch e.Type {
+	case "not_found":
+		return target == hamfts.ErrNotFound
+	case "conflict":
+		return target == hamfts.ErrConflict
+	case "invalid_query":
+		return target == hamfts.ErrInvalidQuery
+	case "corrupt":
+		return target == hamfts.ErrCorrupt
+	case "unsupported":
+		return target == errors.ErrUnsupported
+	case "":
+		// Not a JSON error body, go by the status alone
+		switch e.Status {
+		case http.StatusNotFound:
+			return target == hamfts.ErrNotFound
+		case http.StatusConflict:
+			return target == hamfts.ErrConflict
+		case http.StatusNotImplemented:
+			return target == errors.ErrUnsupported
+		}
+	}
+	return false
+}
+
+// responseError reads the error body of an unsuccessful response
+func responseError(resp *http.Response, op string) error {
+	apiErr := &APIError{Op: op, Status: resp.StatusCode}
+	var body struct {
+		Error struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err == nil {
+		apiErr.Type = body.Error.Type
+		apiErr.Reason = body.Error.Reason
+	}
+	return apiErr
+}
+
 type SearchRequest struct {
-	Query string `json:"query"`
+	Query   string `json:"query"`
+	Suggest bool   `json:"suggest,omitempty"`
 }
 
 type DocumentRequest struct {
@@ -23,6 +89,15 @@ type DocumentRequest struct {
 	Meta    map[string]interface{} `json:"metadata,omitempty"`
 }
 
+// BulkDocument is a document sent by Bulk. CreatedAt defaults to the time
+// the server adds it.
+type BulkDocument struct {
+	ID        string                 `json:"id"`
+	Content   string                 `json:"content"`
+	CreatedAt *time.Time             `json:"createdAt,omitempty"`
+	Meta      map[string]interface{} `json:"metadata,omitempty"`
+}
+
 func NewClient(baseURL string) *Client {
 	return &Client{
 		baseURL: baseURL,
@@ -46,7 +121,7 @@ func (c *Client) Search(query string) ([]interface{}, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("search failed with status: %d", resp.StatusCode)
+		return nil, responseError(resp, "search")
 	}
 
 	var results []interface{}
@@ -57,6 +132,282 @@ func (c *Client) Search(query string) ([]interface{}, error) {
 	return results, nil
 }
 
+// SearchWithSuggestions searches like Search and also returns "did you
+// mean" corrections when nothing matched
+func (c *Client) SearchWithSuggestions(query string) (map[string]interface{}, error) {
+	req := SearchRequest{Query: query, Suggest: true}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Post(c.baseURL+"/search", "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(resp, "search")
+	}
+
+	var result map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// SearchQuery runs a structured query such as
+// {"bool": {"must": [{"term": {"tags.keyword": "go"}}]}} and returns the hits.
+func (c *Client) SearchQuery(query map[string]interface{}) ([]interface{}, error) {
+	body, err := json.Marshal(map[string]interface{}{"query": query})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Post(c.baseURL+"/_search", "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(resp, "search")
+	}
+
+	var result struct {
+		Hits []interface{} `json:"hits"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	return result.Hits, nil
+}
+
+// OpenPointInTime freezes the index for SearchQueryAt and returns the
+// point in time ID. keepAlive is a duration such as "1m".
+func (c *Client) OpenPointInTime(keepAlive string) (string, error) {
+	resp, err := c.httpClient.Post(c.baseURL+"/_pit?keep_alive="+url.QueryEscape(keepAlive), "application/json", nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", responseError(resp, "open point in time")
+	}
+
+	var result struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", err
+	}
+
+	return result.ID, nil
+}
+
+func (c *Client) ClosePointInTime(id string) error {
+	body, err := json.Marshal(map[string]string{"id": id})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodDelete, c.baseURL+"/_pit", bytes.NewBuffer(body))
+	if err != nil {
+		return err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return responseError(resp, "close point in time")
+	}
+
+	return nil
+}
+
+// SearchQueryAt returns size hits of query from offset from, as seen by the
+// point in time pitID, along with the total number of hits. A size of zero
+// returns all hits from the offset on. The point in time is kept alive for
+// keepAlive more, unless it is empty.
+func (c *Client) SearchQueryAt(pitID, keepAlive string, query map[string]interface{}, from, size int) ([]interface{}, int, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"query": query,
+		"pit":   map[string]string{"id": pitID, "keep_alive": keepAlive},
+		"from":  from,
+		"size":  size,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	resp, err := c.httpClient.Post(c.baseURL+"/_search", "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, responseError(resp, "search")
+	}
+
+	var result struct {
+		Total int           `json:"total"`
+		Hits  []interface{} `json:"hits"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, 0, err
+	}
+
+	return result.Hits, result.Total, nil
+}
+
+// Export streams the documents matching query, every document when it is
+// nil, to w as NDJSON and returns how many it wrote. The export is not
+// bounded by the client timeout.
+func (c *Client) Export(query map[string]interface{}, w io.Writer) (int, error) {
+	return c.ExportEach(query, func(doc json.RawMessage) error {
+		if _, err := w.Write(doc); err != nil {
+			return err
+		}
+		_, err := w.Write([]byte{'\n'})
+		return err
+	})
+}
+
+// ExportEach is Export calling fn with each document instead, and stops at
+// the first error fn returns. doc is only valid until fn returns.
+func (c *Client) ExportEach(query map[string]interface{}, fn func(doc json.RawMessage) error) (int, error) {
+	request := map[string]interface{}{}
+	if query != nil {
+		request["query"] = query
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return 0, err
+	}
+
+	stream := *c.httpClient
+	stream.Timeout = 0
+	resp, err := stream.Post(c.baseURL+"/_export", "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, responseError(resp, "export")
+	}
+
+	// A failure after the first document ends the stream with an error line
+	n := 0
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if bytes.HasPrefix(line, []byte(`{"error":`)) {
+			var status struct {
+				Status int `json:"status"`
+			}
+			json.Unmarshal(line, &status)
+			return n, responseError(&http.Response{
+				StatusCode: status.Status,
+				Body:       io.NopCloser(bytes.NewReader(line)),
+			}, "export")
+		}
+		if err := fn(line); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, scanner.Err()
+}
+
+// Bulk adds docs in one request and returns how many were indexed. A
+// document conflicting with the mapping rejects the whole batch.
+func (c *Client) Bulk(docs []BulkDocument) (int, error) {
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	for _, doc := range docs {
+		if err := enc.Encode(doc); err != nil {
+			return 0, err
+		}
+	}
+
+	resp, err := c.httpClient.Post(c.baseURL+"/_bulk", "application/x-ndjson", &body)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, responseError(resp, "bulk")
+	}
+
+	var result struct {
+		Indexed int `json:"indexed"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, err
+	}
+	return result.Indexed, nil
+}
+
+func (c *Client) Suggest(request map[string]interface{}) ([]interface{}, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Post(c.baseURL+"/_suggest", "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(resp, "suggest")
+	}
+
+	var result struct {
+		Suggestions []interface{} `json:"suggestions"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	return result.Suggestions, nil
+}
+
+// MoreLikeThis returns the documents most similar to the given one
+func (c *Client) MoreLikeThis(id string) ([]interface{}, error) {
+	resp, err := c.httpClient.Get(fmt.Sprintf("%s/documents/%s/_mlt", c.baseURL, url.PathEscape(id)))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(resp, "more like this")
+	}
+
+	var result struct {
+		Hits []interface{} `json:"hits"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	return result.Hits, nil
+}
+
 func (c *Client) AddDocument(id, content string, metadata map[string]interface{}) error {
 	req := DocumentRequest{
 		ID:      id,
@@ -75,7 +426,7 @@ func (c *Client) AddDocument(id, content string, metadata map[string]interface{}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusCreated {
-		return fmt.Errorf("add document failed with status: %d", resp.StatusCode)
+		return responseError(resp, "add document")
 	}
 
 	return nil
@@ -89,7 +440,7 @@ func (c *Client) ListDocuments() ([]string, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("list documents failed with status: %d", resp.StatusCode)
+		return nil, responseError(resp, "list documents")
 	}
 
 	var docs []string
@@ -100,8 +451,79 @@ func (c *Client) ListDocuments() ([]string, error) {
 	return docs, nil
 }
 
+// GetDocument fetches a single document. Fields, when given, limit the
+// returned content and metadata to those paths.
+func (c *Client) GetDocument(id string, fields ...string) (map[string]interface{}, error) {
+	u := fmt.Sprintf("%s/documents/%s", c.baseURL, url.PathEscape(id))
+	if len(fields) > 0 {
+		u += "?_source_includes=" + url.QueryEscape(strings.Join(fields, ","))
+	}
+
+	resp, err := c.httpClient.Get(u)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(resp, "get document")
+	}
+
+	var doc map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// DocumentExists checks for a document without fetching it
+func (c *Client) DocumentExists(id string) (bool, error) {
+	resp, err := c.httpClient.Head(fmt.Sprintf("%s/documents/%s", c.baseURL, url.PathEscape(id)))
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	}
+	return false, responseError(resp, "document exists")
+}
+
+// MultiGet fetches several documents in one request. Every returned item
+// has "_id", "found" and, when found, "doc".
+func (c *Client) MultiGet(ids []string) ([]interface{}, error) {
+	body, err := json.Marshal(map[string]interface{}{"ids": ids})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Post(c.baseURL+"/_mget", "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(resp, "multi get")
+	}
+
+	var result struct {
+		Docs []interface{} `json:"docs"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	return result.Docs, nil
+}
+
 func (c *Client) DeleteDocument(id string) error {
-	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/documents/%s", c.baseURL, id), nil)
+	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/documents/%s", c.baseURL, url.PathEscape(id)), nil)
 	if err != nil {
 		return err
 	}
@@ -113,7 +535,247 @@ func (c *Client) DeleteDocument(id string) error {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusNoContent {
-		return fmt.Errorf("delete document failed with status: %d", resp.StatusCode)
+		return responseError(resp, "delete document")
+	}
+
+	return nil
+}
+
+// DeleteByQuery starts deleting the documents matching query and returns
+// the task ID
+func (c *Client) DeleteByQuery(query map[string]interface{}) (string, error) {
+	return c.startByQuery("/_delete_by_query", "delete by query", map[string]interface{}{"query": query})
+}
+
+// UpdateByQuery starts patching the metadata of the documents matching
+// query, e.g. with {"set": {"status": "archived"}, "unset": ["draft"]}, and
+// returns the task ID
+func (c *Client) UpdateByQuery(query, patch map[string]interface{}) (string, error) {
+	return c.startByQuery("/_update_by_query", "update by query", map[string]interface{}{"query": query, "patch": patch})
+}
+
+// Compact starts removing stale records from the document file and returns
+// the task ID
+func (c *Client) Compact() (string, error) {
+	return c.startTask("/_compact", "compact", nil)
+}
+
+// Reindex starts rebuilding the postings from the stored documents with the
+// current analyzers and returns the task ID
+func (c *Client) Reindex() (string, error) {
+	return c.startTask("/_reindex", "reindex", nil)
+}
+
+// ReindexWithAnalysis rebuilds the index in place with new analysis
+// settings, which the index switches to once done, and returns the task ID
+func (c *Client) ReindexWithAnalysis(analysis map[string]interface{}) (string, error) {
+	return c.startByQuery("/_reindex", "reindex", map[string]interface{}{"analysis": analysis})
+}
+
+// ReindexInto starts copying the documents matching query, or all documents
+// when query is nil, into the index directory dest and returns the task ID
+func (c *Client) ReindexInto(dest string, query map[string]interface{}) (string, error) {
+	request := map[string]interface{}{"dest": map[string]interface{}{"index": dest}}
+	if query != nil {
+		request["source"] = map[string]interface{}{"query": query}
+	}
+	return c.startByQuery("/_reindex", "reindex", request)
+}
+
+func (c *Client) startByQuery(path, op string, request map[string]interface{}) (string, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return "", err
+	}
+	return c.startTask(path, op, body)
+}
+
+// startTask posts body to a task endpoint and returns the task ID
+func (c *Client) startTask(path, op string, body []byte) (string, error) {
+	resp, err := c.httpClient.Post(c.baseURL+path, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted {
+		return "", responseError(resp, op)
+	}
+
+	var result struct {
+		Task string `json:"task"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", err
+	}
+
+	return result.Task, nil
+}
+
+// Check verifies the index, or repairs it when repair is set, and returns
+// the report
+func (c *Client) Check(repair bool) (map[string]interface{}, error) {
+	var resp *http.Response
+	var err error
+	if repair {
+		resp, err = c.httpClient.Post(c.baseURL+"/_check/_repair", "application/json", nil)
+	} else {
+		resp, err = c.httpClient.Get(c.baseURL + "/_check")
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(resp, "check")
+	}
+
+	var report map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// ListTasks returns every running and finished task, newest first
+func (c *Client) ListTasks() ([]interface{}, error) {
+	resp, err := c.httpClient.Get(c.baseURL + "/_tasks")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(resp, "list tasks")
+	}
+
+	var result struct {
+		Tasks []interface{} `json:"tasks"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	return result.Tasks, nil
+}
+
+// GetTask returns the status and progress of a task
+func (c *Client) GetTask(id string) (map[string]interface{}, error) {
+	resp, err := c.httpClient.Get(fmt.Sprintf("%s/_tasks/%s", c.baseURL, url.PathEscape(id)))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(resp, "get task")
+	}
+
+	var task map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&task); err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
+
+func (c *Client) CancelTask(id string) error {
+	resp, err := c.httpClient.Post(fmt.Sprintf("%s/_tasks/%s/_cancel", c.baseURL, url.PathEscape(id)), "application/json", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return responseError(resp, "cancel task")
+	}
+
+	return nil
+}
+
+// CreateSnapshot snapshots the index into the server's repository
+func (c *Client) CreateSnapshot(name string) (map[string]interface{}, error) {
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("%s/_snapshot/%s", c.baseURL, url.PathEscape(name)), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(resp, "create snapshot")
+	}
+
+	var info map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+func (c *Client) ListSnapshots() ([]interface{}, error) {
+	resp, err := c.httpClient.Get(c.baseURL + "/_snapshot")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(resp, "list snapshots")
+	}
+
+	var result struct {
+		Snapshots []interface{} `json:"snapshots"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	return result.Snapshots, nil
+}
+
+// RestoreSnapshot restores a snapshot into target, a new directory on the
+// server
+func (c *Client) RestoreSnapshot(name, target string) error {
+	body, err := json.Marshal(map[string]string{"target": target})
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Post(fmt.Sprintf("%s/_snapshot/%s/_restore", c.baseURL, url.PathEscape(name)), "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return responseError(resp, "restore snapshot")
+	}
+
+	return nil
+}
+
+func (c *Client) DeleteSnapshot(name string) error {
+	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/_snapshot/%s", c.baseURL, url.PathEscape(name)), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		return responseError(resp, "delete snapshot")
 	}
 
 	return nil
@@ -127,7 +789,7 @@ func (c *Client) GetStats() (map[string]interface{}, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("get stats failed with status: %d", resp.StatusCode)
+		return nil, responseError(resp, "get stats")
 	}
 
 	var stats map[string]interface{}
@@ -137,3 +799,68 @@ func (c *Client) GetStats() (map[string]interface{}, error) {
 
 	return stats, nil
 }
+
+func (c *Client) GetMapping() (map[string]interface{}, error) {
+	resp, err := c.httpClient.Get(c.baseURL + "/_mapping")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(resp, "get mapping")
+	}
+
+	var mapping map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&mapping); err != nil {
+		return nil, err
+	}
+
+	return mapping, nil
+}
+
+func (c *Client) PutMapping(mapping map[string]interface{}) error {
+	body, err := json.Marshal(mapping)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPut, c.baseURL+"/_mapping", bytes.NewBuffer(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return responseError(resp, "put mapping")
+	}
+
+	return nil
+}
+
+func (c *Client) ReloadSearchAnalyzers() ([]string, error) {
+	resp, err := c.httpClient.Post(c.baseURL+"/_reload_search_analyzers", "application/json", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(resp, "reload search analyzers")
+	}
+
+	var result struct {
+		Reloaded []string `json:"reloaded_analyzers"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	return result.Reloaded, nil
+}
@@ -0,0 +1,84 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	hamfts "hamfts/elasticsearch"
+)
+
+func TestAPIErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   error
+	}{
+		{"not found", http.StatusNotFound, `{"error": {"type": "not_found", "reason": "document \"x\": not found"}, "status": 404}`, hamfts.ErrNotFound},
+		{"conflict", http.StatusConflict, `{"error": {"type": "conflict", "reason": "conflict"}, "status": 409}`, hamfts.ErrConflict},
+		{"invalid query", http.StatusBadRequest, `{"error": {"type": "invalid_query", "reason": "bad"}, "status": 400}`, hamfts.ErrInvalidQuery},
+		{"corrupt", http.StatusInternalServerError, `{"error": {"type": "corrupt", "reason": "bad block"}, "status": 500}`, hamfts.ErrCorrupt},
+		{"unsupported", http.StatusNotImplemented, `{"error": {"type": "unsupported", "reason": "sharded"}, "status": 501}`, errors.ErrUnsupported},
+		{"plain 404", http.StatusNotFound, `404 page not found`, hamfts.ErrNotFound},
+		{"plain 409", http.StatusConflict, ``, hamfts.ErrConflict},
+		{"plain 501", http.StatusNotImplemented, ``, errors.ErrUnsupported},
+	}
+	sentinels := []error{hamfts.ErrNotFound, hamfts.ErrConflict, hamfts.ErrInvalidQuery, hamfts.ErrCorrupt, errors.ErrUnsupported}
+
+	for _, tt := range tests {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tt.status)
+			w.Write([]byte(tt.body))
+		}))
+		_, err := NewClient(srv.URL).GetDocument("x")
+		srv.Close()
+
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.Status != tt.status {
+			t.Errorf("%s: expected an APIError with status %d, got %v", tt.name, tt.status, err)
+			continue
+		}
+		for _, sentinel := range sentinels {
+			if got := errors.Is(err, sentinel); got != (sentinel == tt.want) {
+				t.Errorf("%s: errors.Is(%v) = %v", tt.name, sentinel, got)
+			}
+		}
+	}
+}
+
+func TestPathEscaping(t *testing.T) {
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.EscapedPath())
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL)
+	id := "a/b?c#d%e"
+	c.GetDocument(id)
+	c.DocumentExists(id)
+	c.MoreLikeThis(id)
+	c.DeleteDocument(id)
+	c.GetTask(id)
+	c.CancelTask(id)
+
+	want := []string{
+		"GET /documents/a%2Fb%3Fc%23d%25e",
+		"HEAD /documents/a%2Fb%3Fc%23d%25e",
+		"GET /documents/a%2Fb%3Fc%23d%25e/_mlt",
+		"DELETE /documents/a%2Fb%3Fc%23d%25e",
+		"GET /_tasks/a%2Fb%3Fc%23d%25e",
+		"POST /_tasks/a%2Fb%3Fc%23d%25e/_cancel",
+	}
+	if len(paths) != len(want) {
+		t.Fatalf("expected %v, got %v", want, paths)
+	}
+	for i := range want {
+		if paths[i] != want[i] {
+			t.Errorf("expected %s, got %s", want[i], paths[i])
+		}
+	}
+}
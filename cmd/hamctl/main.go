@@ -19,8 +19,20 @@ func main() {
 		fmt.Println("Commands:")
 		fmt.Println("  search <query>")
 		fmt.Println("  add <id> <content> [metadata]")
+		fmt.Println("  get <id> [field...]")
 		fmt.Println("  list")
 		fmt.Println("  delete <id>")
+		fmt.Println("  mlt <id>")
+		fmt.Println("  export [--query JSON] [--format ndjson|csv|json] [--fields id,content,...]")
+		fmt.Println("  import [--format ndjson|csv|json] [--map column=field,...] [--progress file] [--resume] <file|->")
+		fmt.Println("  compact")
+		fmt.Println("  reindex [--analysis JSON | dest-dir [query]]")
+		fmt.Println("  check [--repair]")
+		fmt.Println("  tasks [id]")
+		fmt.Println("  cancel <task-id>")
+		fmt.Println("  snapshot create|delete <name>")
+		fmt.Println("  snapshot list")
+		fmt.Println("  snapshot restore <name> <target-dir>")
 		fmt.Println("  stats")
 		os.Exit(1)
 	}
@@ -62,6 +74,18 @@ func main() {
 		}
 		fmt.Println("Document added successfully")
 
+	case "get":
+		if len(flag.Args()) < 2 {
+			fmt.Println("Usage: hamctl get <id> [field...]")
+			os.Exit(1)
+		}
+		doc, err := c.GetDocument(flag.Args()[1], flag.Args()[2:]...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Get document failed: %v\n", err)
+			os.Exit(1)
+		}
+		printJSON(doc)
+
 	case "list":
 		docs, err := c.ListDocuments()
 		if err != nil {
@@ -82,6 +106,105 @@ func main() {
 		}
 		fmt.Println("Document deleted successfully")
 
+	case "mlt":
+		if len(flag.Args()) < 2 {
+			fmt.Println("Usage: hamctl mlt <id>")
+			os.Exit(1)
+		}
+		results, err := c.MoreLikeThis(flag.Args()[1])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "More like this failed: %v\n", err)
+			os.Exit(1)
+		}
+		printJSON(results)
+
+	case "export":
+		runExport(c, flag.Args()[1:])
+
+	case "import":
+		runImport(c, flag.Args()[1:])
+
+	case "compact":
+		id, err := c.Compact()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Compact failed: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Compaction started as task %s\n", id)
+
+	case "reindex":
+		var id string
+		var err error
+		switch {
+		case len(flag.Args()) == 1:
+			id, err = c.Reindex()
+		case flag.Args()[1] == "--analysis":
+			if len(flag.Args()) < 3 {
+				fmt.Println("Usage: hamctl reindex --analysis <settings>")
+				os.Exit(1)
+			}
+			var analysis map[string]interface{}
+			if err := json.Unmarshal([]byte(flag.Args()[2]), &analysis); err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid analysis JSON: %v\n", err)
+				os.Exit(1)
+			}
+			id, err = c.ReindexWithAnalysis(analysis)
+		case len(flag.Args()) == 2:
+			id, err = c.ReindexInto(flag.Args()[1], nil)
+		default:
+			var query map[string]interface{}
+			if err := json.Unmarshal([]byte(flag.Args()[2]), &query); err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid query JSON: %v\n", err)
+				os.Exit(1)
+			}
+			id, err = c.ReindexInto(flag.Args()[1], query)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Reindex failed: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Reindex started as task %s\n", id)
+
+	case "check":
+		repair := len(flag.Args()) > 1 && flag.Args()[1] == "--repair"
+		report, err := c.Check(repair)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Check failed: %v\n", err)
+			os.Exit(1)
+		}
+		printJSON(report)
+		if problems, _ := report["problems"].([]interface{}); len(problems) > 0 && !repair {
+			os.Exit(2)
+		}
+
+	case "tasks":
+		var result interface{}
+		var err error
+		if len(flag.Args()) > 1 {
+			result, err = c.GetTask(flag.Args()[1])
+		} else {
+			result, err = c.ListTasks()
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Get tasks failed: %v\n", err)
+			os.Exit(1)
+		}
+		printJSON(result)
+
+	case "cancel":
+		if len(flag.Args()) < 2 {
+			fmt.Println("Usage: hamctl cancel <task-id>")
+			os.Exit(1)
+		}
+		if err := c.CancelTask(flag.Args()[1]); err != nil {
+			fmt.Fprintf(os.Stderr, "Cancel task failed: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("Task cancelled")
+
+	case "snapshot":
+		snapshot(c, flag.Args()[1:])
+
 	case "stats":
 		stats, err := c.GetStats()
 		if err != nil {
@@ -101,3 +224,62 @@ func printJSON(v interface{}) {
 	encoder.SetIndent("", "  ")
 	encoder.Encode(v)
 }
+
+func snapshot(c *client.Client, args []string) {
+	usage := func() {
+		fmt.Println("Usage: hamctl snapshot create|delete <name>")
+		fmt.Println("       hamctl snapshot list")
+		fmt.Println("       hamctl snapshot restore <name> <target-dir>")
+		os.Exit(1)
+	}
+	if len(args) < 1 {
+		usage()
+	}
+
+	switch args[0] {
+	case "create":
+		if len(args) < 2 {
+			usage()
+		}
+		info, err := c.CreateSnapshot(args[1])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Create snapshot failed: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Snapshot %s created: %v documents, %v new bytes\n", args[1], info["document_count"], info["added_bytes"])
+
+	case "list":
+		snapshots, err := c.ListSnapshots()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "List snapshots failed: %v\n", err)
+			os.Exit(1)
+		}
+		for _, s := range snapshots {
+			info := s.(map[string]interface{})
+			fmt.Printf("%s\t%v\t%v documents\n", info["name"], info["start_time"], info["document_count"])
+		}
+
+	case "restore":
+		if len(args) < 3 {
+			usage()
+		}
+		if err := c.RestoreSnapshot(args[1], args[2]); err != nil {
+			fmt.Fprintf(os.Stderr, "Restore snapshot failed: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Snapshot %s restored to %s\n", args[1], args[2])
+
+	case "delete":
+		if len(args) < 2 {
+			usage()
+		}
+		if err := c.DeleteSnapshot(args[1]); err != nil {
+			fmt.Fprintf(os.Stderr, "Delete snapshot failed: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("Snapshot deleted successfully")
+
+	default:
+		usage()
+	}
+}
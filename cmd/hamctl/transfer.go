@@ -0,0 +1,635 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"hamfts/client"
+)
+
+// Documents are imported from and exported to NDJSON (one document per
+// line, as /_export writes them), JSON arrays and CSV. In CSV files one
+// column holds the document ID, one the content and the others metadata
+// fields, named after the column unless --map renames them. Dotted field
+// names build metadata objects.
+
+const (
+	formatNDJSON = "ndjson"
+	formatCSV    = "csv"
+	formatJSON   = "json"
+)
+
+// createdColumn is the CSV column holding the creation time of a document
+const createdColumn = "createdAt"
+
+// fileFormat returns format when set, or the format the extension of path
+// names
+func fileFormat(format, path string) (string, error) {
+	switch format {
+	case formatNDJSON, formatCSV, formatJSON:
+		return format, nil
+	case "":
+	default:
+		return "", fmt.Errorf("unknown format %q, expected ndjson, csv or json", format)
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ndjson", ".jsonl":
+		return formatNDJSON, nil
+	case ".csv":
+		return formatCSV, nil
+	case ".json":
+		return formatJSON, nil
+	}
+	if path == "-" {
+		return "", fmt.Errorf("set --format to read standard input")
+	}
+	return "", fmt.Errorf("cannot tell the format of %s, set --format", path)
+}
+
+// fieldTypes returns the types of the mapped metadata fields, by dotted path
+func fieldTypes(c *client.Client) (map[string]string, error) {
+	mapping, err := c.GetMapping()
+	if err != nil {
+		return nil, err
+	}
+	types := make(map[string]string)
+	properties, _ := mapping["properties"].(map[string]interface{})
+	for field, fm := range properties {
+		if fm, ok := fm.(map[string]interface{}); ok {
+			types[field], _ = fm["type"].(string)
+		}
+	}
+	return types, nil
+}
+
+// documentReader returns the documents of an import file one at a time,
+// and io.EOF after the last
+type documentReader interface {
+	next() (*client.BulkDocument, error)
+}
+
+type ndjsonReader struct {
+	r    *bufio.Reader
+	line int
+}
+
+func (d *ndjsonReader) next() (*client.BulkDocument, error) {
+	for {
+		line, err := d.r.ReadBytes('\n')
+		if err != nil && (err != io.EOF || len(line) == 0) {
+			return nil, err
+		}
+		d.line++
+		if line = bytes.TrimSpace(line); len(line) == 0 {
+			continue
+		}
+		doc := &client.BulkDocument{}
+		dec := json.NewDecoder(bytes.NewReader(line))
+		dec.UseNumber()
+		if err := dec.Decode(doc); err != nil {
+			return nil, fmt.Errorf("line %d: %v", d.line, err)
+		}
+		return doc, nil
+	}
+}
+
+type jsonArrayReader struct {
+	dec     *json.Decoder
+	started bool
+}
+
+func (d *jsonArrayReader) next() (*client.BulkDocument, error) {
+	if !d.started {
+		if tok, err := d.dec.Token(); err != nil {
+			return nil, err
+		} else if tok != json.Delim('[') {
+			return nil, errors.New("expected a JSON array of documents")
+		}
+		d.started = true
+	}
+	if !d.dec.More() {
+		if _, err := d.dec.Token(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+	doc := &client.BulkDocument{}
+	if err := d.dec.Decode(doc); err != nil {
+		return nil, fmt.Errorf("offset %d: %v", d.dec.InputOffset(), err)
+	}
+	return doc, nil
+}
+
+type csvReader struct {
+	r       *csv.Reader
+	types   map[string]string
+	id      int // column indexes, -1 when absent
+	content int
+	created int
+	fields  []string // metadata field of each column, "" when it has none
+}
+
+// newCSVReader reads the header of r. columns maps column names to fields,
+// where a field of "-" skips the column.
+func newCSVReader(r io.Reader, idColumn, contentColumn string, columns map[string]string, types map[string]string) (*csvReader, error) {
+	d := &csvReader{r: csv.NewReader(r), types: types, id: -1, content: -1, created: -1}
+	header, err := d.r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("header: %v", err)
+	}
+	d.fields = make([]string, len(header))
+	for i, name := range header {
+		switch {
+		case name == idColumn:
+			d.id = i
+		case name == contentColumn:
+			d.content = i
+		case name == createdColumn:
+			d.created = i
+		case columns[name] == "-":
+		case columns[name] != "":
+			d.fields[i] = columns[name]
+		default:
+			d.fields[i] = name
+		}
+	}
+	if d.id < 0 {
+		return nil, fmt.Errorf("no %q column for the document ID", idColumn)
+	}
+	return d, nil
+}
+
+func (d *csvReader) next() (*client.BulkDocument, error) {
+	record, err := d.r.Read()
+	if err != nil {
+		return nil, err
+	}
+	line, _ := d.r.FieldPos(0)
+	doc := &client.BulkDocument{ID: record[d.id], Meta: make(map[string]interface{})}
+	if d.content >= 0 {
+		doc.Content = record[d.content]
+	}
+	if d.created >= 0 && record[d.created] != "" {
+		created, err := time.Parse(time.RFC3339Nano, record[d.created])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		doc.CreatedAt = &created
+	}
+	for i, field := range d.fields {
+		if field == "" || record[i] == "" {
+			continue
+		}
+		value, err := d.value(field, record[i])
+		if err != nil {
+			return nil, fmt.Errorf("line %d, column %d: field %s: %v", line, i+1, field, err)
+		}
+		if err := setPath(doc.Meta, field, value); err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+	}
+	return doc, nil
+}
+
+// value converts a cell to the mapped type of field. Cells holding a JSON
+// array or object, as export writes such values, are decoded.
+func (d *csvReader) value(field, cell string) (interface{}, error) {
+	switch d.types[field] {
+	case "long":
+		return strconv.ParseInt(cell, 10, 64)
+	case "double":
+		return strconv.ParseFloat(cell, 64)
+	case "boolean":
+		return strconv.ParseBool(cell)
+	}
+	if cell[0] == '[' || cell[0] == '{' {
+		var v interface{}
+		dec := json.NewDecoder(strings.NewReader(cell))
+		dec.UseNumber()
+		if dec.Decode(&v) == nil && !dec.More() {
+			return v, nil
+		}
+	}
+	return cell, nil
+}
+
+// setPath sets the dotted path field of meta, creating objects on the way
+func setPath(meta map[string]interface{}, field string, value interface{}) error {
+	parts := strings.Split(field, ".")
+	for _, part := range parts[:len(parts)-1] {
+		child, ok := meta[part]
+		if !ok {
+			child = make(map[string]interface{})
+			meta[part] = child
+		}
+		if meta, ok = child.(map[string]interface{}); !ok {
+			return fmt.Errorf("field %s: %s is not an object", field, part)
+		}
+	}
+	meta[parts[len(parts)-1]] = value
+	return nil
+}
+
+// getPath returns the value at the dotted path field of meta
+func getPath(meta map[string]interface{}, field string) interface{} {
+	var value interface{} = meta
+	for _, part := range strings.Split(field, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = object[part]
+	}
+	return value
+}
+
+// importProgress is saved in the progress file after each batch, so that an
+// interrupted import can go on with --resume. Size and ModTime are those of
+// the imported file, and zero for standard input.
+type importProgress struct {
+	Size      int64     `json:"size"`
+	ModTime   time.Time `json:"modTime"`
+	Documents int       `json:"documents"`
+}
+
+func saveProgress(progressPath string, p importProgress) error {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	tmp := progressPath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, progressPath)
+}
+
+// loadProgress returns the progress saved in progressPath, as long as the
+// imported file has not changed since. Standard input, which has no info,
+// is trusted to be the same.
+func loadProgress(progressPath, path string, info os.FileInfo) (importProgress, error) {
+	var p importProgress
+	data, err := os.ReadFile(progressPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return p, fmt.Errorf("no saved progress in %s", progressPath)
+	} else if err != nil {
+		return p, err
+	}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return p, fmt.Errorf("%s: %v", progressPath, err)
+	}
+	if info != nil && (p.Size != info.Size() || !p.ModTime.Equal(info.ModTime())) {
+		return p, fmt.Errorf("%s changed since the import was interrupted, start over without --resume", path)
+	}
+	return p, nil
+}
+
+type importOptions struct {
+	format        string
+	idColumn      string
+	contentColumn string
+	columns       map[string]string
+	batchSize     int
+	progress      string // file to save the progress in, none when empty
+	resume        bool
+}
+
+// importFile sends the documents of path, or of standard input when path is
+// -, to the bulk endpoint in batches, saving its progress after each
+func importFile(c *client.Client, path string, opts importOptions) (int, error) {
+	format, err := fileFormat(opts.format, path)
+	if err != nil {
+		return 0, err
+	}
+	var in io.Reader = os.Stdin
+	var info os.FileInfo
+	progress := importProgress{}
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return 0, err
+		}
+		defer f.Close()
+		if info, err = f.Stat(); err != nil {
+			return 0, err
+		}
+		in = f
+		progress = importProgress{Size: info.Size(), ModTime: info.ModTime()}
+	}
+	if opts.resume {
+		if opts.progress == "" {
+			return 0, fmt.Errorf("--resume needs --progress to read standard input")
+		}
+		if progress, err = loadProgress(opts.progress, path, info); err != nil {
+			return 0, err
+		}
+	}
+
+	var docs documentReader
+	switch format {
+	case formatNDJSON:
+		docs = &ndjsonReader{r: bufio.NewReaderSize(in, 1<<20)}
+	case formatJSON:
+		dec := json.NewDecoder(bufio.NewReaderSize(in, 1<<20))
+		dec.UseNumber()
+		docs = &jsonArrayReader{dec: dec}
+	case formatCSV:
+		types, err := fieldTypes(c)
+		if err != nil {
+			return 0, err
+		}
+		if docs, err = newCSVReader(bufio.NewReaderSize(in, 1<<20), opts.idColumn, opts.contentColumn, opts.columns, types); err != nil {
+			return 0, err
+		}
+	}
+
+	// Documents sent before the import was interrupted are read again but
+	// not sent
+	read := 0
+	batch := make([]client.BulkDocument, 0, opts.batchSize)
+	send := func() error {
+		if _, err := c.Bulk(batch); err != nil {
+			return fmt.Errorf("documents %d to %d: %w", progress.Documents+1, read, err)
+		}
+		progress.Documents = read
+		batch = batch[:0]
+		fmt.Fprintf(os.Stderr, "\rImported %d documents", read)
+		if opts.progress == "" {
+			return nil
+		}
+		return saveProgress(opts.progress, progress)
+	}
+	for {
+		doc, err := docs.next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return progress.Documents, fmt.Errorf("document %d: %v", read+1, err)
+		}
+		read++
+		if read <= progress.Documents {
+			continue
+		}
+		if doc.ID == "" {
+			return progress.Documents, fmt.Errorf("document %d has no id", read)
+		}
+		if batch = append(batch, *doc); len(batch) == opts.batchSize {
+			if err := send(); err != nil {
+				return progress.Documents, err
+			}
+		}
+	}
+	if len(batch) > 0 {
+		if err := send(); err != nil {
+			return progress.Documents, err
+		}
+	}
+	if progress.Documents > 0 {
+		fmt.Fprintln(os.Stderr)
+	}
+	if opts.progress != "" {
+		os.Remove(opts.progress)
+	}
+	return progress.Documents, nil
+}
+
+// parseColumns parses column=field pairs separated by commas
+func parseColumns(s string) (map[string]string, error) {
+	columns := make(map[string]string)
+	if s == "" {
+		return columns, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		column, field, ok := strings.Cut(pair, "=")
+		if !ok || column == "" || field == "" {
+			return nil, fmt.Errorf("invalid column mapping %q, expected column=field", pair)
+		}
+		columns[column] = field
+	}
+	return columns, nil
+}
+
+func runImport(c *client.Client, args []string) {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	format := flags.String("format", "", "ndjson, csv or json; by default from the file extension")
+	idColumn := flags.String("id-column", "id", "CSV column holding the document ID")
+	contentColumn := flags.String("content-column", "content", "CSV column holding the document content")
+	columns := flags.String("map", "", "CSV columns to rename, as column=field,...; a field of - skips the column")
+	batchSize := flags.Int("batch", 500, "Documents per bulk request")
+	progress := flags.String("progress", "", "File to save the progress in; <file>.progress by default, none for standard input")
+	resume := flags.Bool("resume", false, "Go on from the progress saved by an interrupted import")
+	flags.Parse(args)
+	if flags.NArg() != 1 || *batchSize < 1 {
+		fmt.Println("Usage: hamctl import [--format ndjson|csv|json] [--id-column id] [--content-column content] [--map column=field,...] [--batch 500] [--progress file] [--resume] <file|->")
+		os.Exit(1)
+	}
+	mapping, err := parseColumns(*columns)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Import failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	path := flags.Arg(0)
+	if *progress == "" && path != "-" {
+		*progress = path + ".progress"
+	}
+	n, err := importFile(c, path, importOptions{
+		format:        *format,
+		idColumn:      *idColumn,
+		contentColumn: *contentColumn,
+		columns:       mapping,
+		batchSize:     *batchSize,
+		progress:      *progress,
+		resume:        *resume,
+	})
+	if err != nil {
+		if n > 0 {
+			fmt.Fprintln(os.Stderr)
+		}
+		fmt.Fprintf(os.Stderr, "Import failed after %d documents: %v\n", n, err)
+		if n > 0 && *progress != "" {
+			fmt.Fprintf(os.Stderr, "Run it again with --resume to go on from there\n")
+		}
+		os.Exit(1)
+	}
+	if path == "-" {
+		path = "standard input"
+	}
+	fmt.Printf("Imported %d documents from %s\n", n, path)
+}
+
+// exportedDocument decodes a line of /_export, keeping numbers as written
+type exportedDocument struct {
+	ID        string
+	Content   string
+	CreatedAt time.Time
+	Metadata  map[string]interface{}
+}
+
+func decodeExported(raw json.RawMessage) (*exportedDocument, error) {
+	doc := &exportedDocument{}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	return doc, dec.Decode(doc)
+}
+
+// exportFields returns the default CSV columns: the ID, the content and the
+// mapped fields that hold values rather than objects
+func exportFields(c *client.Client) ([]string, error) {
+	types, err := fieldTypes(c)
+	if err != nil {
+		return nil, err
+	}
+	var fields []string
+	for field, t := range types {
+		if t == "object" || t == "nested" {
+			continue
+		}
+		nested := false
+		for parent, t := range types {
+			if t == "nested" && strings.HasPrefix(field, parent+".") {
+				nested = true
+			}
+		}
+		if !nested {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+	return append([]string{"id", "content"}, fields...), nil
+}
+
+// cell formats the value of field for a CSV column
+func (doc *exportedDocument) cell(field string) (string, error) {
+	var value interface{}
+	switch field {
+	case "id":
+		return doc.ID, nil
+	case "content":
+		return doc.Content, nil
+	case createdColumn:
+		return doc.CreatedAt.Format(time.RFC3339Nano), nil
+	default:
+		value = getPath(doc.Metadata, field)
+	}
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	}
+	data, err := json.Marshal(value)
+	return string(data), err
+}
+
+func exportCSV(c *client.Client, query map[string]interface{}, fields []string, out io.Writer) (int, error) {
+	if len(fields) == 0 {
+		var err error
+		if fields, err = exportFields(c); err != nil {
+			return 0, err
+		}
+	}
+	w := csv.NewWriter(out)
+	if err := w.Write(fields); err != nil {
+		return 0, err
+	}
+	record := make([]string, len(fields))
+	n, err := c.ExportEach(query, func(raw json.RawMessage) error {
+		doc, err := decodeExported(raw)
+		if err != nil {
+			return err
+		}
+		for i, field := range fields {
+			if record[i], err = doc.cell(field); err != nil {
+				return err
+			}
+		}
+		return w.Write(record)
+	})
+	w.Flush()
+	if err == nil {
+		err = w.Error()
+	}
+	return n, err
+}
+
+func exportJSONArray(c *client.Client, query map[string]interface{}, out io.Writer) (int, error) {
+	if _, err := io.WriteString(out, "["); err != nil {
+		return 0, err
+	}
+	sep := "\n"
+	n, err := c.ExportEach(query, func(doc json.RawMessage) error {
+		if _, err := io.WriteString(out, sep); err != nil {
+			return err
+		}
+		sep = ",\n"
+		_, err := out.Write(doc)
+		return err
+	})
+	if err != nil {
+		return n, err
+	}
+	_, err = io.WriteString(out, "\n]\n")
+	return n, err
+}
+
+func runExport(c *client.Client, args []string) {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	queryJSON := flags.String("query", "", "Query selecting the documents, all of them by default")
+	format := flags.String("format", formatNDJSON, "ndjson, csv or json")
+	fields := flags.String("fields", "", "CSV columns, as id, content, createdAt or metadata fields; by default id, content and the mapped fields")
+	flags.Parse(args)
+	if flags.NArg() != 0 {
+		fmt.Println("Usage: hamctl export [--query JSON] [--format ndjson|csv|json] [--fields id,content,...] > file")
+		os.Exit(1)
+	}
+
+	var query map[string]interface{}
+	if *queryJSON != "" {
+		if err := json.Unmarshal([]byte(*queryJSON), &query); err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid query JSON: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	out := bufio.NewWriter(os.Stdout)
+	var n int
+	var err error
+	switch *format {
+	case formatNDJSON:
+		n, err = c.Export(query, out)
+	case formatCSV:
+		var columns []string
+		if *fields != "" {
+			columns = strings.Split(*fields, ",")
+		}
+		n, err = exportCSV(c, query, columns, out)
+	case formatJSON:
+		n, err = exportJSONArray(c, query, out)
+	default:
+		err = fmt.Errorf("unknown format %q, expected ndjson, csv or json", *format)
+	}
+	if flushErr := out.Flush(); err == nil {
+		err = flushErr
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Export failed after %d documents: %v\n", n, err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "Exported %d documents\n", n)
+}
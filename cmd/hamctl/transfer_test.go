@@ -0,0 +1,302 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"hamfts/client"
+)
+
+func TestCSVReader(t *testing.T) {
+	created := time.Date(2024, 5, 1, 12, 30, 0, 500, time.UTC)
+	tests := []struct {
+		name    string
+		csv     string
+		id      string
+		content string
+		columns map[string]string
+		types   map[string]string
+		want    []client.BulkDocument
+		err     string
+	}{
+		{
+			name:  "mapped types and JSON cells",
+			csv:   "id,content,price,tags,active\n1,hello,3,\"[\"\"a\"\",\"\"b\"\"]\",true\n",
+			types: map[string]string{"price": "long", "active": "boolean"},
+			want: []client.BulkDocument{
+				{ID: "1", Content: "hello", Meta: map[string]interface{}{"price": int64(3), "tags": []interface{}{"a", "b"}, "active": true}},
+			},
+		},
+		{
+			name:    "renamed and skipped columns",
+			csv:     "sku,title,notes,brand\nA1,Kettle,fragile,acme\nA2,Mug,,\n",
+			id:      "sku",
+			content: "title",
+			columns: map[string]string{"notes": "-", "brand": "maker.name"},
+			want: []client.BulkDocument{
+				{ID: "A1", Content: "Kettle", Meta: map[string]interface{}{"maker": map[string]interface{}{"name": "acme"}}},
+				{ID: "A2", Content: "Mug", Meta: map[string]interface{}{}},
+			},
+		},
+		{
+			name: "creation time",
+			csv:  "id,content,createdAt\n1,x," + created.Format(time.RFC3339Nano) + "\n2,y,\n",
+			want: []client.BulkDocument{
+				{ID: "1", Content: "x", CreatedAt: &created, Meta: map[string]interface{}{}},
+				{ID: "2", Content: "y", Meta: map[string]interface{}{}},
+			},
+		},
+		{
+			name: "no ID column",
+			csv:  "key,content\n1,x\n",
+			err:  `no "id" column`,
+		},
+		{
+			name:  "cell not of the mapped type",
+			csv:   "id,content,price\n1,x,3\n2,y,cheap\n",
+			types: map[string]string{"price": "long"},
+			want:  []client.BulkDocument{{ID: "1", Content: "x", Meta: map[string]interface{}{"price": int64(3)}}},
+			err:   "line 3, column 3: field price",
+		},
+		{
+			name: "invalid creation time",
+			csv:  "id,createdAt\n1,yesterday\n",
+			err:  "line 2",
+		},
+		{
+			name: "field under a value",
+			csv:  "id,a,a.b\n1,x,y\n",
+			err:  "a is not an object",
+		},
+	}
+
+	for _, tt := range tests {
+		if tt.id == "" {
+			tt.id = "id"
+		}
+		if tt.content == "" {
+			tt.content = "content"
+		}
+		var got []client.BulkDocument
+		d, err := newCSVReader(strings.NewReader(tt.csv), tt.id, tt.content, tt.columns, tt.types)
+		for err == nil {
+			var doc *client.BulkDocument
+			if doc, err = d.next(); err == nil {
+				got = append(got, *doc)
+			}
+		}
+		if tt.err == "" && err != io.EOF {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		} else if tt.err != "" && (err == io.EOF || !strings.Contains(err.Error(), tt.err)) {
+			t.Errorf("%s: expected an error containing %q, got %v", tt.name, tt.err, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: expected %+v, got %+v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestSetPath(t *testing.T) {
+	tests := []struct {
+		name  string
+		meta  map[string]interface{}
+		field string
+		want  map[string]interface{}
+		err   bool
+	}{
+		{"top level", map[string]interface{}{}, "a", map[string]interface{}{"a": 1}, false},
+		{"new objects", map[string]interface{}{}, "a.b.c", map[string]interface{}{"a": map[string]interface{}{"b": map[string]interface{}{"c": 1}}}, false},
+		{"existing object", map[string]interface{}{"a": map[string]interface{}{"x": 2}}, "a.b", map[string]interface{}{"a": map[string]interface{}{"x": 2, "b": 1}}, false},
+		{"overwrite", map[string]interface{}{"a": 2}, "a", map[string]interface{}{"a": 1}, false},
+		{"through a value", map[string]interface{}{"a": "text"}, "a.b", map[string]interface{}{"a": "text"}, true},
+	}
+	for _, tt := range tests {
+		err := setPath(tt.meta, tt.field, 1)
+		if (err != nil) != tt.err {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(tt.meta, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, tt.meta)
+		}
+	}
+}
+
+func TestParseColumns(t *testing.T) {
+	tests := []struct {
+		in   string
+		want map[string]string
+		err  bool
+	}{
+		{"", map[string]string{}, false},
+		{"notes=-", map[string]string{"notes": "-"}, false},
+		{"brand=maker.name,notes=-", map[string]string{"brand": "maker.name", "notes": "-"}, false},
+		{"brand", nil, true},
+		{"=maker", nil, true},
+		{"brand=", nil, true},
+		{"brand=maker,", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := parseColumns(tt.in)
+		if (err != nil) != tt.err || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseColumns(%q): got %v, %v, want %v", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestLoadProgress(t *testing.T) {
+	testDir, err := os.MkdirTemp("", "hamfts_test_progress")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+
+	path := filepath.Join(testDir, "docs.ndjson")
+	if err := os.WriteFile(path, []byte(`{"id": "1"}`+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := importProgress{Size: info.Size(), ModTime: info.ModTime(), Documents: 1}
+
+	tests := []struct {
+		name     string
+		progress string // written to the progress file, which is missing when empty
+		info     os.FileInfo
+		err      string
+	}{
+		{"missing", "", info, "no saved progress"},
+		{"not JSON", "{", info, "progress"},
+		{"same file", mustJSON(t, saved), info, ""},
+		{"changed file", mustJSON(t, importProgress{Size: info.Size() + 1, ModTime: info.ModTime(), Documents: 1}), info, "changed since"},
+		{"standard input", mustJSON(t, importProgress{Documents: 1}), nil, ""},
+	}
+	for _, tt := range tests {
+		progressPath := filepath.Join(testDir, "docs.progress")
+		os.Remove(progressPath)
+		if tt.progress != "" {
+			if err := os.WriteFile(progressPath, []byte(tt.progress), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		p, err := loadProgress(progressPath, path, tt.info)
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		case tt.err == "" && p.Documents != 1:
+			t.Errorf("%s: expected 1 document done, got %+v", tt.name, p)
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("%s: expected an error containing %q, got %v", tt.name, tt.err, err)
+		}
+	}
+}
+
+func mustJSON(t *testing.T, v interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// bulkServer records the IDs of the documents sent to /_bulk. The request
+// failAt, counting from one, fails once.
+type bulkServer struct {
+	mu       sync.Mutex
+	requests int
+	failAt   int
+	ids      []string
+}
+
+func (s *bulkServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/_bulk" {
+		http.NotFound(w, r)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests++
+	if s.requests == s.failAt {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"error": {"type": "internal", "reason": "disk full"}, "status": 500}`))
+		return
+	}
+	n := 0
+	scanner := bufio.NewScanner(r.Body)
+	for scanner.Scan() {
+		var doc client.BulkDocument
+		if err := json.Unmarshal(scanner.Bytes(), &doc); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.ids = append(s.ids, doc.ID)
+		n++
+	}
+	json.NewEncoder(w).Encode(map[string]int{"indexed": n})
+}
+
+func TestImportResume(t *testing.T) {
+	testDir, err := os.MkdirTemp("", "hamfts_test_import")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+
+	path := filepath.Join(testDir, "docs.ndjson")
+	var lines, want []string
+	for i := 1; i <= 10; i++ {
+		id := string(rune('a' + i - 1))
+		lines = append(lines, `{"id": "`+id+`", "content": "doc `+id+`"}`)
+		want = append(want, id)
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	bulk := &bulkServer{failAt: 2}
+	srv := httptest.NewServer(bulk)
+	defer srv.Close()
+	c := client.NewClient(srv.URL)
+	opts := importOptions{batchSize: 3, progress: path + ".progress"}
+
+	// The second batch fails, after the first was saved as done
+	n, err := importFile(c, path, opts)
+	if err == nil || n != 3 {
+		t.Fatalf("expected the import to stop after 3 documents, got %d, %v", n, err)
+	}
+	if p, err := loadProgress(opts.progress, path, nil); err != nil || p.Documents != 3 {
+		t.Fatalf("saved progress: got %+v, %v", p, err)
+	}
+
+	// Resuming sends the rest, each document once
+	opts.resume = true
+	if n, err := importFile(c, path, opts); err != nil || n != 10 {
+		t.Fatalf("resume: got %d, %v", n, err)
+	}
+	if !reflect.DeepEqual(bulk.ids, want) {
+		t.Errorf("expected %v to be sent once each, got %v", want, bulk.ids)
+	}
+	if _, err := os.Stat(opts.progress); !os.IsNotExist(err) {
+		t.Errorf("expected the progress file to be removed, got %v", err)
+	}
+
+	// Nothing to resume from once the import finished
+	if _, err := importFile(c, path, opts); err == nil || !strings.Contains(err.Error(), "no saved progress") {
+		t.Errorf("resume a finished import: got %v", err)
+	}
+	if _, err := importFile(c, "-", importOptions{format: formatNDJSON, batchSize: 3, resume: true}); err == nil {
+		t.Error("expected resuming standard input without a progress file to fail")
+	}
+}
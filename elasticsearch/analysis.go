@@ -0,0 +1,470 @@
+package hamfts
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Token is a term produced by a tokenizer. Start and End are byte offsets
+// of the source text, so filters can tell whether two tokens were adjacent.
+type Token struct {
+	Term  string
+	Start int
+	End   int
+	Type  string
+	// Slot groups the tokens a synonym filter emitted for one span of the
+	// input. Tokens in a slot with the same Alt form one alternative, and a
+	// query matches the slot when any alternative matches. Zero means the
+	// token is a slot of its own.
+	Slot int
+	Alt  int
+}
+
+// Token types set by the tokenizers
+const (
+	TokenWord        = "word"
+	TokenNumber      = "number"
+	TokenIdeographic = "ideographic"
+	TokenHiragana    = "hiragana"
+	TokenKatakana    = "katakana"
+	TokenHangul      = "hangul"
+)
+
+// Tokenizer splits text into raw tokens
+type Tokenizer func(text string) []Token
+
+// TokenFilter rewrites a token stream, e.g. lowercasing or stemming it
+type TokenFilter func(tokens []Token) []Token
+
+// Analyzer turns text into the terms stored in and looked up from the index.
+// A field is searched with the analyzer it was indexed with unless a search
+// analyzer is configured for it.
+type Analyzer struct {
+	tokenizer Tokenizer
+	filters   []TokenFilter
+	// updateable is set when the analyzer uses filters that can be reloaded,
+	// which restricts it to search time.
+	updateable bool
+}
+
+func (a *Analyzer) Analyze(text string) []string {
+	tokens := a.AnalyzeTokens(text)
+	terms := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		if token.Term != "" {
+			terms = append(terms, token.Term)
+		}
+	}
+	return terms
+}
+
+// AnalyzeTokens is like Analyze but keeps token offsets and types
+func (a *Analyzer) AnalyzeTokens(text string) []Token {
+	tokens := a.tokenizer(text)
+	for _, filter := range a.filters {
+		tokens = filter(tokens)
+	}
+	return tokens
+}
+
+// AnalyzerConfig defines a custom analyzer from named components
+type AnalyzerConfig struct {
+	Tokenizer string   `json:"tokenizer"`
+	Filters   []string `json:"filter,omitempty"`
+}
+
+// FilterConfig defines a custom token filter of type "synonym", "ngram" or
+// "edge_ngram".
+type FilterConfig struct {
+	Type string `json:"type"`
+	// Synonyms holds inline rules in Solr format
+	Synonyms []string `json:"synonyms,omitempty"`
+	// SynonymsPath names a rules file relative to the index's analysis directory
+	SynonymsPath string `json:"synonyms_path,omitempty"`
+	// Format of the rules, "solr" (default) or "wordnet"
+	Format string `json:"format,omitempty"`
+	// Expand maps equivalent synonyms to each other rather than to the first
+	// one. It defaults to true.
+	Expand *bool `json:"expand,omitempty"`
+	// Updateable filters are reloaded by ReloadSearchAnalyzers and can only
+	// be used in search analyzers.
+	Updateable bool `json:"updateable,omitempty"`
+
+	// MinGram and MaxGram size the grams of ngram and edge_ngram filters.
+	// They default to 1 and 2.
+	MinGram int `json:"min_gram,omitempty"`
+	MaxGram int `json:"max_gram,omitempty"`
+	// PreserveOriginal also keeps tokens that are shorter or longer than
+	// the gram sizes
+	PreserveOriginal bool `json:"preserve_original,omitempty"`
+}
+
+// AnalysisSettings selects the default analyzers of an index and defines
+// custom analyzers and filters that fields can refer to by name.
+type AnalysisSettings struct {
+	Analyzer       string                    `json:"analyzer,omitempty"`
+	SearchAnalyzer string                    `json:"search_analyzer,omitempty"` // defaults to Analyzer
+	Analyzers      map[string]AnalyzerConfig `json:"analyzers,omitempty"`
+	Filters        map[string]FilterConfig   `json:"filters,omitempty"`
+}
+
+const defaultAnalyzerName = "standard"
+
+var tokenizers = map[string]Tokenizer{
+	"standard": standardTokenizer,
+	"unicode":  unicodeTokenizer,
+}
+
+var tokenFilters = map[string]TokenFilter{
+	"lowercase":          lowercaseFilter,
+	"english_possessive": possessiveFilter,
+	"porter_stem":        stemFilter(porterStem),
+	"english_stem":       stemFilter(porterStem),
+	"french_stem":        stemFilter(frenchMinimalStem),
+	"german_stem":        stemFilter(germanLightStem),
+	"spanish_stem":       stemFilter(spanishLightStem),
+	"italian_stem":       stemFilter(italianLightStem),
+	"french_elision":     elisionFilter(elisionArticles["french"]),
+	"italian_elision":    elisionFilter(elisionArticles["italian"]),
+	"nfkc":               nfkcFilter,
+	"asciifolding":       asciiFoldingFilter,
+	"cjk_bigram":         cjkBigramFilter,
+	"ngram":              ngramFilter(defaultMinGram, defaultMaxGram, false),
+	"edge_ngram":         edgeNgramFilter(defaultMinGram, defaultMaxGram, false),
+}
+
+func init() {
+	for lang, words := range stopWords {
+		tokenFilters[lang+"_stop"] = stopFilter(words)
+	}
+}
+
+// builtinAnalyzers are always available and cannot be redefined
+var builtinAnalyzers = map[string]AnalyzerConfig{
+	"standard": {Tokenizer: "standard", Filters: []string{"lowercase"}},
+	"english":  {Tokenizer: "standard", Filters: []string{"lowercase", "english_possessive", "english_stop", "porter_stem"}},
+	"french":   {Tokenizer: "standard", Filters: []string{"lowercase", "french_elision", "french_stop", "french_stem"}},
+	"german":   {Tokenizer: "standard", Filters: []string{"lowercase", "german_stop", "german_stem"}},
+	"spanish":  {Tokenizer: "standard", Filters: []string{"lowercase", "spanish_stop", "spanish_stem"}},
+	"italian":  {Tokenizer: "standard", Filters: []string{"lowercase", "italian_elision", "italian_stop", "italian_stem"}},
+	"unicode":  {Tokenizer: "unicode", Filters: []string{"nfkc", "lowercase", "asciifolding"}},
+	"cjk":      {Tokenizer: "unicode", Filters: []string{"nfkc", "lowercase", "cjk_bigram"}},
+}
+
+// sameAnalyzer reports whether an analyzer is defined identically in both
+// settings, including the custom filters it uses
+func (s AnalysisSettings) sameAnalyzer(other AnalysisSettings, name string) bool {
+	config, builtin := builtinAnalyzers[name]
+	if !builtin {
+		var ok bool
+		if config, ok = s.Analyzers[name]; !ok {
+			return false
+		}
+		otherConfig, ok := other.Analyzers[name]
+		if !ok || !reflect.DeepEqual(config, otherConfig) {
+			return false
+		}
+	}
+	for _, filter := range config.Filters {
+		if !reflect.DeepEqual(s.Filters[filter], other.Filters[filter]) {
+			return false
+		}
+	}
+	return true
+}
+
+// analysis holds the analyzers resolved from an index's settings
+type analysis struct {
+	settings        AnalysisSettings
+	dir             string // where synonym files are looked up
+	defaultAnalyzer *Analyzer
+	searchAnalyzer  *Analyzer
+	analyzers       map[string]*Analyzer
+	// synonyms caches loaded rules by "analyzer/filter" so that a reload
+	// only re-reads updateable filters
+	synonyms map[string]*synonymMap
+}
+
+// newAnalysis builds the analyzers for settings. Rules of non-updateable
+// synonym filters are taken from previous when it is given.
+func newAnalysis(settings AnalysisSettings, dir string, previous *analysis) (*analysis, error) {
+	a := &analysis{
+		settings:  settings,
+		dir:       dir,
+		analyzers: make(map[string]*Analyzer),
+		synonyms:  make(map[string]*synonymMap),
+	}
+	for name, config := range builtinAnalyzers {
+		analyzer, err := a.buildAnalyzer(name, config, previous)
+		if err != nil {
+			return nil, err
+		}
+		a.analyzers[name] = analyzer
+	}
+
+	for name := range settings.Filters {
+		if _, ok := tokenFilters[name]; ok {
+			return nil, fmt.Errorf("token filter %q is built in and cannot be redefined", name)
+		}
+	}
+	for name, config := range settings.Analyzers {
+		if _, ok := builtinAnalyzers[name]; ok {
+			return nil, fmt.Errorf("analyzer %q is built in and cannot be redefined", name)
+		}
+		analyzer, err := a.buildAnalyzer(name, config, previous)
+		if err != nil {
+			return nil, fmt.Errorf("analyzer %q: %w", name, err)
+		}
+		a.analyzers[name] = analyzer
+	}
+
+	name := settings.Analyzer
+	if name == "" {
+		name = defaultAnalyzerName
+	}
+	var err error
+	if a.defaultAnalyzer, err = a.indexAnalyzer(name); err != nil {
+		return nil, err
+	}
+
+	a.searchAnalyzer = a.defaultAnalyzer
+	if settings.SearchAnalyzer != "" {
+		analyzer, ok := a.analyzers[settings.SearchAnalyzer]
+		if !ok {
+			return nil, fmt.Errorf("unknown analyzer %q", settings.SearchAnalyzer)
+		}
+		a.searchAnalyzer = analyzer
+	}
+	return a, nil
+}
+
+func (a *analysis) buildAnalyzer(name string, config AnalyzerConfig, previous *analysis) (*Analyzer, error) {
+	tokenizerName := config.Tokenizer
+	if tokenizerName == "" {
+		tokenizerName = "standard"
+	}
+	tokenizer, ok := tokenizers[tokenizerName]
+	if !ok {
+		return nil, fmt.Errorf("unknown tokenizer %q", tokenizerName)
+	}
+
+	analyzer := &Analyzer{tokenizer: tokenizer}
+	for _, filterName := range config.Filters {
+		if filter, ok := tokenFilters[filterName]; ok {
+			analyzer.filters = append(analyzer.filters, filter)
+			continue
+		}
+
+		fc, ok := a.settings.Filters[filterName]
+		if !ok {
+			return nil, fmt.Errorf("unknown token filter %q", filterName)
+		}
+		switch fc.Type {
+		case "synonym":
+			key := name + "/" + filterName
+			rules, cached := (*synonymMap)(nil), false
+			if previous != nil && !fc.Updateable {
+				rules, cached = previous.synonyms[key]
+			}
+			if !cached {
+				// Rules are analyzed with the part of the chain before the filter
+				partial := &Analyzer{tokenizer: tokenizer, filters: append([]TokenFilter(nil), analyzer.filters...)}
+				var err error
+				if rules, err = loadSynonyms(fc, a.dir, partial); err != nil {
+					return nil, fmt.Errorf("token filter %q: %w", filterName, err)
+				}
+			}
+			a.synonyms[key] = rules
+			analyzer.filters = append(analyzer.filters, rules.filter)
+			analyzer.updateable = analyzer.updateable || fc.Updateable
+		case "ngram", "edge_ngram":
+			min, max, err := gramSizes(fc)
+			if err != nil {
+				return nil, fmt.Errorf("token filter %q: %w", filterName, err)
+			}
+			if fc.Type == "ngram" {
+				analyzer.filters = append(analyzer.filters, ngramFilter(min, max, fc.PreserveOriginal))
+			} else {
+				analyzer.filters = append(analyzer.filters, edgeNgramFilter(min, max, fc.PreserveOriginal))
+			}
+		default:
+			return nil, fmt.Errorf("token filter %q has unknown type %q", filterName, fc.Type)
+		}
+	}
+	return analyzer, nil
+}
+
+// indexAnalyzer looks up an analyzer that is about to be used at index time
+func (a *analysis) indexAnalyzer(name string) (*Analyzer, error) {
+	analyzer, ok := a.analyzers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown analyzer %q", name)
+	}
+	if analyzer.updateable {
+		return nil, fmt.Errorf("analyzer %q uses updateable filters and can only be a search analyzer", name)
+	}
+	return analyzer, nil
+}
+
+// forField returns the analyzer a text field is indexed with, falling back
+// to the index default
+func (a *analysis) forField(fm *FieldMapping) *Analyzer {
+	if fm != nil && fm.Analyzer != "" {
+		if analyzer, ok := a.analyzers[fm.Analyzer]; ok {
+			return analyzer
+		}
+	}
+	return a.defaultAnalyzer
+}
+
+// forSearch returns the analyzer query text for a field is run through.
+// A nil field means the document content.
+func (a *analysis) forSearch(fm *FieldMapping) *Analyzer {
+	if fm == nil {
+		return a.searchAnalyzer
+	}
+	if fm.SearchAnalyzer != "" {
+		if analyzer, ok := a.analyzers[fm.SearchAnalyzer]; ok {
+			return analyzer
+		}
+	}
+	if fm.Analyzer != "" {
+		return a.forField(fm)
+	}
+	return a.searchAnalyzer
+}
+
+// names returns every analyzer name known to the index
+func (a *analysis) names() []string {
+	names := make([]string, 0, len(a.analyzers))
+	for name := range a.analyzers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// querySlots groups analyzed query tokens into slots of alternatives. A
+// document matches the query when, for every slot, it contains all terms of
+// at least one alternative.
+func querySlots(tokens []Token) [][][]string {
+	var slots [][][]string
+	index := make(map[int]int) // token slot -> position in slots
+	for _, token := range tokens {
+		if token.Term == "" {
+			continue
+		}
+		if token.Slot == 0 {
+			slots = append(slots, [][]string{{token.Term}})
+			continue
+		}
+
+		i, ok := index[token.Slot]
+		if !ok {
+			i = len(slots)
+			index[token.Slot] = i
+			slots = append(slots, nil)
+		}
+		for len(slots[i]) <= token.Alt {
+			slots[i] = append(slots[i], nil)
+		}
+		slots[i][token.Alt] = append(slots[i][token.Alt], token.Term)
+	}
+
+	// Drop alternatives that lost all their terms, e.g. to a stop filter
+	result := slots[:0]
+	for _, alts := range slots {
+		kept := alts[:0]
+		for _, alt := range alts {
+			if len(alt) > 0 {
+				kept = append(kept, alt)
+			}
+		}
+		if len(kept) > 0 {
+			result = append(result, kept)
+		}
+	}
+	return result
+}
+
+// standardTokenizer splits text on whitespace and trims surrounding punctuation
+func standardTokenizer(text string) []Token {
+	var tokens []Token
+	start := -1
+	for i, r := range text + " " {
+		if !unicode.IsSpace(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start < 0 {
+			continue
+		}
+
+		word := text[start:i]
+		trimmed := strings.TrimLeft(word, ",.!?")
+		offset := start + len(word) - len(trimmed)
+		trimmed = strings.TrimRight(trimmed, ",.!?")
+		if trimmed != "" {
+			tokens = append(tokens, Token{Term: trimmed, Start: offset, End: offset + len(trimmed), Type: TokenWord})
+		}
+		start = -1
+	}
+	return tokens
+}
+
+// termFilter applies fn to the term of every token
+func termFilter(fn func(string) string) TokenFilter {
+	return func(tokens []Token) []Token {
+		for i := range tokens {
+			tokens[i].Term = fn(tokens[i].Term)
+		}
+		return tokens
+	}
+}
+
+var lowercaseFilter = termFilter(strings.ToLower)
+
+func stopFilter(words map[string]struct{}) TokenFilter {
+	return func(tokens []Token) []Token {
+		kept := tokens[:0]
+		for _, token := range tokens {
+			if _, stop := words[token.Term]; !stop {
+				kept = append(kept, token)
+			}
+		}
+		return kept
+	}
+}
+
+func stemFilter(stem func(string) string) TokenFilter {
+	return termFilter(stem)
+}
+
+// possessiveFilter strips a trailing 's from English tokens
+var possessiveFilter = termFilter(func(term string) string {
+	for _, suffix := range []string{"'s", "’s"} {
+		if strings.HasSuffix(term, suffix) {
+			return strings.TrimSuffix(term, suffix)
+		}
+	}
+	return term
+})
+
+// elisionFilter removes elided articles such as the l' in "l'avion"
+func elisionFilter(articles map[string]struct{}) TokenFilter {
+	return termFilter(func(term string) string {
+		if j := strings.IndexAny(term, "'’"); j > 0 {
+			_, size := utf8.DecodeRuneInString(term[j:])
+			if _, ok := articles[term[:j]]; ok && j+size < len(term) {
+				return term[j+size:]
+			}
+		}
+		return term
+	})
+}
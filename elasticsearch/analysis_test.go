@@ -0,0 +1,133 @@
+package hamfts
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestPorterStem(t *testing.T) {
+	tests := map[string]string{
+		"caresses":        "caress",
+		"ponies":          "poni",
+		"cats":            "cat",
+		"agreed":          "agre",
+		"motoring":        "motor",
+		"hopping":         "hop",
+		"filing":          "file",
+		"happy":           "happi",
+		"relational":      "relat",
+		"generalizations": "gener",
+		"running":         "run",
+		"runs":            "run",
+		"oscillators":     "oscil",
+		"adoption":        "adopt",
+		"controll":        "control",
+	}
+	for word, want := range tests {
+		if got := porterStem(word); got != want {
+			t.Errorf("porterStem(%q) = %q, want %q", word, got, want)
+		}
+	}
+}
+
+func TestLanguageAnalyzers(t *testing.T) {
+	a, err := newAnalysis(AnalysisSettings{}, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		analyzer string
+		text     string
+		want     []string
+	}{
+		{"standard", "The Running Dogs!", []string{"the", "running", "dogs"}},
+		{"english", "The dog's running", []string{"dog", "run"}},
+		{"french", "L'avion des chevaux", []string{"avion", "cheval"}},
+		{"german", "Die Häuser", []string{"haus"}},
+		{"spanish", "Las luces", []string{"luz"}},
+		{"italian", "Il ragazzo e le ragazze", []string{"ragazz", "ragazz"}},
+	}
+	for _, tt := range tests {
+		got := a.analyzers[tt.analyzer].Analyze(tt.text)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s.Analyze(%q) = %v, want %v", tt.analyzer, tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestIndexAnalyzer(t *testing.T) {
+	testDir, err := os.MkdirTemp("", "hamfts_test_analysis")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+
+	idx, err := NewIndexWithOptions(testDir, IndexOptions{
+		Analysis: &AnalysisSettings{Analyzer: "english"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := idx.PutMapping(Mapping{Fields: map[string]*FieldMapping{
+		"title": {Type: FieldText, Analyzer: "french"},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+
+	doc := NewDocument("1", "She runs every morning")
+	doc.Metadata["title"] = "Les chevaux"
+	if err := idx.AddDocument(doc); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, query := range []string{"running", "run", "the runs"} {
+		results, err := idx.Search(query, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(results) != 1 {
+			t.Errorf("Search(%q) got %d results, want 1", query, len(results))
+		}
+	}
+
+	results, err := idx.SearchQuery(Query{Match: map[string]string{"title": "cheval"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 {
+		t.Errorf("Expected field analyzer to match, got %d results", len(results))
+	}
+
+	if err := idx.PutMapping(Mapping{Fields: map[string]*FieldMapping{
+		"summary": {Type: FieldText, Analyzer: "klingon"},
+	}}); err == nil {
+		t.Error("expected unknown analyzer to be rejected")
+	}
+
+	// The analyzer is persisted and cannot change once documents exist
+	if err := idx.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewIndexWithOptions(testDir, IndexOptions{
+		Analysis: &AnalysisSettings{Analyzer: "standard"},
+	}); err == nil {
+		t.Error("expected analyzer change on a non-empty index to fail")
+	}
+
+	idx, err = NewIndex(testDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+
+	results, err = idx.Search("running", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 {
+		t.Errorf("Expected persisted analyzer after reopening, got %d results", len(results))
+	}
+}
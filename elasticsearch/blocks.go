@@ -0,0 +1,252 @@
+package hamfts
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"encoding/gob"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"sort"
+	"sync"
+)
+
+// In the block format docs.dat is a sequence of blocks, each a checksummed
+// record whose payload is a deflate compressed list of gob encoded
+// documents:
+//
+//	uvarint count, count uvarint lengths, the documents
+//
+// Documents sharing a block share the compression, so the type information
+// gob repeats for each of them costs next to nothing. Fetching a document
+// decompresses its block only, and recently used blocks are cached.
+//
+// New documents are appended to docs.tail as checksummed records. Once it
+// reaches blockSize, saveMetadata packs its live documents into blocks at
+// the end of docs.dat and empties it.
+
+// blockSize is the amount of encoded documents gathered into a block
+const blockSize = 16 << 10
+
+// A block location holds the offset of the block in its high bits and the
+// slot of the document in its low blockSlotBits
+const blockSlotBits = 16
+
+// tailLocation marks locations of records in docs.tail
+const tailLocation = 1 << 62
+
+// cachedBlocks is how many decoded blocks an index keeps
+const cachedBlocks = 64
+
+func blockOffset(loc int64) int64 { return loc >> blockSlotBits }
+
+// block is a decoded block
+type block struct {
+	pos     int64   // in docs.dat
+	stored  int64   // bytes taken in docs.dat, header included
+	data    []byte  // decompressed payload
+	offsets []int64 // start of each document in data; one more marks the end
+}
+
+func (b *block) document(loc int64) (*Document, error) {
+	slot := loc & (1<<blockSlotBits - 1)
+	if slot >= int64(len(b.offsets)-1) {
+		return nil, fmt.Errorf("%w: block at offset %d has no slot %d", ErrCorrupt, b.pos, slot)
+	}
+	return decodeDocument(b.data[b.offsets[slot]:b.offsets[slot+1]], b.pos)
+}
+
+// readBlock reads, checks and decompresses the block at pos
+func readBlock(r io.ReaderAt, pos int64) (*block, error) {
+	payload, err := readPayload(r, pos)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(io.LimitReader(flate.NewReader(bytes.NewReader(payload)), maxRecordSize))
+	if err != nil {
+		return nil, fmt.Errorf("%w: block at offset %d: %v", ErrCorrupt, pos, err)
+	}
+
+	b := &block{pos: pos, stored: recordHeaderSize + int64(len(payload)), data: data}
+	rd := bytes.NewReader(data)
+	count, err := binary.ReadUvarint(rd)
+	if err != nil || count == 0 || count > 1<<blockSlotBits {
+		return nil, fmt.Errorf("%w: block at offset %d has a bad document count", ErrCorrupt, pos)
+	}
+	lengths := make([]uint64, count)
+	for i := range lengths {
+		if lengths[i], err = binary.ReadUvarint(rd); err != nil {
+			return nil, fmt.Errorf("%w: block at offset %d has a bad length table", ErrCorrupt, pos)
+		}
+	}
+	start := int64(len(data)) - int64(rd.Len())
+	b.offsets = append(make([]int64, 0, count+1), start)
+	for _, n := range lengths {
+		start += int64(n)
+		if start > int64(len(data)) {
+			return nil, fmt.Errorf("%w: block at offset %d is shorter than its documents", ErrCorrupt, pos)
+		}
+		b.offsets = append(b.offsets, start)
+	}
+	return b, nil
+}
+
+// blockWriter gathers documents into blocks written to w, which is at
+// offset end
+type blockWriter struct {
+	w    io.Writer
+	end  int64
+	docs [][]byte
+	size int
+}
+
+// add encodes doc into the current block and returns its location. The
+// block is written once it is full.
+func (bw *blockWriter) add(doc *Document) (int64, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(doc); err != nil {
+		return 0, err
+	}
+	loc := bw.end<<blockSlotBits | int64(len(bw.docs))
+	bw.docs = append(bw.docs, buf.Bytes())
+	bw.size += buf.Len()
+	if bw.size >= blockSize || len(bw.docs) == 1<<blockSlotBits {
+		return loc, bw.flush()
+	}
+	return loc, nil
+}
+
+// flush writes the current block, if it holds any document
+func (bw *blockWriter) flush() error {
+	if len(bw.docs) == 0 {
+		return nil
+	}
+	raw := binary.AppendUvarint(nil, uint64(len(bw.docs)))
+	for _, doc := range bw.docs {
+		raw = binary.AppendUvarint(raw, uint64(len(doc)))
+	}
+	for _, doc := range bw.docs {
+		raw = append(raw, doc...)
+	}
+
+	var record bytes.Buffer
+	record.Write(make([]byte, recordHeaderSize))
+	zw, err := flate.NewWriter(&record, flate.DefaultCompression)
+	if err != nil {
+		return err
+	}
+	if _, err := zw.Write(raw); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	data := record.Bytes()
+	payload := data[recordHeaderSize:]
+	binary.LittleEndian.PutUint32(data[0:4], uint32(len(payload)))
+	binary.LittleEndian.PutUint32(data[4:8], crc32.Checksum(payload, crcTable))
+
+	if _, err := bw.w.Write(data); err != nil {
+		return err
+	}
+	bw.end += int64(len(data))
+	bw.docs, bw.size = nil, 0
+	return nil
+}
+
+// blockCache keeps recently decoded blocks. Readers share it under the
+// index read lock, so it has its own mutex. A nil cache caches nothing.
+type blockCache struct {
+	mu     sync.Mutex
+	blocks map[int64]*block
+}
+
+func newBlockCache() *blockCache {
+	return &blockCache{blocks: make(map[int64]*block)}
+}
+
+func (c *blockCache) get(pos int64) *block {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.blocks[pos]
+}
+
+func (c *blockCache) put(pos int64, b *block) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.blocks) >= cachedBlocks {
+		// Any block will do; the cache only spares repeated decompression
+		for old := range c.blocks {
+			delete(c.blocks, old)
+			break
+		}
+	}
+	c.blocks[pos] = b
+}
+
+// packTail moves the live documents of docs.tail into blocks at the end of
+// docs.dat once it has grown to blockSize. It reports whether it did, in
+// which case the caller empties docs.tail after saving the metadata. It is
+// called with the write lock held, and not while a compaction or reindex
+// needs the records where they are.
+func (idx *Index) packTail() (bool, error) {
+	if idx.metadata.RecordFormat != recordFormatBlocks || idx.rewriting.Load() {
+		return false, nil
+	}
+	info, err := idx.tailFile.Stat()
+	if err != nil || info.Size() < blockSize {
+		return false, err
+	}
+
+	var nums []int64
+	for num, loc := range idx.metadata.DocumentLocations {
+		if loc != deletedLocation && loc&tailLocation != 0 {
+			nums = append(nums, int64(num))
+		}
+	}
+	locations := idx.metadata.DocumentLocations
+	sort.Slice(nums, func(i, j int) bool { return locations[nums[i]] < locations[nums[j]] })
+
+	end, err := idx.docFile.Seek(0, io.SeekEnd)
+	if err != nil {
+		return false, err
+	}
+	bw := &blockWriter{w: idx.docFile, end: end}
+	moved := make(map[int64]int64, len(nums))
+	live := int64(0)
+	for _, num := range nums {
+		pos := locations[num] &^ tailLocation
+		payload, err := readPayload(idx.tailFile, pos)
+		if err != nil {
+			return false, err
+		}
+		doc, err := decodeDocument(payload, pos)
+		if err != nil {
+			return false, err
+		}
+		if moved[num], err = bw.add(doc); err != nil {
+			return false, err
+		}
+		live += recordHeaderSize + int64(len(payload))
+	}
+	if err := bw.flush(); err != nil {
+		return false, err
+	}
+	// The blocks must be on disk before the metadata points at them
+	if err := idx.docFile.Sync(); err != nil {
+		return false, err
+	}
+
+	for num, loc := range moved {
+		idx.metadata.DocumentLocations[num] = loc
+	}
+	idx.metadata.DeadBytes = max(idx.metadata.DeadBytes-(info.Size()-live), 0)
+	return true, nil
+}
@@ -0,0 +1,78 @@
+package hamfts
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBlockFormat(t *testing.T) {
+	testDir, err := os.MkdirTemp("", "hamfts_test_blocks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+
+	idx, err := NewIndexWithOptions(testDir, IndexOptions{AutoCompactRatio: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw := 0
+	for i := 0; i < 500; i++ {
+		doc := NewDocument(fmt.Sprintf("doc%d", i), strings.Repeat(fmt.Sprintf("block number %d of text ", i%7), 10))
+		doc.Metadata["n"] = i
+		record, err := encodeRecord(doc, recordFormatChecksummed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		raw += len(record)
+		if err := idx.AddDocument(doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tail, err := os.Stat(filepath.Join(testDir, "documents", "docs.tail"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tail.Size() >= blockSize {
+		t.Errorf("docs.tail was not packed: %d bytes", tail.Size())
+	}
+	if stored := idx.storedBytes(); stored*4 > int64(raw) {
+		t.Errorf("expected blocks to take under a quarter of %d bytes, got %d", raw, stored)
+	}
+	if report, err := idx.Verify(); err != nil || !report.OK() || report.Records != 500 {
+		t.Errorf("verify: got %+v, %v", report, err)
+	}
+
+	// Deleting a document in a block charges its share of the block
+	if err := idx.DeleteDocument("doc3"); err != nil {
+		t.Fatal(err)
+	}
+	if dead := idx.metadata.DeadBytes; dead <= 0 || dead > idx.storedBytes()/10 {
+		t.Errorf("unexpected dead bytes %d of %d", dead, idx.storedBytes())
+	}
+	if err := idx.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	idx, err = NewIndex(testDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+	for _, i := range []int{0, 250, 499} {
+		id := fmt.Sprintf("doc%d", i)
+		doc, err := idx.GetDocument(id)
+		if err != nil || doc.ID != id || doc.Metadata["n"] != i {
+			t.Errorf("get %s after reopening: got %v, %v", id, doc, err)
+		}
+	}
+	docs, err := idx.SearchQuery(Query{Range: map[string]RangeQuery{"n": {GTE: 495}}})
+	if err != nil || len(docs) != 5 {
+		t.Errorf("range: got %d documents, %v", len(docs), err)
+	}
+}
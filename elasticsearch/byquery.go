@@ -0,0 +1,357 @@
+package hamfts
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// byQueryBatchSize is the number of documents changed per write lock, so
+// searches and writes can go on while a by-query task runs
+const byQueryBatchSize = 100
+
+// Patch changes the metadata of a document. Paths are dotted; Set creates
+// missing objects along the way and Unset ignores missing fields.
+type Patch struct {
+	Set   map[string]interface{} `json:"set,omitempty"`
+	Unset []string               `json:"unset,omitempty"`
+}
+
+func (p Patch) validate() error {
+	if len(p.Set) == 0 && len(p.Unset) == 0 {
+		return queryError("patch has nothing to set or unset")
+	}
+	for path := range p.Set {
+		if !validPatchPath(path) {
+			return queryError("invalid patch path %q", path)
+		}
+	}
+	for _, path := range p.Unset {
+		if !validPatchPath(path) {
+			return queryError("invalid patch path %q", path)
+		}
+		if _, ok := p.Set[path]; ok {
+			return queryError("patch both sets and unsets %q", path)
+		}
+	}
+	return nil
+}
+
+func validPatchPath(path string) bool {
+	for _, part := range strings.Split(path, ".") {
+		if part == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// Apply returns a patched copy of meta
+func (p Patch) Apply(meta map[string]interface{}) (map[string]interface{}, error) {
+	out := copyObject(meta)
+	// Sorted so that "a" is set before "a.b"
+	paths := make([]string, 0, len(p.Set))
+	for path := range p.Set {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		parts := strings.Split(path, ".")
+		obj := out
+		for i, part := range parts[:len(parts)-1] {
+			switch child := obj[part].(type) {
+			case map[string]interface{}:
+				obj = child
+			case nil:
+				next := make(map[string]interface{})
+				obj[part] = next
+				obj = next
+			default:
+				return nil, queryError("cannot set %q: %q is not an object", path, strings.Join(parts[:i+1], "."))
+			}
+		}
+		obj[parts[len(parts)-1]] = p.Set[path]
+	}
+
+	for _, path := range p.Unset {
+		parts := strings.Split(path, ".")
+		obj := out
+		for _, part := range parts[:len(parts)-1] {
+			if obj, _ = obj[part].(map[string]interface{}); obj == nil {
+				break
+			}
+		}
+		if obj != nil {
+			delete(obj, parts[len(parts)-1])
+		}
+	}
+	return out, nil
+}
+
+// copyObject deep copies the nested objects of meta. Other values are
+// shared, as patches replace them whole.
+func copyObject(meta map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(meta))
+	for key, value := range meta {
+		if child, ok := value.(map[string]interface{}); ok {
+			value = copyObject(child)
+		}
+		out[key] = value
+	}
+	return out
+}
+
+// DeleteByQuery deletes every document matching q in a background task
+func (idx *Index) DeleteByQuery(q Query) (*Task, error) {
+	return idx.startByQuery("delete_by_query", q, func(id string, num int64, p *TaskProgress) error {
+		doc, err := idx.readDocument(num)
+		if err != nil {
+			return err
+		}
+		idx.unindexDocument(doc, num)
+		idx.markDead(num, doc)
+		delete(idx.metadata.DocumentNumbers, id)
+		idx.metadata.DocumentLocations[num] = deletedLocation
+		idx.metadata.DocumentCount--
+		p.Deleted++
+		return nil
+	})
+}
+
+// UpdateByQuery applies patch to the metadata of every document matching q
+// in a background task. The task fails on the first document whose patched
+// metadata does not fit the mapping; documents updated before it stay
+// updated.
+func (idx *Index) UpdateByQuery(q Query, patch Patch) (*Task, error) {
+	if err := patch.validate(); err != nil {
+		return nil, err
+	}
+	return idx.startByQuery("update_by_query", q, func(id string, num int64, p *TaskProgress) error {
+		doc, err := idx.readDocument(num)
+		if err != nil {
+			return err
+		}
+		meta, err := patch.Apply(doc.Metadata)
+		if err != nil {
+			return fmt.Errorf("document %q: %w", id, err)
+		}
+		if reflect.DeepEqual(meta, doc.Metadata) {
+			p.Noops++
+			return nil
+		}
+
+		mapping := idx.metadata.Mapping.clone()
+		if err := mapping.apply(meta); err != nil {
+			return fmt.Errorf("document %q: %w", id, err)
+		}
+		idx.metadata.Mapping = mapping
+
+		updated := *doc
+		updated.Metadata = meta
+		pos, err := idx.appendDocument(&updated)
+		if err != nil {
+			return err
+		}
+		idx.unindexDocument(doc, num)
+		idx.markDead(num, doc)
+		idx.metadata.DocumentLocations[num] = pos
+		idx.indexDocument(&updated, num)
+		p.Updated++
+		return nil
+	})
+}
+
+// byQueryMatched, when set, runs once the IDs are collected and before the
+// first batch. Tests use it to write during a by-query task.
+var byQueryMatched func()
+
+// matchedDoc is a document that matched a by-query task, with its doc
+// number and location at the time
+type matchedDoc struct {
+	id       string
+	num      int64
+	location int64
+}
+
+// startByQuery collects the documents matching q and hands them to apply
+// in batches from a background task. Each batch holds the write lock;
+// documents deleted since the query ran are skipped.
+func (idx *Index) startByQuery(action string, q Query, apply func(id string, num int64, p *TaskProgress) error) (*Task, error) {
+	docs, err := idx.matchingDocs(q)
+	if err != nil {
+		return nil, err
+	}
+
+	return idx.tasks.start(action, func(t *Task) error {
+		t.update(func(p *TaskProgress) { p.Total = len(docs) })
+		if byQueryMatched != nil {
+			byQueryMatched()
+		}
+		for start := 0; start < len(docs); start += byQueryBatchSize {
+			if t.ctx.Err() != nil {
+				return nil
+			}
+			batch := docs[start:min(start+byQueryBatchSize, len(docs))]
+			if err := idx.applyBatch(t, q, batch, apply); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// applyBatch applies a batch under the write lock. A document that moved
+// since it matched may have been replaced, so the query runs again on those
+// documents; one that no longer matches is left alone and counted as a
+// version conflict.
+func (idx *Index) applyBatch(t *Task, q Query, batch []matchedDoc, apply func(id string, num int64, p *TaskProgress) error) error {
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+
+	var moved []int64
+	for _, m := range batch {
+		if num, ok := idx.metadata.DocumentNumbers[m.id]; ok && (num != m.num || idx.metadata.DocumentLocations[num] != m.location) {
+			moved = append(moved, num)
+		}
+	}
+	matched, err := idx.matchAmong(q, moved)
+	if err != nil {
+		return err
+	}
+
+	var progress TaskProgress
+	var applyErr error
+	for _, m := range batch {
+		num, ok := idx.metadata.DocumentNumbers[m.id]
+		if !ok {
+			progress.Done++
+			continue
+		}
+		if num != m.num || idx.metadata.DocumentLocations[num] != m.location {
+			if _, still := matched[num]; !still {
+				progress.VersionConflicts++
+				progress.Done++
+				continue
+			}
+		}
+		if applyErr = apply(m.id, num, &progress); applyErr != nil {
+			break
+		}
+		progress.Done++
+	}
+
+	t.update(func(p *TaskProgress) {
+		p.Done += progress.Done
+		p.Deleted += progress.Deleted
+		p.Updated += progress.Updated
+		p.Noops += progress.Noops
+		p.VersionConflicts += progress.VersionConflicts
+	})
+	if err := idx.saveMetadata(); err != nil {
+		return err
+	}
+	idx.maybeCompact()
+	return applyErr
+}
+
+// matchAmong returns which of the documents nums match q. The query runs
+// against a view of just those documents, so the cost follows their number
+// rather than the size of the index; more_like_this picks its terms by the
+// statistics of the whole index, so a query using it runs on all of it.
+// The caller holds idx.mutex.
+func (idx *Index) matchAmong(q Query, nums []int64) (docSet, error) {
+	if len(nums) == 0 {
+		return docSet{}, nil
+	}
+	live := idx.live()
+	if usesMoreLikeThis(q) {
+		matched, err := live.evalQuery(q)
+		if err != nil {
+			return nil, err
+		}
+		within := make(docSet, len(nums))
+		for _, num := range nums {
+			within[num] = struct{}{}
+		}
+		return intersect(matched, within), nil
+	}
+
+	metadata := *live.metadata
+	metadata.IndexEntries = make(map[string][]int64)
+	metadata.FieldEntries = make(map[string]map[string][]int64)
+	metadata.DocumentNumbers = make(map[string]int64, len(nums))
+	for _, num := range nums {
+		doc, err := live.readDocument(num)
+		if err != nil {
+			return nil, err
+		}
+		idx.addPostings(metadata.IndexEntries, metadata.FieldEntries, live.analysis, doc, num)
+		metadata.DocumentNumbers[doc.ID] = num
+	}
+	batch := *live
+	batch.metadata = &metadata
+	return batch.evalQuery(q)
+}
+
+// usesMoreLikeThis reports whether q has a more_like_this clause
+func usesMoreLikeThis(q Query) bool {
+	if q.MoreLikeThis != nil {
+		return true
+	}
+	if q.Nested != nil {
+		return usesMoreLikeThis(q.Nested.Query)
+	}
+	if q.Bool != nil {
+		for _, subs := range [][]Query{q.Bool.Must, q.Bool.Should, q.Bool.MustNot} {
+			for _, sub := range subs {
+				if usesMoreLikeThis(sub) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// matchingIDs returns the IDs of the documents matching q in doc number
+// order
+func (idx *Index) matchingIDs(q Query) ([]string, error) {
+	docs, err := idx.matchingDocs(q)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(docs))
+	for i, doc := range docs {
+		ids[i] = doc.id
+	}
+	return ids, nil
+}
+
+// matchingDocs returns the documents matching q in doc number order
+func (idx *Index) matchingDocs(q Query) ([]matchedDoc, error) {
+	v := idx.acquire()
+	defer v.release()
+
+	matched, err := v.evalQuery(q)
+	if err != nil {
+		return nil, err
+	}
+	byNum := make(map[int64]string, len(v.metadata.DocumentNumbers))
+	for id, num := range v.metadata.DocumentNumbers {
+		byNum[num] = id
+	}
+	nums := make([]int64, 0, len(matched))
+	for num := range matched {
+		if _, ok := byNum[num]; ok {
+			nums = append(nums, num)
+		}
+	}
+	sort.Slice(nums, func(i, j int) bool { return nums[i] < nums[j] })
+	docs := make([]matchedDoc, len(nums))
+	for i, num := range nums {
+		docs[i] = matchedDoc{id: byNum[num], num: num, location: v.metadata.DocumentLocations[num]}
+	}
+	return docs, nil
+}
@@ -0,0 +1,209 @@
+package hamfts
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestPatchApply(t *testing.T) {
+	meta := map[string]interface{}{
+		"title":  "Hello",
+		"author": map[string]interface{}{"name": "Ann", "email": "ann@example.com"},
+	}
+	patch := Patch{
+		Set:   map[string]interface{}{"author.name": "Bob", "stats.views": 3},
+		Unset: []string{"author.email", "missing.field"},
+	}
+	got, err := patch.Apply(meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"title":  "Hello",
+		"author": map[string]interface{}{"name": "Bob"},
+		"stats":  map[string]interface{}{"views": 3},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if meta["author"].(map[string]interface{})["name"] != "Ann" {
+		t.Error("Apply modified its input")
+	}
+
+	if _, err := (Patch{Set: map[string]interface{}{"title.sub": 1}}).Apply(meta); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("setting below a value: got %v, want ErrInvalidQuery", err)
+	}
+	if err := (Patch{Set: map[string]interface{}{"a..b": 1}}).validate(); err == nil {
+		t.Error("expected an error for an empty path segment")
+	}
+}
+
+func TestByQuery(t *testing.T) {
+	testDir, err := os.MkdirTemp("", "hamfts_test_byquery")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+
+	idx, err := NewIndex(testDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+
+	for i := 0; i < 250; i++ {
+		doc := NewDocument(fmt.Sprintf("doc%d", i), "some text")
+		doc.Metadata["status"] = "active"
+		if i%5 == 0 {
+			doc.Metadata["status"] = "stale"
+		}
+		doc.Metadata["owner"] = map[string]interface{}{"name": "ann", "team": "a"}
+		if err := idx.AddDocument(doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stale := Query{Term: map[string]interface{}{"status.keyword": "stale"}}
+
+	task, err := idx.UpdateByQuery(stale, Patch{
+		Set:   map[string]interface{}{"owner.name": "bob", "archived": true},
+		Unset: []string{"owner.team"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := task.Wait()
+	if info.Status != TaskCompleted || info.Progress != (TaskProgress{Total: 50, Done: 50, Updated: 50}) {
+		t.Errorf("update: got %+v", info)
+	}
+	docs, err := idx.SearchQuery(Query{Term: map[string]interface{}{"owner.name.keyword": "bob"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 50 || docs[0].Metadata["archived"] != true {
+		t.Errorf("expected 50 updated documents, got %d", len(docs))
+	}
+	if _, ok := docs[0].Metadata["owner"].(map[string]interface{})["team"]; ok {
+		t.Error("owner.team should have been unset")
+	}
+
+	// Running the same patch again changes nothing
+	task, err = idx.UpdateByQuery(stale, Patch{Set: map[string]interface{}{"archived": true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info := task.Wait(); info.Progress.Noops != 50 || info.Progress.Updated != 0 {
+		t.Errorf("repeated update: got %+v", info.Progress)
+	}
+
+	// A value that does not fit the mapping fails the task
+	task, err = idx.UpdateByQuery(stale, Patch{Set: map[string]interface{}{"archived": "maybe"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info := task.Wait(); info.Status != TaskFailed || info.Error == "" {
+		t.Errorf("mapping conflict: got %+v", info)
+	}
+
+	task, err = idx.DeleteByQuery(stale)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info := task.Wait(); info.Status != TaskCompleted || info.Progress.Deleted != 50 {
+		t.Errorf("delete: got %+v", info)
+	}
+	if n := idx.DocumentCount(); n != 200 {
+		t.Errorf("expected 200 documents left, got %d", n)
+	}
+	if docs, _ := idx.SearchQuery(stale); len(docs) != 0 {
+		t.Errorf("expected no stale documents, got %d", len(docs))
+	}
+
+	// Cancel while the task waits for the write lock
+	task, err = idx.DeleteByQuery(Query{MatchAll: &MatchAllQuery{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx.mutex.Lock()
+	if err := idx.CancelTask(task.Info().ID); err != nil {
+		t.Error(err)
+	}
+	idx.mutex.Unlock()
+	info = task.Wait()
+	if info.Status != TaskCancelled || info.Progress.Done >= info.Progress.Total {
+		t.Errorf("cancel: got %+v", info)
+	}
+	if idx.DocumentCount() != 200-info.Progress.Deleted {
+		t.Errorf("document count %d does not match %d deletes", idx.DocumentCount(), info.Progress.Deleted)
+	}
+
+	if _, err := idx.Task("nope"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("unknown task: got %v, want ErrNotFound", err)
+	}
+	if _, err := idx.UpdateByQuery(stale, Patch{}); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("empty patch: got %v, want ErrInvalidQuery", err)
+	}
+}
+
+func TestByQueryVersionConflicts(t *testing.T) {
+	testDir, err := os.MkdirTemp("", "hamfts_test_byquery_conflicts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+
+	idx, err := NewIndex(testDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+
+	newDoc := func(id, status string) *Document {
+		doc := NewDocument(id, "some text")
+		doc.Metadata["status"] = status
+		return doc
+	}
+	var docs []*Document
+	for i := 0; i < 250; i++ {
+		docs = append(docs, newDoc(fmt.Sprintf("doc%d", i), "stale"))
+	}
+	if err := idx.AddDocuments(docs); err != nil {
+		t.Fatal(err)
+	}
+
+	// Between the query and the deletes, in each of the three batches a
+	// document is replaced: doc1 and doc150 by ones that no longer match,
+	// doc249 by one that still does. doc120 is deleted, and the compaction
+	// moves every document.
+	byQueryMatched = func() {
+		for _, doc := range []*Document{newDoc("doc1", "active"), newDoc("doc150", "active"), newDoc("doc249", "stale")} {
+			if err := idx.AddDocument(doc); err != nil {
+				t.Error(err)
+			}
+		}
+		if err := idx.DeleteDocument("doc120"); err != nil {
+			t.Error(err)
+		}
+		if err := idx.Compact(); err != nil {
+			t.Error(err)
+		}
+	}
+	defer func() { byQueryMatched = nil }()
+
+	task, err := idx.DeleteByQuery(Query{Term: map[string]interface{}{"status.keyword": "stale"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := task.Wait()
+	if want := (TaskProgress{Total: 250, Done: 250, Deleted: 247, VersionConflicts: 2}); info.Progress != want {
+		t.Errorf("expected %+v, got %+v", want, info.Progress)
+	}
+	ids := idx.ListDocumentIDs()
+	sort.Strings(ids)
+	if !reflect.DeepEqual(ids, []string{"doc1", "doc150"}) {
+		t.Errorf("expected doc1 and doc150 left, got %v", ids)
+	}
+}
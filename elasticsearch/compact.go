@@ -0,0 +1,264 @@
+package hamfts
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// defaultAutoCompactRatio is the share of dead bytes in docs.dat that starts
+// a compaction when IndexOptions.AutoCompactRatio is unset
+const defaultAutoCompactRatio = 0.5
+
+// autoCompactMinBytes keeps small document files from being compacted
+// automatically, whatever their share of dead bytes
+var autoCompactMinBytes int64 = 1 << 20
+
+// compactCopied, when set, runs once the snapshot has been copied and before
+// compaction catches up. Tests use it to write during a compaction.
+var compactCopied func()
+
+// Compact rewrites docs.dat without the records of deleted and replaced
+// documents. Searches and writes go on while it runs; see compact.
+func (idx *Index) Compact() error {
+	if !idx.rewriting.CompareAndSwap(false, true) {
+		return fmt.Errorf("%w: a compaction or reindex is already running", ErrConflict)
+	}
+	defer idx.rewriting.Store(false)
+	return idx.compact(context.Background(), nil)
+}
+
+// StartCompact runs Compact as a background task. Cancelling the task leaves
+// the index as it was.
+func (idx *Index) StartCompact() (*Task, error) {
+	if !idx.rewriting.CompareAndSwap(false, true) {
+		return nil, fmt.Errorf("%w: a compaction or reindex is already running", ErrConflict)
+	}
+	task, err := idx.tasks.start("compact", func(t *Task) error {
+		defer idx.rewriting.Store(false)
+		return idx.compact(t.ctx, t)
+	})
+	if err != nil {
+		idx.rewriting.Store(false)
+	}
+	return task, err
+}
+
+// compaction copies live documents from docs.dat and docs.tail into blocks
+// of a new file, remembering where each one moved
+type compaction struct {
+	src     docStore // on separate handles, so copying needs no index lock
+	dst     *os.File
+	dstPath string
+	blocks  *blockWriter
+	moved   map[int64]int64 // old location -> new location
+	task    *Task
+}
+
+// compact works in three steps. It copies the documents live at the start
+// from a snapshot of their locations without holding the index lock, then
+// copies the documents written in the meantime under the read lock, and
+// finally takes the write lock to copy the last few, update the locations
+// and swap the files. Postings hold doc numbers, so they stay as they are.
+func (idx *Index) compact(ctx context.Context, t *Task) error {
+	// A writer that packed the tail before rewriting was set may still be
+	// about to empty docs.tail; under the lock it is done with it
+	docPath := filepath.Join(idx.baseDir, "documents", "docs.dat")
+	idx.mutex.RLock()
+	format := idx.metadata.RecordFormat
+	src, err := os.Open(docPath)
+	if err != nil {
+		idx.mutex.RUnlock()
+		return err
+	}
+	defer src.Close()
+	tailSrc, err := os.Open(filepath.Join(idx.baseDir, "documents", "docs.tail"))
+	idx.mutex.RUnlock()
+	if err != nil {
+		return err
+	}
+	defer tailSrc.Close()
+
+	c := &compaction{
+		src:     docStore{docs: src, tail: tailSrc, format: format, blocks: newBlockCache()},
+		dstPath: docPath + ".tmp",
+		moved:   make(map[int64]int64),
+		task:    t,
+	}
+	c.dst, err = os.Create(c.dstPath)
+	if err != nil {
+		return err
+	}
+	c.blocks = &blockWriter{w: c.dst}
+	swapped := false
+	defer func() {
+		if !swapped {
+			c.dst.Close()
+			os.Remove(c.dstPath)
+		}
+	}()
+
+	idx.mutex.RLock()
+	snapshot := idx.pendingPositions(c.moved)
+	idx.mutex.RUnlock()
+	if err := c.copyRecords(ctx, snapshot); err != nil || ctx.Err() != nil {
+		return err
+	}
+	if compactCopied != nil {
+		compactCopied()
+	}
+
+	idx.mutex.RLock()
+	pending := idx.pendingPositions(c.moved)
+	idx.mutex.RUnlock()
+	if err := c.copyRecords(ctx, pending); err != nil || ctx.Err() != nil {
+		return err
+	}
+
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+	if err := c.copyRecords(ctx, idx.pendingPositions(c.moved)); err != nil || ctx.Err() != nil {
+		return err
+	}
+	if err := c.blocks.flush(); err != nil {
+		return err
+	}
+	if err := c.dst.Sync(); err != nil {
+		return err
+	}
+
+	locations := make([]int64, len(idx.metadata.DocumentLocations))
+	for num, pos := range idx.metadata.DocumentLocations {
+		locations[num] = deletedLocation
+		if pos != deletedLocation {
+			locations[num] = c.moved[pos]
+		}
+	}
+
+	// Swap the files; on failure the old file is reopened untouched
+	if err := c.dst.Close(); err != nil {
+		return err
+	}
+	swapped = true
+	idx.docFile.Close()
+	renameErr := os.Rename(c.dstPath, docPath)
+	if renameErr != nil {
+		os.Remove(c.dstPath)
+	}
+	idx.docFile, err = os.OpenFile(docPath, os.O_RDWR, 0644)
+	if err != nil {
+		return err
+	}
+	if renameErr != nil {
+		return renameErr
+	}
+
+	// Views published before keep reading the old file until released
+	if err := idx.renewFiles(newBlockCache()); err != nil {
+		return err
+	}
+	idx.metadata.DocumentLocations = locations
+	// Documents deleted or replaced after they were copied are already dead
+	// in the new file
+	idx.metadata.DeadBytes = c.deadBytes(locations)
+	idx.metadata.RecordFormat = currentRecordFormat
+	if err := idx.saveMetadata(); err != nil {
+		return err
+	}
+	// The documents of docs.tail are all in the new file now
+	if err := idx.rotateTail(); err != nil {
+		return err
+	}
+	idx.publish()
+	return nil
+}
+
+// pendingPositions returns the locations of live documents not copied yet,
+// in file order
+func (idx *Index) pendingPositions(moved map[int64]int64) []int64 {
+	var pending []int64
+	for _, pos := range idx.metadata.DocumentLocations {
+		if _, ok := moved[pos]; !ok && pos != deletedLocation {
+			pending = append(pending, pos)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i] < pending[j] })
+	return pending
+}
+
+func (c *compaction) copyRecords(ctx context.Context, positions []int64) error {
+	if c.task != nil {
+		c.task.update(func(p *TaskProgress) { p.Total += len(positions) })
+	}
+	for _, pos := range positions {
+		if ctx.Err() != nil {
+			return nil
+		}
+		doc, err := c.src.read(pos)
+		if err != nil {
+			return err
+		}
+		loc, err := c.blocks.add(doc)
+		if err != nil {
+			return err
+		}
+		c.moved[pos] = loc
+		if c.task != nil {
+			c.task.update(func(p *TaskProgress) { p.Done++ })
+		}
+	}
+	return nil
+}
+
+// deadBytes is the share of the new file taken by copied records that live
+// no longer points at, charged the way markDead charges a block record
+func (c *compaction) deadBytes(live []int64) int64 {
+	referenced := make(map[int64]bool, len(live))
+	for _, loc := range live {
+		referenced[loc] = true
+	}
+	counts := make(map[int64]int64) // block offset -> documents in it
+	for _, loc := range c.moved {
+		counts[blockOffset(loc)]++
+	}
+	offsets := make([]int64, 0, len(counts))
+	for off := range counts {
+		offsets = append(offsets, off)
+	}
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+	stored := make(map[int64]int64, len(offsets))
+	for i, off := range offsets {
+		end := c.blocks.end
+		if i+1 < len(offsets) {
+			end = offsets[i+1]
+		}
+		stored[off] = end - off
+	}
+
+	var dead int64
+	for _, loc := range c.moved {
+		if !referenced[loc] {
+			off := blockOffset(loc)
+			dead += stored[off] / counts[off]
+		}
+	}
+	return dead
+}
+
+// maybeCompact starts a compaction task once dead records make up more than
+// the configured share of docs.dat. It is called with the write lock held;
+// the task only takes the lock after it is released.
+func (idx *Index) maybeCompact() {
+	if idx.autoCompactRatio <= 0 || idx.rewriting.Load() {
+		return
+	}
+	size := idx.storedBytes()
+	if size == 0 || size < autoCompactMinBytes {
+		return
+	}
+	if float64(idx.metadata.DeadBytes)/float64(size) >= idx.autoCompactRatio {
+		idx.StartCompact()
+	}
+}
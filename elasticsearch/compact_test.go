@@ -0,0 +1,225 @@
+package hamfts
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// waitForTask polls a task started behind the caller's back, such as an
+// automatic compaction, until it finishes
+func waitForTask(t *testing.T, idx *Index, id string) TaskInfo {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		info, err := idx.Task(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Status != TaskRunning {
+			return info
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("task %s still running", id)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestOnlineCompact(t *testing.T) {
+	testDir, err := os.MkdirTemp("", "hamfts_test_compact")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+
+	idx, err := NewIndexWithOptions(testDir, IndexOptions{AutoCompactRatio: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+
+	for i := 0; i < 100; i++ {
+		doc := NewDocument(fmt.Sprintf("doc%d", i), fmt.Sprintf("common word%d", i))
+		doc.Metadata["n"] = i
+		if err := idx.AddDocument(doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 100; i += 2 {
+		if err := idx.DeleteDocument(fmt.Sprintf("doc%d", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	storedSize := func() int64 {
+		var size int64
+		for _, name := range []string{"docs.dat", "docs.tail"} {
+			if info, err := os.Stat(filepath.Join(testDir, "documents", name)); err == nil {
+				size += info.Size()
+			}
+		}
+		return size
+	}
+	before := storedSize()
+
+	// Writes that arrive while the snapshot is copied must not be lost
+	compactCopied = func() {
+		if err := idx.AddDocument(NewDocument("late", "common latecomer")); err != nil {
+			t.Error(err)
+		}
+		if err := idx.DeleteDocument("doc1"); err != nil {
+			t.Error(err)
+		}
+		// Searches are not blocked either
+		if docs, err := idx.Search("common", false); err != nil || len(docs) != 50 {
+			t.Errorf("search during compaction: got %d, %v", len(docs), err)
+		}
+	}
+	defer func() { compactCopied = nil }()
+
+	if err := idx.Compact(); err != nil {
+		t.Fatal(err)
+	}
+
+	if after := storedSize(); after >= before {
+		t.Errorf("stored documents did not shrink: %d -> %d bytes", before, after)
+	}
+	// doc1 was copied before it was deleted, so the new file starts with a
+	// dead record that the next compaction drops
+	if dead := idx.GetStats()["deadBytes"].(int64); dead <= 0 {
+		t.Errorf("expected the copy of doc1 to be dead, got %d dead bytes", dead)
+	}
+	compactCopied = nil
+	if err := idx.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	if dead := idx.GetStats()["deadBytes"]; dead != int64(0) {
+		t.Errorf("expected no dead bytes, got %v", dead)
+	}
+
+	docs, err := idx.Search("common", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 50 {
+		t.Errorf("expected 50 documents, got %d", len(docs))
+	}
+	for _, id := range []string{"late", "doc3", "doc99"} {
+		if doc, err := idx.GetDocument(id); err != nil || doc.ID != id {
+			t.Errorf("get %s: got %v, %v", id, doc, err)
+		}
+	}
+	if docs, _ := idx.Search("word51", false); len(docs) != 1 || docs[0].ID != "doc51" {
+		t.Errorf("postings not remapped: %v", docs)
+	}
+	docs, err = idx.SearchQuery(Query{Range: map[string]RangeQuery{"n": {GTE: 97}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 2 || docs[0].ID != "doc97" || docs[1].ID != "doc99" {
+		t.Errorf("field postings not remapped: %v", docs)
+	}
+}
+
+func TestAutoCompact(t *testing.T) {
+	testDir, err := os.MkdirTemp("", "hamfts_test_autocompact")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+
+	minBytes := autoCompactMinBytes
+	autoCompactMinBytes = 0
+	defer func() { autoCompactMinBytes = minBytes }()
+
+	idx, err := NewIndex(testDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+
+	for i := 0; i < 10; i++ {
+		if err := idx.AddDocument(NewDocument(fmt.Sprintf("doc%d", i), "text")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 4; i++ {
+		if err := idx.DeleteDocument(fmt.Sprintf("doc%d", i)); err != nil {
+			t.Fatal(err)
+		}
+		if tasks, _ := idx.Tasks(); len(tasks) != 0 {
+			t.Fatalf("compaction started after %d of 10 deletes", i+1)
+		}
+	}
+
+	// Past half of the file the next delete starts a compaction
+	if err := idx.DeleteDocument("doc4"); err != nil {
+		t.Fatal(err)
+	}
+	tasks, err := idx.Tasks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 1 || tasks[0].Action != "compact" {
+		t.Fatalf("expected a compaction task, got %+v", tasks)
+	}
+	if info := waitForTask(t, idx, tasks[0].ID); info.Status != TaskCompleted || info.Progress.Done != 5 {
+		t.Errorf("compaction: got %+v", info)
+	}
+	if idx.DocumentCount() != 5 || idx.GetStats()["deadBytes"] != int64(0) {
+		t.Errorf("unexpected stats after compaction: %v", idx.GetStats())
+	}
+}
+
+func TestAutoCompactAfterReplace(t *testing.T) {
+	testDir, err := os.MkdirTemp("", "hamfts_test_autocompact_replace")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+
+	minBytes := autoCompactMinBytes
+	autoCompactMinBytes = 0
+	defer func() { autoCompactMinBytes = minBytes }()
+
+	idx, err := NewIndex(testDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+
+	var docs []*Document
+	for i := 0; i < 10; i++ {
+		docs = append(docs, NewDocument(fmt.Sprintf("doc%d", i), "text"))
+	}
+	if err := idx.AddDocuments(docs); err != nil {
+		t.Fatal(err)
+	}
+
+	// Each overwrite appends a record and kills one, so the file is half
+	// dead once every document was replaced
+	if err := idx.AddDocuments(docs[:9]); err != nil {
+		t.Fatal(err)
+	}
+	if tasks, _ := idx.Tasks(); len(tasks) != 0 {
+		t.Fatalf("compaction started after 9 of 10 replacements")
+	}
+	if err := idx.AddDocument(docs[9]); err != nil {
+		t.Fatal(err)
+	}
+	tasks, err := idx.Tasks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 1 || tasks[0].Action != "compact" {
+		t.Fatalf("expected a compaction task, got %+v", tasks)
+	}
+	if info := waitForTask(t, idx, tasks[0].ID); info.Status != TaskCompleted || info.Progress.Done != 10 {
+		t.Errorf("compaction: got %+v", info)
+	}
+	if idx.DocumentCount() != 10 || idx.GetStats()["deadBytes"] != int64(0) {
+		t.Errorf("unexpected stats after compaction: %v", idx.GetStats())
+	}
+}
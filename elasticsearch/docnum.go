@@ -0,0 +1,137 @@
+package hamfts
+
+import (
+	"fmt"
+	"sort"
+)
+
+// deletedLocation marks the doc numbers of deleted documents in
+// IndexMetadata.DocumentLocations. Numbers are never reused, so a stale
+// posting cannot point at another document.
+const deletedLocation = -1
+
+// readDocument reads the document with the given doc number
+func (v *view) readDocument(num int64) (*Document, error) {
+	pos, ok := v.metadata.location(num)
+	if !ok {
+		return nil, fmt.Errorf("%w: doc number %d has no stored document", ErrCorrupt, num)
+	}
+	return v.store.read(pos)
+}
+
+// readDocument reads a document of the live index, for writers holding the
+// index lock
+func (idx *Index) readDocument(num int64) (*Document, error) {
+	return idx.live().readDocument(num)
+}
+
+// location returns where the document with the given doc number is stored
+func (m *IndexMetadata) location(num int64) (int64, bool) {
+	if num < 0 || num >= int64(len(m.DocumentLocations)) || m.DocumentLocations[num] == deletedLocation {
+		return 0, false
+	}
+	return m.DocumentLocations[num], true
+}
+
+// liveDocNumbers returns the doc numbers of the stored documents in
+// ascending order
+func (m *IndexMetadata) liveDocNumbers() []int64 {
+	nums := make([]int64, 0, len(m.DocumentNumbers))
+	for num, pos := range m.DocumentLocations {
+		if pos != deletedLocation {
+			nums = append(nums, int64(num))
+		}
+	}
+	return nums
+}
+
+// migrateDocumentPositions numbers the documents of an index written before
+// doc numbers in file order, and turns its postings from file positions
+// into doc numbers. Postings of positions no document is stored at are
+// dropped. DocumentCount is recounted, since old versions counted a
+// re-added ID twice.
+func (m *IndexMetadata) migrateDocumentPositions() {
+	ids := make([]string, 0, len(m.DocumentPositions))
+	for id := range m.DocumentPositions {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return m.DocumentPositions[ids[i]] < m.DocumentPositions[ids[j]] })
+
+	m.DocumentNumbers = make(map[string]int64, len(ids))
+	m.DocumentLocations = make([]int64, 0, len(ids))
+	numbers := make(map[int64]int64, len(ids)) // file position -> doc number
+	for _, id := range ids {
+		num, pos := int64(len(m.DocumentLocations)), m.DocumentPositions[id]
+		m.DocumentNumbers[id] = num
+		m.DocumentLocations = append(m.DocumentLocations, pos)
+		numbers[pos] = num
+	}
+	m.DocumentCount = len(m.DocumentNumbers)
+
+	renumber := func(entries map[string][]int64) {
+		for term, postings := range entries {
+			nums := postings[:0]
+			for _, pos := range postings {
+				if num, ok := numbers[pos]; ok {
+					nums = append(nums, num)
+				}
+			}
+			if len(nums) == 0 {
+				delete(entries, term)
+			} else {
+				entries[term] = nums
+			}
+		}
+	}
+	renumber(m.IndexEntries)
+	for field, terms := range m.FieldEntries {
+		renumber(terms)
+		if len(terms) == 0 {
+			delete(m.FieldEntries, field)
+		}
+	}
+	m.DocumentPositions = nil
+}
+
+// hitKey is where a document sorts among search results: by creation time,
+// then ID. Single and sharded indexes order their hits by it, so a page is
+// the same whichever one serves it.
+type hitKey struct {
+	created int64 // Unix nanoseconds
+	id      string
+}
+
+func (k hitKey) before(o hitKey) bool {
+	if k.created != o.created {
+		return k.created < o.created
+	}
+	return k.id < o.id
+}
+
+func documentKey(doc *Document) hitKey {
+	return hitKey{created: doc.CreatedAt.UnixNano(), id: doc.ID}
+}
+
+// hitKey returns the key of the document with the given doc number without
+// reading it
+func (m *IndexMetadata) hitKey(num int64) hitKey {
+	return hitKey{created: m.DocumentCreated[num], id: m.documentIDs[num]}
+}
+
+// fillDocumentCreated reads the creation time of every document of an index
+// written before DocumentCreated was kept
+func (idx *Index) fillDocumentCreated() error {
+	if len(idx.metadata.DocumentCreated) == len(idx.metadata.DocumentLocations) {
+		return nil
+	}
+	created := make([]int64, len(idx.metadata.DocumentLocations))
+	for _, num := range idx.metadata.liveDocNumbers() {
+		doc, err := idx.readDocument(num)
+		if err != nil {
+			return err
+		}
+		created[num] = doc.CreatedAt.UnixNano()
+	}
+	idx.metadata.DocumentCreated = created
+	return idx.saveMetadata()
+}
@@ -0,0 +1,160 @@
+package hamfts
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDocNumbers(t *testing.T) {
+	testDir, err := os.MkdirTemp("", "hamfts_test_docnum")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+
+	idx, err := NewIndexWithOptions(testDir, IndexOptions{AutoCompactRatio: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+
+	for i := 0; i < 4; i++ {
+		if err := idx.AddDocument(NewDocument(fmt.Sprintf("doc%d", i), "first version")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := idx.AddDocument(NewDocument("doc1", "second version")); err != nil {
+		t.Fatal(err)
+	}
+	if err := idx.DeleteDocument("doc2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := idx.AddDocument(NewDocument("doc2", "back again")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Replacing keeps the number, deleting frees it for good
+	want := map[string]int64{"doc0": 0, "doc1": 1, "doc2": 4, "doc3": 3}
+	if !reflect.DeepEqual(idx.metadata.DocumentNumbers, want) {
+		t.Errorf("doc numbers: got %v, want %v", idx.metadata.DocumentNumbers, want)
+	}
+	if pos := idx.metadata.DocumentLocations[2]; pos != deletedLocation {
+		t.Errorf("expected doc number 2 to be deleted, found at %d", pos)
+	}
+	if got := idx.metadata.IndexEntries["version"]; !reflect.DeepEqual(got, []int64{0, 3, 1}) {
+		t.Errorf("postings of \"version\": got %v", got)
+	}
+
+	// Compaction moves records without touching the postings
+	entries, _ := json.Marshal(idx.metadata.IndexEntries)
+	fieldEntries, _ := json.Marshal(idx.metadata.FieldEntries)
+	if err := idx.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	after, _ := json.Marshal(idx.metadata.IndexEntries)
+	afterFields, _ := json.Marshal(idx.metadata.FieldEntries)
+	if string(after) != string(entries) || string(afterFields) != string(fieldEntries) {
+		t.Error("compaction changed the postings")
+	}
+	if doc, err := idx.GetDocument("doc1"); err != nil || doc.Content != "second version" {
+		t.Errorf("get after compaction: got %v, %v", doc, err)
+	}
+	if report, err := idx.Verify(); err != nil || !report.OK() {
+		t.Errorf("after compaction: got %+v, %v", report, err)
+	}
+}
+
+func TestMigrateDocumentPositions(t *testing.T) {
+	testDir, err := os.MkdirTemp("", "hamfts_test_migrate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+
+	idx, err := NewIndex(testDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		doc := NewDocument(fmt.Sprintf("doc%d", i), fmt.Sprintf("legacy word%d", i))
+		doc.Metadata["n"] = i
+		if err := idx.AddDocument(doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := idx.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Rewrite the metadata the way it was stored before doc numbers, with
+	// doc1 re-added: its old version left a stale posting behind and was
+	// counted twice
+	data, err := os.ReadFile(filepath.Join(testDir, "metadata.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var meta IndexMetadata
+	if err := json.Unmarshal(data, &meta); err != nil {
+		t.Fatal(err)
+	}
+	toPositions := func(nums []int64) []int64 {
+		positions := make([]int64, len(nums))
+		for i, num := range nums {
+			positions[i] = meta.DocumentLocations[num]
+		}
+		return positions
+	}
+	meta.DocumentPositions = make(map[string]int64)
+	for id, num := range meta.DocumentNumbers {
+		meta.DocumentPositions[id] = meta.DocumentLocations[num]
+	}
+	for word, nums := range meta.IndexEntries {
+		meta.IndexEntries[word] = toPositions(nums)
+	}
+	meta.IndexEntries["legacy"] = append(meta.IndexEntries["legacy"], 99999)
+	meta.IndexEntries["word1"] = append(meta.IndexEntries["word1"], 99999)
+	meta.DocumentCount++
+	for _, terms := range meta.FieldEntries {
+		for term, nums := range terms {
+			terms[term] = toPositions(nums)
+		}
+	}
+	meta.DocumentNumbers, meta.DocumentLocations, meta.DocumentCreated = nil, nil, nil
+	if data, err = json.Marshal(meta); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(testDir, "metadata.json"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	idx, err = NewIndex(testDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+
+	if report, err := idx.Verify(); err != nil || !report.OK() {
+		t.Errorf("migrated index: got %+v, %v", report, err)
+	}
+	if n := idx.DocumentCount(); n != 3 {
+		t.Errorf("expected 3 documents after migration, got %d", n)
+	}
+	if idx.metadata.DocumentPositions != nil {
+		t.Error("expected the legacy positions to be dropped")
+	}
+	// Creation times are read back from the documents, which orders hits
+	if docs, err := idx.SearchQuery(Query{Match: map[string]string{"content": "legacy"}}); err != nil || len(docs) != 3 || docs[0].ID != "doc0" || docs[2].ID != "doc2" {
+		t.Errorf("order after migration: got %v, %v", docs, err)
+	}
+	if docs, _ := idx.Search("word1", false); len(docs) != 1 || docs[0].ID != "doc1" {
+		t.Errorf("search after migration: got %v", docs)
+	}
+	docs, err := idx.SearchQuery(Query{Range: map[string]RangeQuery{"n": {GTE: 2}}})
+	if err != nil || len(docs) != 1 || docs[0].ID != "doc2" {
+		t.Errorf("range after migration: got %v, %v", docs, err)
+	}
+}
@@ -2,12 +2,15 @@ package hamfts
 
 import (
 	"encoding/gob"
+	"path"
 	"time"
 )
 
 func init() {
 	// Register types for gob encoding
 	gob.Register(map[string]interface{}{})
+	gob.Register([]interface{}{})
+	gob.Register([]string{})
 	gob.Register(time.Time{})
 }
 
@@ -26,3 +29,63 @@ func NewDocument(id string, content string) *Document {
 		Metadata:  make(map[string]interface{}),
 	}
 }
+
+// SourceFilter selects the parts of a document returned to a caller. Patterns
+// are dotted metadata paths, or "content" for the document body, and may use
+// * wildcards. Including an object includes everything below it.
+type SourceFilter struct {
+	Includes []string `json:"includes,omitempty"`
+	Excludes []string `json:"excludes,omitempty"`
+	// Disabled drops the content and metadata altogether
+	Disabled bool `json:"-"`
+}
+
+// Apply returns a filtered copy of doc
+func (f SourceFilter) Apply(doc *Document) *Document {
+	filtered := &Document{ID: doc.ID, CreatedAt: doc.CreatedAt}
+	if f.Disabled {
+		return filtered
+	}
+
+	all := len(f.Includes) == 0
+	if (all || matchesAny(f.Includes, contentField)) && !matchesAny(f.Excludes, contentField) {
+		filtered.Content = doc.Content
+	}
+	filtered.Metadata = f.filterObject("", doc.Metadata, all)
+	return filtered
+}
+
+func (f SourceFilter) filterObject(prefix string, obj map[string]interface{}, included bool) map[string]interface{} {
+	out := make(map[string]interface{}, len(obj))
+	for key, value := range obj {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		if matchesAny(f.Excludes, path) {
+			continue
+		}
+
+		inc := included || matchesAny(f.Includes, path)
+		if child, ok := value.(map[string]interface{}); ok {
+			sub := f.filterObject(path, child, inc)
+			if len(sub) > 0 || (inc && len(child) == 0) {
+				out[key] = sub
+			}
+			continue
+		}
+		if inc {
+			out[key] = value
+		}
+	}
+	return out
+}
+
+func matchesAny(patterns []string, p string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, p); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package hamfts
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestSourceFilter(t *testing.T) {
+	doc := NewDocument("1", "body")
+	doc.Metadata = map[string]interface{}{
+		"title": "Hello",
+		"author": map[string]interface{}{
+			"name":  "Ann",
+			"email": "ann@example.com",
+		},
+		"tags": []interface{}{"a", "b"},
+	}
+
+	tests := []struct {
+		name        string
+		filter      SourceFilter
+		wantContent string
+		wantMeta    map[string]interface{}
+	}{
+		{"all", SourceFilter{}, "body", doc.Metadata},
+		{"disabled", SourceFilter{Disabled: true}, "", nil},
+		{"include leaf", SourceFilter{Includes: []string{"author.name"}}, "", map[string]interface{}{
+			"author": map[string]interface{}{"name": "Ann"},
+		}},
+		{"include object", SourceFilter{Includes: []string{"author", "content"}}, "body", map[string]interface{}{
+			"author": map[string]interface{}{"name": "Ann", "email": "ann@example.com"},
+		}},
+		{"wildcard exclude", SourceFilter{Excludes: []string{"author.e*", "tags"}}, "body", map[string]interface{}{
+			"title":  "Hello",
+			"author": map[string]interface{}{"name": "Ann"},
+		}},
+	}
+	for _, tt := range tests {
+		got := tt.filter.Apply(doc)
+		if got.ID != "1" || got.Content != tt.wantContent {
+			t.Errorf("%s: got id %q content %q", tt.name, got.ID, got.Content)
+		}
+		if !reflect.DeepEqual(got.Metadata, tt.wantMeta) {
+			t.Errorf("%s: got metadata %v, want %v", tt.name, got.Metadata, tt.wantMeta)
+		}
+	}
+}
+
+func TestMultiGet(t *testing.T) {
+	testDir, err := os.MkdirTemp("", "hamfts_test_mget")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+
+	idx, err := NewIndex(testDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+
+	for _, id := range []string{"1", "2"} {
+		if err := idx.AddDocument(NewDocument(id, "doc "+id)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	docs, err := idx.MultiGet([]string{"2", "missing", "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 3 || docs[0].ID != "2" || docs[1] != nil || docs[2].Content != "doc 1" {
+		t.Errorf("unexpected multi get result %v", docs)
+	}
+}
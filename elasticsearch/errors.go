@@ -0,0 +1,27 @@
+package hamfts
+
+import "errors"
+
+// Sentinel errors returned by the index. Check them with errors.Is; the
+// returned errors usually wrap them with details such as the document ID.
+var (
+	// ErrNotFound is returned for a document or field that does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrConflict is returned when a change clashes with the current state,
+	// e.g. a value that does not fit the mapping.
+	ErrConflict = errors.New("conflict")
+	// ErrInvalidQuery is returned for malformed queries and requests.
+	ErrInvalidQuery = errors.New("invalid query")
+	// ErrCorrupt is returned when stored data cannot be decoded.
+	ErrCorrupt = errors.New("corrupt data")
+)
+
+// Is makes mapping errors match ErrConflict
+func (e *MappingError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+// Is makes query errors match ErrInvalidQuery
+func (e *QueryError) Is(target error) bool {
+	return target == ErrInvalidQuery
+}
@@ -0,0 +1,57 @@
+package hamfts
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTypedErrors(t *testing.T) {
+	testDir, err := os.MkdirTemp("", "hamfts_test_errors")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+
+	idx, err := NewIndex(testDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doc := NewDocument("1", "hello")
+	doc.Metadata["count"] = 1
+	if err := idx.AddDocument(doc); err != nil {
+		t.Fatal(err)
+	}
+
+	doc = NewDocument("2", "hello")
+	doc.Metadata["count"] = "many"
+	if err := idx.AddDocument(doc); !errors.Is(err, ErrConflict) {
+		t.Errorf("mapping conflict: got %v, want ErrConflict", err)
+	}
+
+	_, err = idx.SearchQuery(Query{})
+	if !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("empty query: got %v, want ErrInvalidQuery", err)
+	}
+
+	if _, err := idx.MoreLikeThis(MoreLikeThisQuery{ID: "missing"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("more_like_this: got %v, want ErrNotFound", err)
+	}
+	if _, err := idx.Analyze("missing", "text"); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("unknown analyzer: got %v, want ErrInvalidQuery", err)
+	}
+	settings := AnalysisSettings{SearchAnalyzer: "missing"}
+	if err := idx.UpdateAnalysis(settings); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("invalid settings: got %v, want ErrInvalidQuery", err)
+	}
+	idx.Close()
+
+	if err := os.WriteFile(filepath.Join(testDir, "metadata.json"), []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewIndex(testDir); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("corrupt metadata: got %v, want ErrCorrupt", err)
+	}
+}
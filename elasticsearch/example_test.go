@@ -1,6 +1,7 @@
 package hamfts
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"testing"
@@ -140,12 +141,16 @@ func TestDocumentManagement(t *testing.T) {
 	}
 
 	doc, err = idx.GetDocument("2")
-	if err != nil {
-		t.Fatal(err)
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for deleted document, got %v", err)
 	}
 	if doc != nil {
 		t.Error("Document should have been deleted")
 	}
+
+	if err := idx.DeleteDocument("2"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound deleting a missing document, got %v", err)
+	}
 }
 
 func TestBulkOperations(t *testing.T) {
@@ -0,0 +1,221 @@
+package hamfts
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// flattenMetadata collects the leaf values of meta under their dotted paths.
+// Array elements are appended to the same path, making it multi-valued.
+func flattenMetadata(prefix string, meta map[string]interface{}, out map[string][]interface{}) {
+	for key, value := range meta {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		flattenValue(path, value, out)
+	}
+}
+
+func flattenValue(path string, value interface{}, out map[string][]interface{}) {
+	switch v := value.(type) {
+	case nil:
+	case map[string]interface{}:
+		flattenMetadata(path, v, out)
+	case []interface{}:
+		for _, elem := range v {
+			flattenValue(path, elem, out)
+		}
+	case []string:
+		for _, elem := range v {
+			out[path] = append(out[path], elem)
+		}
+	default:
+		out[path] = append(out[path], v)
+	}
+}
+
+// fieldTerms returns the index terms for every mapped field of meta, keyed by
+// field path. Text fields also fill their sub-fields, e.g. "title.keyword",
+// and search_as_you_type fields their prefix sub-field.
+func (m Mapping) fieldTerms(meta map[string]interface{}, a *analysis) map[string][]string {
+	values := make(map[string][]interface{})
+	flattenMetadata("", meta, values)
+
+	terms := make(map[string][]string)
+	for path, vals := range values {
+		fm, ok := m.Fields[path]
+		if !ok {
+			continue
+		}
+		for _, value := range vals {
+			valTerms := valueTerms(fm, value, a)
+			terms[path] = append(terms[path], valTerms...)
+			if fm.Type == FieldSearchAsYouType {
+				terms[path+indexPrefixSuffix] = append(terms[path+indexPrefixSuffix], prefixTerms(valTerms)...)
+			}
+			for name, sub := range fm.Fields {
+				for _, term := range valueTerms(sub, value, a) {
+					terms[path+"."+name] = append(terms[path+"."+name], term)
+				}
+			}
+		}
+	}
+
+	for path, list := range terms {
+		terms[path] = uniqueTerms(list)
+	}
+	return terms
+}
+
+// valueTerms converts a single metadata value into index terms for a field
+func valueTerms(fm *FieldMapping, value interface{}, a *analysis) []string {
+	if fm.Type.analyzed() {
+		s, ok := value.(string)
+		if !ok {
+			return nil
+		}
+		return a.forField(fm).Analyze(s)
+	}
+
+	term, err := normalizeTerm(fm.Type, value)
+	if err != nil {
+		return nil
+	}
+	return []string{term}
+}
+
+// normalizeTerm converts a value into the canonical term stored for a field
+// type, so that query values and document values compare equal.
+func normalizeTerm(t FieldType, value interface{}) (string, error) {
+	switch t {
+	case FieldText, FieldSearchAsYouType:
+		s, ok := value.(string)
+		if !ok {
+			return "", fmt.Errorf("expected string, got %T", value)
+		}
+		return strings.ToLower(s), nil
+	case FieldKeyword:
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+		return fmt.Sprint(value), nil
+	case FieldLong, FieldDouble:
+		f, err := toFloat(value)
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatFloat(f, 'g', -1, 64), nil
+	case FieldBoolean:
+		switch v := value.(type) {
+		case bool:
+			return strconv.FormatBool(v), nil
+		case string:
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return "", err
+			}
+			return strconv.FormatBool(b), nil
+		}
+		return "", fmt.Errorf("expected boolean, got %T", value)
+	case FieldDate:
+		tm, err := toTime(value)
+		if err != nil {
+			return "", err
+		}
+		return tm.UTC().Format(time.RFC3339Nano), nil
+	}
+	return "", fmt.Errorf("field type %s cannot be queried by value", t)
+}
+
+func toFloat(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case int:
+		return float64(v), nil
+	case int8:
+		return float64(v), nil
+	case int16:
+		return float64(v), nil
+	case int32:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case uint:
+		return float64(v), nil
+	case uint8:
+		return float64(v), nil
+	case uint16:
+		return float64(v), nil
+	case uint32:
+		return float64(v), nil
+	case uint64:
+		return float64(v), nil
+	case string:
+		return strconv.ParseFloat(v, 64)
+	}
+	return 0, fmt.Errorf("expected number, got %T", value)
+}
+
+func toTime(value interface{}) (time.Time, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, nil
+	case string:
+		return time.Parse(time.RFC3339, v)
+	}
+	return time.Time{}, fmt.Errorf("expected RFC3339 date, got %T", value)
+}
+
+// compareTerms orders two normalized terms of the given field type
+func compareTerms(t FieldType, a, b string) (int, error) {
+	switch t {
+	case FieldLong, FieldDouble:
+		x, err := strconv.ParseFloat(a, 64)
+		if err != nil {
+			return 0, err
+		}
+		y, err := strconv.ParseFloat(b, 64)
+		if err != nil {
+			return 0, err
+		}
+		switch {
+		case x < y:
+			return -1, nil
+		case x > y:
+			return 1, nil
+		}
+		return 0, nil
+	case FieldDate:
+		x, err := time.Parse(time.RFC3339Nano, a)
+		if err != nil {
+			return 0, err
+		}
+		y, err := time.Parse(time.RFC3339Nano, b)
+		if err != nil {
+			return 0, err
+		}
+		return x.Compare(y), nil
+	}
+	return strings.Compare(a, b), nil
+}
+
+func uniqueTerms(terms []string) []string {
+	seen := make(map[string]struct{}, len(terms))
+	unique := terms[:0]
+	for _, term := range terms {
+		if _, ok := seen[term]; ok {
+			continue
+		}
+		seen[term] = struct{}{}
+		unique = append(unique, term)
+	}
+	sort.Strings(unique)
+	return unique
+}
@@ -1,33 +1,86 @@
 package hamfts
 
 import (
-	"encoding/gob"
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"sync"
+	"sync/atomic"
 )
 
+// IndexMetadata is persisted as metadata.json. Postings hold doc numbers,
+// which a document keeps when it is replaced; only DocumentLocations knows
+// where documents are stored in docs.dat.
 type IndexMetadata struct {
 	DocumentCount     int
-	IndexEntries      map[string][]int64 // word -> file positions
-	DocumentPositions map[string]int64   // docID -> file position
+	IndexEntries      map[string][]int64            // word -> doc numbers
+	DocumentNumbers   map[string]int64              // docID -> doc number
+	DocumentLocations []int64                       // doc number -> file position, or deletedLocation
+	DocumentCreated   []int64                       // doc number -> CreatedAt in Unix nanoseconds; see hitKey
+	FieldEntries      map[string]map[string][]int64 // field -> term -> doc numbers
+	Mapping           Mapping
+	Analysis          AnalysisSettings
+	DeadBytes         int64 // size of deleted and replaced records in docs.dat
+	RecordFormat      int   // how records in docs.dat are encoded
+	// DocumentPositions maps IDs to file positions in indexes written
+	// before doc numbers, whose postings held positions; see
+	// migrateDocumentPositions
+	DocumentPositions map[string]int64 `json:",omitempty"`
+
+	// documentIDs maps doc numbers back to IDs. It is rebuilt on load and
+	// only appended to, like a posting list.
+	documentIDs []string
+}
+
+type IndexOptions struct {
+	// Dynamic overrides the persisted dynamic mapping mode when set.
+	Dynamic DynamicMode
+	// Analysis replaces the persisted analysis settings when set. It can
+	// only change while the index is empty.
+	Analysis *AnalysisSettings
+	// AutoCompactRatio is the share of dead bytes in docs.dat that starts a
+	// compaction in the background. Zero means 0.5, negative disables it.
+	AutoCompactRatio float64
+	// DisableMmap reads docs.dat with ReadAt instead of memory mapping it
+	DisableMmap bool
 }
 
 type Index struct {
-	mutex     sync.RWMutex
-	baseDir   string
-	metadata  IndexMetadata
-	docFile   *os.File
-	indexFile *os.File
+	// mutex serializes writers, and keeps them out while the files must
+	// match the metadata. Searches do not take it; see view.
+	mutex            sync.RWMutex
+	rewriting        atomic.Bool // set while Compact or Reindex runs
+	autoCompactRatio float64
+	baseDir          string
+	metadata         IndexMetadata
+	analysis         *analysis
+	suggest          *completionIndex
+	tasks            *taskManager
+	pits             *pitManager
+	docFile          *os.File
+	tailFile         *os.File // new documents in the block format
+	indexFile        *os.File
+	mmap             bool
+	files            *viewFiles   // of the current view
+	retired          []*viewFiles // replaced since the last view was published
+	current          atomic.Pointer[view]
 }
 
 func NewIndex(baseDir string) (*Index, error) {
+	return NewIndexWithOptions(baseDir, IndexOptions{})
+}
+
+func NewIndexWithOptions(baseDir string, opts IndexOptions) (*Index, error) {
 	// Create directory structure
 	dirs := []string{
 		filepath.Join(baseDir, "documents"),
 		filepath.Join(baseDir, "indexes"),
+		filepath.Join(baseDir, "analysis"),
+		filepath.Join(baseDir, "suggest"),
+		filepath.Join(baseDir, "tasks"),
 	}
 
 	for _, dir := range dirs {
@@ -46,6 +99,15 @@ func NewIndex(baseDir string) (*Index, error) {
 		return nil, err
 	}
 
+	tailFile, err := os.OpenFile(
+		filepath.Join(baseDir, "documents", "docs.tail"),
+		os.O_RDWR|os.O_CREATE,
+		0644,
+	)
+	if err != nil {
+		return nil, err
+	}
+
 	indexFile, err := os.OpenFile(
 		filepath.Join(baseDir, "indexes", "inverted.idx"),
 		os.O_RDWR|os.O_CREATE,
@@ -56,17 +118,74 @@ func NewIndex(baseDir string) (*Index, error) {
 	}
 
 	idx := &Index{
-		baseDir:   baseDir,
-		docFile:   docFile,
-		indexFile: indexFile,
+		baseDir:          baseDir,
+		autoCompactRatio: opts.AutoCompactRatio,
+		docFile:          docFile,
+		tailFile:         tailFile,
+		indexFile:        indexFile,
+		mmap:             !opts.DisableMmap,
+		pits:             newPitManager(),
 		metadata: IndexMetadata{
-			IndexEntries:      make(map[string][]int64),
-			DocumentPositions: make(map[string]int64),
+			IndexEntries:    make(map[string][]int64),
+			DocumentNumbers: make(map[string]int64),
+			FieldEntries:    make(map[string]map[string][]int64),
+			Mapping:         newMapping(),
 		},
 	}
 
 	// Load metadata if exists
-	idx.loadMetadata()
+	if err := idx.loadMetadata(); err != nil {
+		return nil, err
+	}
+	if idx.storedBytes() == 0 {
+		// Nothing written yet, so records can use the current format
+		idx.metadata.RecordFormat = currentRecordFormat
+	}
+	if idx.metadata.FieldEntries == nil {
+		idx.metadata.FieldEntries = make(map[string]map[string][]int64)
+	}
+	if idx.metadata.Mapping.Fields == nil {
+		idx.metadata.Mapping.Fields = make(map[string]*FieldMapping)
+	}
+	if idx.metadata.Mapping.Dynamic == "" {
+		idx.metadata.Mapping.Dynamic = DynamicTrue
+	}
+	if opts.Dynamic != "" {
+		mode, err := ParseDynamicMode(string(opts.Dynamic))
+		if err != nil {
+			return nil, err
+		}
+		idx.metadata.Mapping.Dynamic = mode
+	}
+	if opts.Analysis != nil && !reflect.DeepEqual(*opts.Analysis, idx.metadata.Analysis) {
+		if idx.metadata.DocumentCount > 0 {
+			return nil, fmt.Errorf("%w: analysis settings cannot change on an index with %d documents", ErrConflict, idx.metadata.DocumentCount)
+		}
+		idx.metadata.Analysis = *opts.Analysis
+	}
+
+	idx.analysis, err = newAnalysis(idx.metadata.Analysis, idx.analysisDir(), nil)
+	if err != nil {
+		return nil, err
+	}
+	idx.suggest, err = loadCompletionIndex(filepath.Join(baseDir, "suggest", "completion.json"))
+	if err != nil {
+		return nil, err
+	}
+	if idx.autoCompactRatio == 0 {
+		idx.autoCompactRatio = defaultAutoCompactRatio
+	}
+	idx.tasks, err = newTaskManager(filepath.Join(baseDir, "tasks"))
+	if err != nil {
+		return nil, err
+	}
+	if err := idx.renewFiles(newBlockCache()); err != nil {
+		return nil, err
+	}
+	if err := idx.fillDocumentCreated(); err != nil {
+		return nil, err
+	}
+	idx.publish()
 	return idx, nil
 }
 
@@ -79,153 +198,301 @@ func (idx *Index) loadMetadata() error {
 		}
 		return err
 	}
-	return json.Unmarshal(data, &idx.metadata)
+	if err := json.Unmarshal(data, &idx.metadata); err != nil {
+		return fmt.Errorf("%w: metadata.json: %v", ErrCorrupt, err)
+	}
+	if idx.metadata.DocumentNumbers == nil {
+		idx.metadata.migrateDocumentPositions()
+	}
+	idx.metadata.documentIDs = make([]string, len(idx.metadata.DocumentLocations))
+	for id, num := range idx.metadata.DocumentNumbers {
+		idx.metadata.documentIDs[num] = id
+	}
+	return nil
+}
+
+// analysisDir is where synonym files referenced by the settings live
+func (idx *Index) analysisDir() string {
+	return filepath.Join(idx.baseDir, "analysis")
 }
 
+// saveMetadata writes the metadata and publishes it to searches
 func (idx *Index) saveMetadata() error {
+	defer idx.publish()
+	packed, err := idx.packTail()
+	if err != nil {
+		return err
+	}
 	metaPath := filepath.Join(idx.baseDir, "metadata.json")
 	data, err := json.Marshal(idx.metadata)
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(metaPath, data, 0644)
+	if err := os.WriteFile(metaPath, data, 0644); err != nil {
+		return err
+	}
+	if packed {
+		// Only now does nothing point into docs.tail any more
+		if err := idx.rotateTail(); err != nil {
+			return err
+		}
+	}
+	return idx.suggest.save()
 }
 
 func (idx *Index) AddDocument(doc *Document) error {
 	idx.mutex.Lock()
 	defer idx.mutex.Unlock()
 
+	// Check metadata against the mapping before anything is written
+	mapping := idx.metadata.Mapping.clone()
+	if err := mapping.apply(doc.Metadata); err != nil {
+		return err
+	}
+	idx.metadata.Mapping = mapping
+
 	// Serialize and write document
-	pos, err := idx.docFile.Seek(0, 2) // Seek to end
+	pos, err := idx.appendDocument(doc)
 	if err != nil {
 		return err
 	}
-
-	// Store document position
-	idx.metadata.DocumentPositions[doc.ID] = pos
-
-	encoder := gob.NewEncoder(idx.docFile)
-	if err := encoder.Encode(doc); err != nil {
+	num, err := idx.replaceDocument(doc)
+	if err != nil {
 		return err
 	}
 
-	// Update inverted index
-	words := strings.Fields(strings.ToLower(doc.Content))
-	for _, word := range words {
-		word = strings.Trim(word, ",.!? \t\n\r")
-		idx.metadata.IndexEntries[word] = append(idx.metadata.IndexEntries[word], pos)
-	}
+	// Store document location and update inverted index
+	idx.metadata.DocumentLocations[num] = pos
+	idx.indexDocument(doc, num)
 
 	idx.metadata.DocumentCount++
-	return idx.saveMetadata()
+	if err := idx.saveMetadata(); err != nil {
+		return err
+	}
+	// The replaced version, if any, is dead now
+	idx.maybeCompact()
+	return nil
 }
 
 func (idx *Index) AddDocuments(docs []*Document) error {
 	idx.mutex.Lock()
 	defer idx.mutex.Unlock()
 
+	// Reject the whole batch if any document conflicts with the mapping
+	mapping := idx.metadata.Mapping.clone()
 	for _, doc := range docs {
-		pos, err := idx.docFile.Seek(0, 2)
+		if err := mapping.apply(doc.Metadata); err != nil {
+			return fmt.Errorf("document %q: %w", doc.ID, err)
+		}
+	}
+	idx.metadata.Mapping = mapping
+
+	for _, doc := range docs {
+		pos, err := idx.appendDocument(doc)
 		if err != nil {
 			return err
 		}
-
-		encoder := gob.NewEncoder(idx.docFile)
-		if err := encoder.Encode(doc); err != nil {
+		num, err := idx.replaceDocument(doc)
+		if err != nil {
 			return err
 		}
 
-		idx.metadata.DocumentPositions[doc.ID] = pos
-		words := strings.Fields(strings.ToLower(doc.Content))
-		for _, word := range words {
-			word = strings.Trim(word, ",.!? \t\n\r")
-			idx.metadata.IndexEntries[word] = append(idx.metadata.IndexEntries[word], pos)
-		}
+		idx.metadata.DocumentLocations[num] = pos
+		idx.indexDocument(doc, num)
 		idx.metadata.DocumentCount++
 	}
 
-	return idx.saveMetadata()
+	if err := idx.saveMetadata(); err != nil {
+		return err
+	}
+	idx.maybeCompact()
+	return nil
 }
 
 func (idx *Index) GetDocument(id string) (*Document, error) {
-	idx.mutex.RLock()
-	defer idx.mutex.RUnlock()
+	v := idx.acquire()
+	defer v.release()
 
-	pos, exists := idx.metadata.DocumentPositions[id]
+	num, exists := v.metadata.DocumentNumbers[id]
 	if !exists {
-		return nil, nil
+		return nil, fmt.Errorf("document %q: %w", id, ErrNotFound)
 	}
 
-	return idx.readDocumentAt(pos)
+	return v.readDocument(num)
+}
+
+// MultiGet fetches several documents at once. The result is aligned with
+// ids and holds nil for documents that do not exist.
+func (idx *Index) MultiGet(ids []string) ([]*Document, error) {
+	v := idx.acquire()
+	defer v.release()
+
+	docs := make([]*Document, len(ids))
+	for i, id := range ids {
+		num, exists := v.metadata.DocumentNumbers[id]
+		if !exists {
+			continue
+		}
+		doc, err := v.readDocument(num)
+		if err != nil {
+			return nil, err
+		}
+		docs[i] = doc
+	}
+	return docs, nil
 }
 
 func (idx *Index) DeleteDocument(id string) error {
 	idx.mutex.Lock()
 	defer idx.mutex.Unlock()
 
-	pos, exists := idx.metadata.DocumentPositions[id]
+	num, exists := idx.metadata.DocumentNumbers[id]
 	if !exists {
-		return nil
+		return fmt.Errorf("document %q: %w", id, ErrNotFound)
 	}
 
 	// Read document to get its words for index cleanup
-	doc, err := idx.readDocumentAt(pos)
+	doc, err := idx.readDocument(num)
 	if err != nil {
 		return err
 	}
 
 	// Remove from inverted index
-	words := strings.Fields(strings.ToLower(doc.Content))
-	for _, word := range words {
-		word = strings.Trim(word, ",.!? \t\n\r")
-		positions := idx.metadata.IndexEntries[word]
-
-		// Remove this document's position from the word's position list
-		newPositions := make([]int64, 0, len(positions)-1)
-		for _, p := range positions {
-			if p != pos {
-				newPositions = append(newPositions, p)
-			}
+	idx.unindexDocument(doc, num)
+	idx.markDead(num, doc)
+
+	// Remove document number and location
+	delete(idx.metadata.DocumentNumbers, id)
+	idx.metadata.DocumentLocations[num] = deletedLocation
+	idx.metadata.DocumentCount--
+
+	if err := idx.saveMetadata(); err != nil {
+		return err
+	}
+	idx.maybeCompact()
+	return nil
+}
+
+// replaceDocument returns the doc number of a document about to be added
+// and records when it was created. A document replacing another keeps its
+// number once the postings of the old version are dropped; a new one gets
+// the next number.
+func (idx *Index) replaceDocument(doc *Document) (int64, error) {
+	num, exists := idx.metadata.DocumentNumbers[doc.ID]
+	if !exists {
+		num = int64(len(idx.metadata.DocumentLocations))
+		idx.metadata.DocumentNumbers[doc.ID] = num
+		idx.metadata.DocumentLocations = append(idx.metadata.DocumentLocations, deletedLocation)
+		idx.metadata.DocumentCreated = append(idx.metadata.DocumentCreated, doc.CreatedAt.UnixNano())
+		idx.metadata.documentIDs = append(idx.metadata.documentIDs, doc.ID)
+		return num, nil
+	}
+	old, err := idx.readDocument(num)
+	if err != nil {
+		return 0, err
+	}
+	idx.unindexDocument(old, num)
+	idx.markDead(num, old)
+	idx.metadata.DocumentCreated[num] = doc.CreatedAt.UnixNano()
+	idx.metadata.DocumentCount--
+	return num, nil
+}
+
+// indexDocument adds the postings of a document
+func (idx *Index) indexDocument(doc *Document, num int64) {
+	idx.addPostings(idx.metadata.IndexEntries, idx.metadata.FieldEntries, idx.analysis, doc, num)
+	idx.suggest.addDocument(doc, idx.metadata.Mapping)
+}
+
+// addPostings adds the content and metadata postings of a document, as
+// analyzed by a, to entries and fieldEntries
+func (idx *Index) addPostings(entries map[string][]int64, fieldEntries map[string]map[string][]int64, a *analysis, doc *Document, num int64) {
+	for _, word := range a.defaultAnalyzer.Analyze(doc.Content) {
+		entries[word] = append(entries[word], num)
+	}
+
+	for field, terms := range idx.metadata.Mapping.fieldTerms(doc.Metadata, a) {
+		termEntries := fieldEntries[field]
+		if termEntries == nil {
+			termEntries = make(map[string][]int64)
+			fieldEntries[field] = termEntries
+		}
+		for _, term := range terms {
+			termEntries[term] = append(termEntries[term], num)
 		}
+	}
+}
+
+// unindexDocument removes the postings of a document
+func (idx *Index) unindexDocument(doc *Document, num int64) {
+	idx.removePostings(idx.metadata.IndexEntries, idx.metadata.FieldEntries, idx.analysis, doc, num)
+	idx.suggest.removeDocument(doc.ID)
+}
 
-		if len(newPositions) == 0 {
-			delete(idx.metadata.IndexEntries, word)
+// removePostings removes the content and metadata postings of a document,
+// as analyzed by a, from entries and fieldEntries
+func (idx *Index) removePostings(entries map[string][]int64, fieldEntries map[string]map[string][]int64, a *analysis, doc *Document, num int64) {
+	for _, word := range uniqueTerms(a.defaultAnalyzer.Analyze(doc.Content)) {
+		if nums := removeDocNumber(entries[word], num); len(nums) == 0 {
+			delete(entries, word)
 		} else {
-			idx.metadata.IndexEntries[word] = newPositions
+			entries[word] = nums
 		}
 	}
 
-	// Remove document position
-	delete(idx.metadata.DocumentPositions, id)
-	idx.metadata.DocumentCount--
+	for field, terms := range idx.metadata.Mapping.fieldTerms(doc.Metadata, a) {
+		termEntries := fieldEntries[field]
+		for _, term := range terms {
+			if nums := removeDocNumber(termEntries[term], num); len(nums) == 0 {
+				delete(termEntries, term)
+			} else {
+				termEntries[term] = nums
+			}
+		}
+		if len(termEntries) == 0 {
+			delete(fieldEntries, field)
+		}
+	}
+}
 
-	return idx.saveMetadata()
+func removeDocNumber(nums []int64, num int64) []int64 {
+	kept := make([]int64, 0, len(nums))
+	for _, n := range nums {
+		if n != num {
+			kept = append(kept, n)
+		}
+	}
+	return kept
 }
 
 func (idx *Index) PatternSearch(pattern string) ([]*Document, error) {
-	idx.mutex.RLock()
-	defer idx.mutex.RUnlock()
+	v := idx.acquire()
+	defer v.release()
+	return v.patternSearch(pattern)
+}
 
+// patternSearch returns the documents with a word containing pattern
+func (v *view) patternSearch(pattern string) ([]*Document, error) {
 	pattern = strings.ToLower(strings.Trim(pattern, ",.!? \t\n\r"))
 	if pattern == "" {
 		return nil, nil
 	}
 
 	// Don't trim asterisks from the pattern here
-	positions := make(map[int64]struct{})
-	for word, wordPositions := range idx.metadata.IndexEntries {
+	matched := make(docSet)
+	for word, nums := range v.metadata.IndexEntries {
 
 		if strings.Contains(word, pattern) {
-			for _, pos := range wordPositions {
-				positions[pos] = struct{}{}
+			for _, num := range nums {
+				matched[num] = struct{}{}
 			}
 		}
 	}
 
 	// ...rest of existing PatternSearch code...
-	docs := make([]*Document, 0, len(positions))
-	for pos := range positions {
-		doc, err := idx.readDocumentAt(pos)
+	docs := make([]*Document, 0, len(matched))
+	for num := range matched {
+		doc, err := v.readDocument(num)
 		if err != nil {
 			return nil, err
 		}
@@ -236,8 +503,8 @@ func (idx *Index) PatternSearch(pattern string) ([]*Document, error) {
 }
 
 func (idx *Index) Search(query string, containsMode bool) ([]*Document, error) {
-	idx.mutex.RLock()
-	defer idx.mutex.RUnlock()
+	v := idx.acquire()
+	defer v.release()
 
 	words := strings.Fields(strings.ToLower(query))
 	if len(words) == 0 {
@@ -246,41 +513,22 @@ func (idx *Index) Search(query string, containsMode bool) ([]*Document, error) {
 
 	lastWord := words[len(words)-1]
 	if containsMode {
-		return idx.PatternSearch(lastWord)
+		return v.patternSearch(lastWord)
 	}
 
 	// Regular search continues...
-	// Get positions for all words
-	wordPositions := make([]map[int64]struct{}, len(words))
-	for i, word := range words {
-		word = strings.Trim(word, ",.!? \t\n\r")
-		positions := idx.metadata.IndexEntries[word]
-		posMap := make(map[int64]struct{})
-		for _, pos := range positions {
-			posMap[pos] = struct{}{}
-		}
-		wordPositions[i] = posMap
-	}
-
-	// Find intersection of all positions
-	var commonPositions []int64
-	for pos := range wordPositions[0] {
-		found := true
-		for _, posMap := range wordPositions[1:] {
-			if _, ok := posMap[pos]; !ok {
-				found = false
-				break
-			}
-		}
-		if found {
-			commonPositions = append(commonPositions, pos)
-		}
+	// Analyze the query with the search analyzer; synonyms of a word are
+	// alternatives, every other word must match
+	slots := querySlots(v.analysis.forSearch(nil).AnalyzeTokens(query))
+	if len(slots) == 0 {
+		return nil, nil
 	}
+	common := v.slotDocs(contentField, slots)
 
-	// Read documents at common positions
-	docs := make([]*Document, 0, len(commonPositions))
-	for _, pos := range commonPositions {
-		doc, err := idx.readDocumentAt(pos)
+	// Read the documents matching every word
+	docs := make([]*Document, 0, len(common))
+	for num := range common {
+		doc, err := v.readDocument(num)
 		if err != nil {
 			return nil, err
 		}
@@ -290,144 +538,225 @@ func (idx *Index) Search(query string, containsMode bool) ([]*Document, error) {
 	return docs, nil
 }
 
-func (idx *Index) readDocumentAt(pos int64) (*Document, error) {
-	_, err := idx.docFile.Seek(pos, 0)
-	if err != nil {
-		return nil, err
+func (idx *Index) Close() error {
+	// Running tasks take the lock per batch, so stop them first
+	idx.tasks.stop()
+	idx.pits.closeAll()
+
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+
+	if err := idx.saveMetadata(); err != nil {
+		return err
 	}
 
-	decoder := gob.NewDecoder(idx.docFile)
-	doc := &Document{}
-	if err := decoder.Decode(doc); err != nil {
-		return nil, err
+	for _, files := range append(idx.retired, idx.files) {
+		files.release()
 	}
+	if err := idx.docFile.Close(); err != nil {
+		return err
+	}
+	if err := idx.tailFile.Close(); err != nil {
+		return err
+	}
+
+	return idx.indexFile.Close()
+}
+
+// Add method to get document count
+func (idx *Index) DocumentCount() int {
+	v := idx.acquire()
+	defer v.release()
+	return v.metadata.DocumentCount
+}
+
+// Add method to list all document IDs
+func (idx *Index) ListDocumentIDs() []string {
+	v := idx.acquire()
+	defer v.release()
+
+	ids := make([]string, 0, len(v.metadata.DocumentNumbers))
+	for id := range v.metadata.DocumentNumbers {
+		ids = append(ids, id)
+	}
+	return ids
+}
 
-	return doc, nil
+// GetMapping returns a copy of the current metadata mapping
+func (idx *Index) GetMapping() Mapping {
+	v := idx.acquire()
+	defer v.release()
+	return v.metadata.Mapping.clone()
 }
 
-func (idx *Index) Compact() error {
+// PutMapping adds explicit field mappings and optionally changes the dynamic
+// mode. Existing fields cannot change type.
+func (idx *Index) PutMapping(m Mapping) error {
 	idx.mutex.Lock()
 	defer idx.mutex.Unlock()
 
-	// Create temporary files
-	tempDocPath := filepath.Join(idx.baseDir, "documents", "docs.dat.tmp")
-	tempDoc, err := os.Create(tempDocPath)
+	mapping, err := idx.mergeMapping(m)
 	if err != nil {
 		return err
 	}
-	defer tempDoc.Close()
-
-	// Create new position map
-	newPositions := make(map[string]int64)
+	idx.metadata.Mapping = mapping
+	return idx.saveMetadata()
+}
 
-	// Copy valid documents to temporary file
-	for id, oldPos := range idx.metadata.DocumentPositions {
-		doc, err := idx.readDocumentAt(oldPos)
-		if err != nil {
-			continue // Skip corrupted documents
+// mergeMapping returns the mapping with m added, or why m cannot be added.
+// The caller holds idx.mutex.
+func (idx *Index) mergeMapping(m Mapping) (Mapping, error) {
+	mapping := idx.metadata.Mapping.clone()
+	if m.Fields == nil {
+		m.Fields = make(map[string]*FieldMapping)
+	}
+	for path, fm := range m.Fields {
+		if err := validateFieldAnalyzers(path, fm, idx.analysis); err != nil {
+			return Mapping{}, err
 		}
+	}
+	if err := mapping.merge(m); err != nil {
+		return Mapping{}, err
+	}
+	return mapping, nil
+}
 
-		newPos, err := tempDoc.Seek(0, 2)
-		if err != nil {
-			return err
+// validateFieldAnalyzers checks that a field and its sub-fields refer to
+// known analyzers, and that index-time analyzers are not updateable
+func validateFieldAnalyzers(path string, fm *FieldMapping, a *analysis) error {
+	if fm.Analyzer != "" {
+		if _, err := a.indexAnalyzer(fm.Analyzer); err != nil {
+			return &MappingError{Field: path, Reason: err.Error()}
 		}
-
-		encoder := gob.NewEncoder(tempDoc)
-		if err := encoder.Encode(doc); err != nil {
+	}
+	if fm.SearchAnalyzer != "" {
+		if _, ok := a.analyzers[fm.SearchAnalyzer]; !ok {
+			return &MappingError{Field: path, Reason: fmt.Sprintf("uses unknown search analyzer %q", fm.SearchAnalyzer)}
+		}
+	}
+	for name, sub := range fm.Fields {
+		if err := validateFieldAnalyzers(path+"."+name, sub, a); err != nil {
 			return err
 		}
-
-		newPositions[id] = newPos
 	}
+	return nil
+}
+
+// GetAnalysis returns the analysis settings of the index
+func (idx *Index) GetAnalysis() AnalysisSettings {
+	v := idx.acquire()
+	defer v.release()
+	return v.metadata.Analysis
+}
+
+// UpdateAnalysis replaces the analysis settings. Once the index holds
+// documents, the analyzers used at index time must stay the same; search
+// analyzers and their synonyms can change freely. ReindexWithAnalysis
+// changes them all.
+func (idx *Index) UpdateAnalysis(settings AnalysisSettings) error {
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
 
-	// Update inverted index with new positions
-	newIndexEntries := make(map[string][]int64)
-	for word, positions := range idx.metadata.IndexEntries {
-		newWordPositions := make([]int64, 0, len(positions))
-		for _, oldPos := range positions {
-			for id, newPos := range newPositions {
-				if idx.metadata.DocumentPositions[id] == oldPos {
-					newWordPositions = append(newWordPositions, newPos)
-					break
-				}
+	if idx.metadata.DocumentCount > 0 {
+		for _, name := range idx.indexTimeAnalyzers() {
+			if !idx.metadata.Analysis.sameAnalyzer(settings, name) {
+				return fmt.Errorf("%w: analyzer %q is used at index time and cannot change on an index with %d documents without reindexing", ErrConflict, name, idx.metadata.DocumentCount)
 			}
 		}
-		if len(newWordPositions) > 0 {
-			newIndexEntries[word] = newWordPositions
+		if settings.Analyzer != idx.metadata.Analysis.Analyzer {
+			return fmt.Errorf("%w: the default analyzer cannot change on an index with %d documents without reindexing", ErrConflict, idx.metadata.DocumentCount)
 		}
 	}
 
-	// Close current file
-	idx.docFile.Close()
-
-	// Replace old file with new one
-	if err := os.Rename(tempDocPath, filepath.Join(idx.baseDir, "documents", "docs.dat")); err != nil {
-		return err
-	}
-
-	// Reopen file and update metadata
-	idx.docFile, err = os.OpenFile(
-		filepath.Join(idx.baseDir, "documents", "docs.dat"),
-		os.O_RDWR,
-		0644,
-	)
+	a, err := newAnalysis(settings, idx.analysisDir(), nil)
 	if err != nil {
-		return err
+		return fmt.Errorf("%w: %v", ErrInvalidQuery, err)
+	}
+	for path, fm := range idx.metadata.Mapping.Fields {
+		if err := validateFieldAnalyzers(path, fm, a); err != nil {
+			return err
+		}
 	}
 
-	idx.metadata.DocumentPositions = newPositions
-	idx.metadata.IndexEntries = newIndexEntries
-
+	idx.metadata.Analysis = settings
+	idx.analysis = a
 	return idx.saveMetadata()
 }
 
-func (idx *Index) Close() error {
+// indexTimeAnalyzers lists the analyzers content and metadata are indexed with
+func (idx *Index) indexTimeAnalyzers() []string {
+	names := []string{idx.metadata.Analysis.Analyzer}
+	if names[0] == "" {
+		names[0] = defaultAnalyzerName
+	}
+	var collect func(fm *FieldMapping)
+	collect = func(fm *FieldMapping) {
+		if fm.Analyzer != "" {
+			names = append(names, fm.Analyzer)
+		}
+		for _, sub := range fm.Fields {
+			collect(sub)
+		}
+	}
+	for _, fm := range idx.metadata.Mapping.Fields {
+		collect(fm)
+	}
+	return uniqueTerms(names)
+}
+
+// ReloadSearchAnalyzers re-reads the rules of updateable synonym filters,
+// so that edited synonym files take effect without reindexing. It returns
+// the analyzers that were reloaded.
+func (idx *Index) ReloadSearchAnalyzers() ([]string, error) {
 	idx.mutex.Lock()
 	defer idx.mutex.Unlock()
 
-	if err := idx.saveMetadata(); err != nil {
-		return err
+	a, err := newAnalysis(idx.metadata.Analysis, idx.analysisDir(), idx.analysis)
+	if err != nil {
+		return nil, err
 	}
+	idx.analysis = a
+	idx.publish()
 
-	if err := idx.docFile.Close(); err != nil {
-		return err
+	var reloaded []string
+	for _, name := range a.names() {
+		if a.analyzers[name].updateable {
+			reloaded = append(reloaded, name)
+		}
 	}
-
-	return idx.indexFile.Close()
+	return reloaded, nil
 }
 
-// Add method to get document count
-func (idx *Index) DocumentCount() int {
-	idx.mutex.RLock()
-	defer idx.mutex.RUnlock()
-	return idx.metadata.DocumentCount
-}
+// Analyze runs text through a named analyzer, or the index default when
+// name is empty, and returns the resulting terms
+func (idx *Index) Analyze(name, text string) ([]string, error) {
+	v := idx.acquire()
+	defer v.release()
 
-// Add method to list all document IDs
-func (idx *Index) ListDocumentIDs() []string {
-	idx.mutex.RLock()
-	defer idx.mutex.RUnlock()
-
-	ids := make([]string, 0, len(idx.metadata.DocumentPositions))
-	for id := range idx.metadata.DocumentPositions {
-		ids = append(ids, id)
+	if name == "" {
+		return v.analysis.defaultAnalyzer.Analyze(text), nil
 	}
-	return ids
+	analyzer, ok := v.analysis.analyzers[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown analyzer %q, available: %s", ErrInvalidQuery, name, strings.Join(v.analysis.names(), ", "))
+	}
+	return analyzer.Analyze(text), nil
 }
 
 func (idx *Index) GetStats() map[string]interface{} {
-	idx.mutex.RLock()
-	defer idx.mutex.RUnlock()
+	v := idx.acquire()
+	defer v.release()
 
 	stats := map[string]interface{}{
-		"documentCount": idx.metadata.DocumentCount,
-		"uniqueWords":   len(idx.metadata.IndexEntries),
+		"documentCount": v.metadata.DocumentCount,
+		"uniqueWords":   len(v.metadata.IndexEntries),
+		"deadBytes":     v.metadata.DeadBytes,
 	}
 
 	// Calculate total indexed words
 	totalWords := 0
-	for _, positions := range idx.metadata.IndexEntries {
-		totalWords += len(positions)
+	for _, nums := range v.metadata.IndexEntries {
+		totalWords += len(nums)
 	}
 	stats["totalIndexedWords"] = totalWords
 
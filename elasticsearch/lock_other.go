@@ -0,0 +1,13 @@
+//go:build !unix
+
+package hamfts
+
+import "os"
+
+// Without flock, only the processes of one server are kept apart
+
+func lockFile(f *os.File) error {
+	return nil
+}
+
+func unlockFile(f *os.File) {}
@@ -0,0 +1,42 @@
+//go:build unix
+
+package hamfts
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRepositoryLockedByAnotherProcess(t *testing.T) {
+	repo, err := os.MkdirTemp("", "hamfts_test_repo_lock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(repo)
+
+	// flock locks belong to the open file, so a second handle stands in for
+	// another process
+	f, err := os.OpenFile(filepath.Join(repo, "lock"), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := lockFile(f); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lockRepository(repo); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected a conflict, got %v", err)
+	}
+	if err := DeleteSnapshot(repo, "any"); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected delete to fail with a conflict, got %v", err)
+	}
+
+	unlockFile(f)
+	unlock, err := lockRepository(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unlock()
+}
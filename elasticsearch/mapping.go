@@ -0,0 +1,355 @@
+package hamfts
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DynamicMode controls what happens when a document carries a metadata
+// field that is not in the mapping yet.
+type DynamicMode string
+
+const (
+	// DynamicTrue adds unknown fields to the mapping using the inferred type.
+	DynamicTrue DynamicMode = "true"
+	// DynamicFalse keeps unknown fields in the stored document but leaves them unmapped.
+	DynamicFalse DynamicMode = "false"
+	// DynamicStrict rejects documents that carry unknown fields.
+	DynamicStrict DynamicMode = "strict"
+)
+
+type FieldType string
+
+const (
+	FieldText    FieldType = "text"
+	FieldKeyword FieldType = "keyword"
+	FieldLong    FieldType = "long"
+	FieldDouble  FieldType = "double"
+	FieldBoolean FieldType = "boolean"
+	FieldDate    FieldType = "date"
+	FieldObject  FieldType = "object"
+	// FieldNested is an array of objects whose elements can be queried one
+	// at a time with a nested query. It is never inferred, only mapped.
+	FieldNested FieldType = "nested"
+	// FieldSearchAsYouType is a text field that also indexes the prefixes
+	// of its terms, for autocomplete with prefix and match_bool_prefix
+	// queries. It is never inferred, only mapped.
+	FieldSearchAsYouType FieldType = "search_as_you_type"
+	// FieldCompletion holds suggestion inputs served by the completion
+	// suggester. It is never inferred, only mapped.
+	FieldCompletion FieldType = "completion"
+)
+
+// analyzed reports whether values of the field type go through an analyzer
+func (t FieldType) analyzed() bool {
+	return t == FieldText || t == FieldSearchAsYouType
+}
+
+type FieldMapping struct {
+	Type           FieldType                `json:"type"`
+	Analyzer       string                   `json:"analyzer,omitempty"`        // text fields only, defaults to the index analyzer
+	SearchAnalyzer string                   `json:"search_analyzer,omitempty"` // defaults to Analyzer
+	Fields         map[string]*FieldMapping `json:"fields,omitempty"`          // sub-fields, e.g. text -> keyword
+	Contexts       []ContextMapping         `json:"contexts,omitempty"`        // completion fields only
+}
+
+// ContextMapping declares a category context of a completion field. Values
+// come from the suggestion itself or from the metadata field at Path.
+type ContextMapping struct {
+	Name string `json:"name"`
+	Type string `json:"type"` // only "category" is supported
+	Path string `json:"path,omitempty"`
+}
+
+// Mapping holds the field types of document metadata. Object fields are
+// flattened, so "user.name" is its own entry next to the "user" object.
+type Mapping struct {
+	Dynamic DynamicMode              `json:"dynamic"`
+	Fields  map[string]*FieldMapping `json:"properties"`
+}
+
+// MappingError is returned when document metadata or a mapping update is
+// rejected by the current mapping.
+type MappingError struct {
+	Field  string
+	Reason string
+}
+
+func (e *MappingError) Error() string {
+	return fmt.Sprintf("mapping error: field %q %s", e.Field, e.Reason)
+}
+
+func conflictError(path string, existing, got FieldType) *MappingError {
+	return &MappingError{
+		Field:  path,
+		Reason: fmt.Sprintf("is mapped as %s, got %s", existing, got),
+	}
+}
+
+func ParseDynamicMode(s string) (DynamicMode, error) {
+	switch mode := DynamicMode(strings.ToLower(s)); mode {
+	case DynamicTrue, DynamicFalse, DynamicStrict:
+		return mode, nil
+	}
+	return "", fmt.Errorf("invalid dynamic mode %q: want true, false or strict", s)
+}
+
+func newMapping() Mapping {
+	return Mapping{
+		Dynamic: DynamicTrue,
+		Fields:  make(map[string]*FieldMapping),
+	}
+}
+
+func (m Mapping) clone() Mapping {
+	c := Mapping{
+		Dynamic: m.Dynamic,
+		Fields:  make(map[string]*FieldMapping, len(m.Fields)),
+	}
+	for path, fm := range m.Fields {
+		c.Fields[path] = fm.clone()
+	}
+	return c
+}
+
+func (fm *FieldMapping) clone() *FieldMapping {
+	c := &FieldMapping{Type: fm.Type, Analyzer: fm.Analyzer, SearchAnalyzer: fm.SearchAnalyzer}
+	if fm.Contexts != nil {
+		c.Contexts = append([]ContextMapping(nil), fm.Contexts...)
+	}
+	if fm.Fields != nil {
+		c.Fields = make(map[string]*FieldMapping, len(fm.Fields))
+		for name, sub := range fm.Fields {
+			c.Fields[name] = sub.clone()
+		}
+	}
+	return c
+}
+
+// FieldNames returns the mapped field paths in sorted order.
+func (m Mapping) FieldNames() []string {
+	names := make([]string, 0, len(m.Fields))
+	for path := range m.Fields {
+		names = append(names, path)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// merge adds the fields of other to m, failing on the first field whose type
+// differs from the one already mapped.
+func (m *Mapping) merge(other Mapping) error {
+	if other.Dynamic != "" {
+		mode, err := ParseDynamicMode(string(other.Dynamic))
+		if err != nil {
+			return err
+		}
+		m.Dynamic = mode
+	}
+
+	for _, path := range other.FieldNames() {
+		fm := other.Fields[path]
+		if !validFieldType(fm.Type) {
+			return &MappingError{Field: path, Reason: fmt.Sprintf("has unknown type %q", fm.Type)}
+		}
+		if (fm.Analyzer != "" || fm.SearchAnalyzer != "") && !fm.Type.analyzed() {
+			return &MappingError{Field: path, Reason: "has an analyzer but is not a text field"}
+		}
+		if err := validateContexts(path, fm); err != nil {
+			return err
+		}
+		if existing, ok := m.Fields[path]; ok {
+			if existing.Type != fm.Type {
+				return conflictError(path, existing.Type, fm.Type)
+			}
+			if existing.Analyzer != fm.Analyzer {
+				return &MappingError{
+					Field:  path,
+					Reason: fmt.Sprintf("uses analyzer %q, cannot change to %q", existing.Analyzer, fm.Analyzer),
+				}
+			}
+			if !reflect.DeepEqual(existing.Contexts, fm.Contexts) {
+				return &MappingError{Field: path, Reason: "cannot change its contexts"}
+			}
+			// The search analyzer does not affect stored postings
+			existing.SearchAnalyzer = fm.SearchAnalyzer
+			continue
+		}
+		m.Fields[path] = fm.clone()
+	}
+	return nil
+}
+
+func validateContexts(path string, fm *FieldMapping) error {
+	if len(fm.Contexts) > 0 && fm.Type != FieldCompletion {
+		return &MappingError{Field: path, Reason: "has contexts but is not a completion field"}
+	}
+	seen := make(map[string]bool, len(fm.Contexts))
+	for _, ctx := range fm.Contexts {
+		if ctx.Name == "" || seen[ctx.Name] {
+			return &MappingError{Field: path, Reason: fmt.Sprintf("has a missing or duplicate context name %q", ctx.Name)}
+		}
+		if ctx.Type != "category" {
+			return &MappingError{Field: path, Reason: fmt.Sprintf("has context %q of unsupported type %q", ctx.Name, ctx.Type)}
+		}
+		seen[ctx.Name] = true
+	}
+	return nil
+}
+
+// apply infers the type of every metadata value and records new fields
+// according to the dynamic setting.
+func (m *Mapping) apply(meta map[string]interface{}) error {
+	return m.applyObject("", meta)
+}
+
+func (m *Mapping) applyObject(prefix string, obj map[string]interface{}) error {
+	// Walk keys in order so strict-mode errors are deterministic
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		if err := m.applyValue(path, obj[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Mapping) applyValue(path string, value interface{}) error {
+	// Completion values may be objects, which must not be mapped as such
+	if fm, ok := m.Fields[path]; ok && fm.Type == FieldCompletion {
+		if _, err := parseCompletionValue(value); err != nil {
+			return &MappingError{Field: path, Reason: err.Error()}
+		}
+		return nil
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		for _, elem := range v {
+			if err := m.applyValue(path, elem); err != nil {
+				return err
+			}
+		}
+		return nil
+	case []string:
+		for _, elem := range v {
+			if err := m.applyValue(path, elem); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	t, ok := inferFieldType(value)
+	if !ok {
+		return &MappingError{Field: path, Reason: fmt.Sprintf("has unsupported value type %T", value)}
+	}
+
+	mapped, err := m.resolve(path, t)
+	if err != nil || !mapped {
+		return err
+	}
+
+	if obj, ok := value.(map[string]interface{}); ok {
+		return m.applyObject(path, obj)
+	}
+	return nil
+}
+
+// resolve checks an inferred type against the mapping, adding the field if
+// dynamic mapping allows it. It reports whether the field is mapped.
+func (m *Mapping) resolve(path string, t FieldType) (bool, error) {
+	if existing, ok := m.Fields[path]; ok {
+		if !compatibleFieldType(existing.Type, t) {
+			return false, conflictError(path, existing.Type, t)
+		}
+		return true, nil
+	}
+
+	switch m.Dynamic {
+	case DynamicStrict:
+		return false, &MappingError{Field: path, Reason: "is not mapped and dynamic mapping is strict"}
+	case DynamicFalse:
+		return false, nil
+	}
+
+	fm := &FieldMapping{Type: t}
+	if t == FieldText {
+		fm.Fields = map[string]*FieldMapping{
+			"keyword": {Type: FieldKeyword},
+		}
+	}
+	m.Fields[path] = fm
+	return true, nil
+}
+
+func inferFieldType(value interface{}) (FieldType, bool) {
+	switch v := value.(type) {
+	case string:
+		if _, err := time.Parse(time.RFC3339, v); err == nil {
+			return FieldDate, true
+		}
+		return FieldText, true
+	case bool:
+		return FieldBoolean, true
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return FieldLong, true
+	case float32:
+		if float32(int64(v)) == v {
+			return FieldLong, true
+		}
+		return FieldDouble, true
+	case float64:
+		// encoding/json decodes every number as float64
+		if float64(int64(v)) == v {
+			return FieldLong, true
+		}
+		return FieldDouble, true
+	case time.Time:
+		return FieldDate, true
+	case map[string]interface{}:
+		return FieldObject, true
+	}
+	return "", false
+}
+
+// compatibleFieldType reports whether a value inferred as got can be stored
+// in a field mapped as existing.
+func compatibleFieldType(existing, got FieldType) bool {
+	if existing == got {
+		return true
+	}
+	switch existing {
+	case FieldLong, FieldDouble:
+		return got == FieldLong || got == FieldDouble
+	case FieldText, FieldKeyword, FieldSearchAsYouType:
+		// Dates arrive as strings, so they fit any string field
+		return got == FieldText || got == FieldDate
+	case FieldNested:
+		return got == FieldObject
+	}
+	return false
+}
+
+func validFieldType(t FieldType) bool {
+	switch t {
+	case FieldText, FieldKeyword, FieldLong, FieldDouble, FieldBoolean, FieldDate, FieldObject, FieldNested,
+		FieldSearchAsYouType, FieldCompletion:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package hamfts
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestDynamicMapping(t *testing.T) {
+	testDir, err := os.MkdirTemp("", "hamfts_test_mapping")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+
+	idx, err := NewIndex(testDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doc := NewDocument("1", "first document")
+	doc.Metadata["title"] = "Hello"
+	doc.Metadata["views"] = float64(10)
+	doc.Metadata["rating"] = 4.5
+	doc.Metadata["published"] = "2024-01-02T15:04:05Z"
+	doc.Metadata["author"] = map[string]interface{}{"name": "bob", "age": float64(30)}
+	if err := idx.AddDocument(doc); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]FieldType{
+		"title":       FieldText,
+		"views":       FieldLong,
+		"rating":      FieldDouble,
+		"published":   FieldDate,
+		"author":      FieldObject,
+		"author.name": FieldText,
+		"author.age":  FieldLong,
+	}
+	mapping := idx.GetMapping()
+	for path, typ := range want {
+		fm, ok := mapping.Fields[path]
+		if !ok {
+			t.Errorf("field %q not mapped", path)
+			continue
+		}
+		if fm.Type != typ {
+			t.Errorf("field %q mapped as %s, want %s", path, fm.Type, typ)
+		}
+	}
+	if mapping.Fields["title"].Fields["keyword"] == nil {
+		t.Error("expected keyword sub-field on text field")
+	}
+
+	// Conflicting type is rejected and nothing is indexed
+	bad := NewDocument("2", "second document")
+	bad.Metadata["views"] = "many"
+	var mappingErr *MappingError
+	if err := idx.AddDocument(bad); !errors.As(err, &mappingErr) {
+		t.Fatalf("expected MappingError, got %v", err)
+	}
+	if count := idx.DocumentCount(); count != 1 {
+		t.Errorf("Expected count 1 after rejected document, got %d", count)
+	}
+
+	// Mapping survives a restart
+	if err := idx.Close(); err != nil {
+		t.Fatal(err)
+	}
+	idx, err = NewIndexWithOptions(testDir, IndexOptions{Dynamic: DynamicStrict})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+
+	if got := idx.GetMapping().Fields["published"]; got == nil || got.Type != FieldDate {
+		t.Errorf("mapping not persisted, got %v", got)
+	}
+
+	strict := NewDocument("3", "third document")
+	strict.Metadata["unknown"] = true
+	if err := idx.AddDocument(strict); !errors.As(err, &mappingErr) {
+		t.Fatalf("expected strict mapping to reject unknown field, got %v", err)
+	}
+
+	if err := idx.PutMapping(Mapping{Fields: map[string]*FieldMapping{
+		"unknown": {Type: FieldBoolean},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := idx.AddDocument(strict); err != nil {
+		t.Fatal(err)
+	}
+
+	// Unmapped fields are kept but ignored when dynamic is false
+	if err := idx.PutMapping(Mapping{Dynamic: DynamicFalse}); err != nil {
+		t.Fatal(err)
+	}
+	loose := NewDocument("4", "fourth document")
+	loose.Metadata["extra"] = "value"
+	if err := idx.AddDocument(loose); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := idx.GetMapping().Fields["extra"]; ok {
+		t.Error("field should not be mapped when dynamic is false")
+	}
+}
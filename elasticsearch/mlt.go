@@ -0,0 +1,212 @@
+package hamfts
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// MoreLikeThisQuery finds documents similar to a stored document or to raw
+// text. The most significant terms of the source by TF-IDF are combined in a
+// weighted OR query; the source document itself never matches.
+type MoreLikeThisQuery struct {
+	ID     string   `json:"id,omitempty"`     // document to start from
+	Text   string   `json:"text,omitempty"`   // or raw text
+	Fields []string `json:"fields,omitempty"` // defaults to content
+
+	MaxQueryTerms int `json:"max_query_terms,omitempty"` // default 25
+	MinTermFreq   int `json:"min_term_freq,omitempty"`   // in the source, default 1
+	MinDocFreq    int `json:"min_doc_freq,omitempty"`    // in the index, default 1
+	MinWordLength int `json:"min_word_length,omitempty"`
+	Size          int `json:"size,omitempty"` // for MoreLikeThis, default 10
+}
+
+// mltTerm is a selected query term and its weight
+type mltTerm struct {
+	field  string
+	term   string
+	weight float64
+}
+
+// scoredDocument is a document and its more_like_this score
+type scoredDocument struct {
+	doc   *Document
+	score float64
+}
+
+// MoreLikeThis returns the documents most similar to the source, best first
+func (idx *Index) MoreLikeThis(q MoreLikeThisQuery) ([]*Document, error) {
+	v := idx.acquire()
+	defer v.release()
+
+	hits, err := v.moreLikeThis(q, nil)
+	if err != nil {
+		return nil, err
+	}
+	docs := make([]*Document, 0, len(hits))
+	for _, hit := range hits {
+		docs = append(docs, hit.doc)
+	}
+	return docs, nil
+}
+
+// moreLikeThis returns the size best scoring documents. A source document
+// given by the caller is used instead of looking up q.ID, which lets the
+// shards of a ShardedIndex that do not hold it take part.
+func (v *view) moreLikeThis(q MoreLikeThisQuery, source *Document) ([]scoredDocument, error) {
+	terms, exclude, err := v.mltTerms(q, source)
+	if err != nil {
+		return nil, err
+	}
+
+	scores := make(map[int64]float64)
+	for _, t := range terms {
+		for num := range v.termDocs(t.field, t.term) {
+			scores[num] += t.weight
+		}
+	}
+	delete(scores, exclude)
+
+	ranked := make([]int64, 0, len(scores))
+	for num := range scores {
+		ranked = append(ranked, num)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if scores[ranked[i]] != scores[ranked[j]] {
+			return scores[ranked[i]] > scores[ranked[j]]
+		}
+		return ranked[i] < ranked[j]
+	})
+
+	size := q.Size
+	if size <= 0 {
+		size = 10
+	}
+	if len(ranked) > size {
+		ranked = ranked[:size]
+	}
+
+	hits := make([]scoredDocument, 0, len(ranked))
+	for _, num := range ranked {
+		doc, err := v.readDocument(num)
+		if err != nil {
+			return nil, err
+		}
+		hits = append(hits, scoredDocument{doc: doc, score: scores[num]})
+	}
+	return hits, nil
+}
+
+// evalMoreLikeThis matches every document that has one of the selected terms
+func (v *view) evalMoreLikeThis(q *MoreLikeThisQuery) (docSet, error) {
+	terms, exclude, err := v.mltTerms(*q, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(docSet)
+	for _, t := range terms {
+		for num := range v.termDocs(t.field, t.term) {
+			result[num] = struct{}{}
+		}
+	}
+	delete(result, exclude)
+	return result, nil
+}
+
+// mltTerms picks the query terms of a more_like_this query and returns the
+// doc number of the source document, or -1 for raw text and for a source
+// this index does not hold
+func (v *view) mltTerms(q MoreLikeThisQuery, source *Document) ([]mltTerm, int64, error) {
+	if (q.ID == "") == (q.Text == "") {
+		return nil, 0, queryError("more_like_this needs exactly one of id or text")
+	}
+	fields := q.Fields
+	if len(fields) == 0 {
+		fields = []string{contentField}
+	}
+
+	exclude := int64(-1)
+	if source != nil {
+		if num, ok := v.metadata.DocumentNumbers[q.ID]; ok {
+			exclude = num
+		}
+	} else if q.ID != "" {
+		num, ok := v.metadata.DocumentNumbers[q.ID]
+		if !ok {
+			return nil, 0, fmt.Errorf("more_like_this document %q: %w", q.ID, ErrNotFound)
+		}
+		doc, err := v.readDocument(num)
+		if err != nil {
+			return nil, 0, err
+		}
+		source, exclude = doc, num
+	}
+
+	// Term frequencies in the source, analyzed like the indexed field
+	freqs := make(map[string]map[string]int)
+	for _, field := range fields {
+		var terms []string
+		switch {
+		case field == contentField && source != nil:
+			terms = v.analysis.defaultAnalyzer.Analyze(source.Content)
+		case field == contentField:
+			terms = v.analysis.defaultAnalyzer.Analyze(q.Text)
+		default:
+			fm, ok := v.metadata.Mapping.lookupField(field)
+			if !ok || !fm.Type.analyzed() {
+				return nil, 0, queryError("more_like_this field %q is not a text field", field)
+			}
+			if source == nil {
+				terms = valueTerms(fm, q.Text, v.analysis)
+				break
+			}
+			values := make(map[string][]interface{})
+			flattenMetadata("", source.Metadata, values)
+			for _, value := range values[fieldSource(v.metadata.Mapping, field)] {
+				terms = append(terms, valueTerms(fm, value, v.analysis)...)
+			}
+		}
+
+		freqs[field] = make(map[string]int)
+		for _, term := range terms {
+			freqs[field][term]++
+		}
+	}
+
+	minTermFreq := max(q.MinTermFreq, 1)
+	minDocFreq := max(q.MinDocFreq, 1)
+	docCount := float64(v.metadata.DocumentCount)
+	var selected []mltTerm
+	for field, terms := range freqs {
+		for term, tf := range terms {
+			if tf < minTermFreq || len([]rune(term)) < q.MinWordLength {
+				continue
+			}
+			df := len(v.termDocs(field, term))
+			if df < minDocFreq {
+				continue
+			}
+			idf := 1 + math.Log(docCount/float64(df+1))
+			selected = append(selected, mltTerm{field: field, term: term, weight: float64(tf) * idf})
+		}
+	}
+
+	sort.Slice(selected, func(i, j int) bool {
+		if selected[i].weight != selected[j].weight {
+			return selected[i].weight > selected[j].weight
+		}
+		if selected[i].field != selected[j].field {
+			return selected[i].field < selected[j].field
+		}
+		return selected[i].term < selected[j].term
+	})
+	maxTerms := q.MaxQueryTerms
+	if maxTerms <= 0 {
+		maxTerms = 25
+	}
+	if len(selected) > maxTerms {
+		selected = selected[:maxTerms]
+	}
+	return selected, exclude, nil
+}
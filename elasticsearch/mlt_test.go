@@ -0,0 +1,85 @@
+package hamfts
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestMoreLikeThis(t *testing.T) {
+	testDir, err := os.MkdirTemp("", "hamfts_test_mlt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+
+	idx, err := NewIndex(testDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+
+	docs := []struct{ id, content, title string }{
+		{"go1", "go channels and goroutines make concurrency simple", "Go concurrency"},
+		{"go2", "goroutines and channels in go", "Go channels"},
+		{"go3", "error handling in go", "Go errors"},
+		{"py1", "python generators and asyncio", "Python async"},
+		{"misc", "and in the", "Filler"},
+	}
+	for _, d := range docs {
+		doc := NewDocument(d.id, d.content)
+		doc.Metadata["title"] = d.title
+		if err := idx.AddDocument(doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ids := func(results []*Document) []string {
+		var got []string
+		for _, doc := range results {
+			got = append(got, doc.ID)
+		}
+		return got
+	}
+
+	results, err := idx.MoreLikeThis(MoreLikeThisQuery{ID: "go1", MinWordLength: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(results); !reflect.DeepEqual(got, []string{"go2"}) {
+		t.Errorf("like go1: got %v, want [go2]", got)
+	}
+
+	// Short words are kept by default, so every document sharing "go" matches
+	results, err = idx.MoreLikeThis(MoreLikeThisQuery{ID: "go1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(results); len(got) < 3 || got[0] != "go2" {
+		t.Errorf("like go1: got %v, want go2 first", got)
+	}
+
+	results, err = idx.MoreLikeThis(MoreLikeThisQuery{Text: "python asyncio tutorial", Size: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(results); !reflect.DeepEqual(got, []string{"py1"}) {
+		t.Errorf("like text: got %v, want [py1]", got)
+	}
+
+	// As a query clause on a metadata field, combined with other clauses
+	results, err = idx.SearchQuery(Query{Bool: &BoolQuery{
+		Must:    []Query{{MoreLikeThis: &MoreLikeThisQuery{ID: "go2", Fields: []string{"title"}}}},
+		MustNot: []Query{{Term: map[string]interface{}{"title.keyword": "Go errors"}}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(results); !reflect.DeepEqual(got, []string{"go1"}) {
+		t.Errorf("clause: got %v, want [go1]", got)
+	}
+
+	if _, err := idx.MoreLikeThis(MoreLikeThisQuery{ID: "missing"}); err == nil {
+		t.Error("expected an error for a missing document")
+	}
+}
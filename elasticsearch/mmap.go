@@ -0,0 +1,60 @@
+package hamfts
+
+import "os"
+
+// mappedFile reads docs.dat through a read-only memory mapping where the
+// platform has one, and through ReadAt elsewhere. Neither moves a shared
+// file cursor, so concurrent searches never get in each other's way. The
+// mapping covers the file as it was when mapped; bytes appended since are
+// read with ReadAt.
+type mappedFile struct {
+	file *os.File
+	data []byte // nil when not mapped
+}
+
+// newMappedFile maps f at its current size if mmap is set. Failing to map
+// is not an error: reads fall back to ReadAt.
+func newMappedFile(f *os.File, mmap bool) *mappedFile {
+	m := &mappedFile{file: f}
+	if !mmap {
+		return m
+	}
+	info, err := f.Stat()
+	if err != nil || info.Size() == 0 || int64(int(info.Size())) != info.Size() {
+		return m
+	}
+	if data, err := mmapFile(f, int(info.Size())); err == nil {
+		m.data = data
+	}
+	return m
+}
+
+// ReadAt copies from the mapping, or reads the file past its end
+func (m *mappedFile) ReadAt(p []byte, off int64) (int, error) {
+	if data, ok := m.slice(off, int64(len(p))); ok {
+		return copy(p, data), nil
+	}
+	return m.file.ReadAt(p, off)
+}
+
+// slice returns n bytes at off without copying them, if they are mapped.
+// The bytes are only valid until the file is unmapped.
+func (m *mappedFile) slice(off, n int64) ([]byte, bool) {
+	if off < 0 || n < 0 || off+n > int64(len(m.data)) {
+		return nil, false
+	}
+	return m.data[off : off+n : off+n], true
+}
+
+func (m *mappedFile) unmap() {
+	if m.data != nil {
+		munmapFile(m.data)
+		m.data = nil
+	}
+}
+
+// byteSlicer is implemented by readers that can hand out their bytes
+// without copying, like mappedFile
+type byteSlicer interface {
+	slice(off, n int64) ([]byte, bool)
+}
@@ -0,0 +1,16 @@
+//go:build !unix
+
+package hamfts
+
+import (
+	"errors"
+	"os"
+)
+
+// Without mmap, mappedFile reads everything with ReadAt
+
+func mmapFile(f *os.File, size int) ([]byte, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func munmapFile(data []byte) {}
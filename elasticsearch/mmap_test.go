@@ -0,0 +1,123 @@
+package hamfts
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+)
+
+func fillIndex(tb testing.TB, idx *Index, n int) {
+	docs := make([]*Document, 0, n)
+	for i := 0; i < n; i++ {
+		docs = append(docs, NewDocument(fmt.Sprintf("doc%d", i),
+			fmt.Sprintf("document %d about topic%d with some words to fill a block, word%d", i, i%50, i%997)))
+	}
+	if err := idx.AddDocuments(docs); err != nil {
+		tb.Fatal(err)
+	}
+}
+
+func TestMappedReads(t *testing.T) {
+	for _, disable := range []bool{false, true} {
+		t.Run(fmt.Sprintf("DisableMmap=%v", disable), func(t *testing.T) {
+			testDir, err := os.MkdirTemp("", "hamfts_test_mmap")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(testDir)
+
+			idx, err := NewIndexWithOptions(testDir, IndexOptions{AutoCompactRatio: -1, DisableMmap: disable})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer idx.Close()
+			fillIndex(t, idx, 1000)
+			if mapped := idx.files.data.data != nil; mapped == disable {
+				t.Errorf("expected docs.dat mapped to be %v", !disable)
+			}
+
+			// Readers run alongside writes that pack the tail and a compaction
+			// that swaps docs.dat
+			var wg sync.WaitGroup
+			errs := make(chan error, 8)
+			for r := 0; r < 4; r++ {
+				wg.Add(1)
+				go func(r int) {
+					defer wg.Done()
+					for i := 0; i < 100; i++ {
+						id := fmt.Sprintf("doc%d", (i*7+r)%500)
+						doc, err := idx.GetDocument(id)
+						if err != nil || doc.ID != id {
+							errs <- fmt.Errorf("get %s: %v, %v", id, doc, err)
+							return
+						}
+						if docs, err := idx.Search(fmt.Sprintf("topic%d", i%50), false); err != nil || len(docs) < 15 {
+							errs <- fmt.Errorf("search topic%d: %d docs, %v", i%50, len(docs), err)
+							return
+						}
+					}
+				}(r)
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 250; i++ {
+					if err := idx.DeleteDocument(fmt.Sprintf("doc%d", 500+i)); err != nil {
+						errs <- err
+						return
+					}
+				}
+				if err := idx.Compact(); err != nil {
+					errs <- err
+				}
+			}()
+			wg.Wait()
+			close(errs)
+			for err := range errs {
+				t.Error(err)
+			}
+
+			if report, err := idx.Verify(); err != nil || !report.OK() || report.Records != 750 {
+				t.Errorf("verify: got %+v, %v", report, err)
+			}
+		})
+	}
+}
+
+// BenchmarkParallelSearch measures searches fetching their documents from
+// many goroutines at once, with docs.dat mapped and read with ReadAt
+func BenchmarkParallelSearch(b *testing.B) {
+	for _, disable := range []bool{false, true} {
+		name := "mmap"
+		if disable {
+			name = "readat"
+		}
+		b.Run(name, func(b *testing.B) {
+			testDir, err := os.MkdirTemp("", "hamfts_bench_search")
+			if err != nil {
+				b.Fatal(err)
+			}
+			defer os.RemoveAll(testDir)
+
+			idx, err := NewIndexWithOptions(testDir, IndexOptions{AutoCompactRatio: -1, DisableMmap: disable})
+			if err != nil {
+				b.Fatal(err)
+			}
+			defer idx.Close()
+			fillIndex(b, idx, 20000)
+
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				i := 0
+				for pb.Next() {
+					if _, err := idx.Search(fmt.Sprintf("word%d", i%997), false); err != nil {
+						b.Error(err)
+						return
+					}
+					i++
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package hamfts
+
+import "fmt"
+
+// Default gram sizes of the ngram and edge_ngram filters
+const (
+	defaultMinGram = 1
+	defaultMaxGram = 2
+)
+
+// maxPrefixChars is the longest prefix indexed for search_as_you_type
+// fields. Longer prefixes are matched by scanning the field's terms.
+const maxPrefixChars = 20
+
+// indexPrefixSuffix names the hidden sub-field holding the edge n-grams of a
+// search_as_you_type field, e.g. "title._index_prefix"
+const indexPrefixSuffix = "._index_prefix"
+
+// ngramFilter replaces every token by its character n-grams of length min to
+// max, so that "fox" with 1-2 grams becomes "f", "fo", "o", "ox", "x". Grams
+// keep the offsets of the token they came from.
+func ngramFilter(min, max int, preserveOriginal bool) TokenFilter {
+	return func(tokens []Token) []Token {
+		out := make([]Token, 0, len(tokens))
+		for _, token := range tokens {
+			runes := []rune(token.Term)
+			for start := 0; start < len(runes); start++ {
+				for n := min; n <= max && start+n <= len(runes); n++ {
+					gram := token
+					gram.Term = string(runes[start : start+n])
+					out = append(out, gram)
+				}
+			}
+			if preserveOriginal && (len(runes) < min || len(runes) > max) {
+				out = append(out, token)
+			}
+		}
+		return out
+	}
+}
+
+// edgeNgramFilter replaces every token by its prefixes of length min to max,
+// so that "fox" with 1-2 grams becomes "f", "fo".
+func edgeNgramFilter(min, max int, preserveOriginal bool) TokenFilter {
+	return func(tokens []Token) []Token {
+		out := make([]Token, 0, len(tokens))
+		for _, token := range tokens {
+			out = appendPrefixes(out, token, min, max)
+			if n := len([]rune(token.Term)); preserveOriginal && (n < min || n > max) {
+				out = append(out, token)
+			}
+		}
+		return out
+	}
+}
+
+func appendPrefixes(out []Token, token Token, min, max int) []Token {
+	runes := []rune(token.Term)
+	for n := min; n <= max && n <= len(runes); n++ {
+		prefix := token
+		prefix.Term = string(runes[:n])
+		out = append(out, prefix)
+	}
+	return out
+}
+
+// gramSizes validates and defaults the gram sizes of an n-gram filter config
+func gramSizes(fc FilterConfig) (int, int, error) {
+	min, max := fc.MinGram, fc.MaxGram
+	if min == 0 {
+		min = defaultMinGram
+	}
+	if max == 0 {
+		max = defaultMaxGram
+		if max < min {
+			max = min
+		}
+	}
+	if min < 1 || max < min {
+		return 0, 0, fmt.Errorf("invalid gram sizes min_gram=%d max_gram=%d", min, max)
+	}
+	return min, max, nil
+}
+
+// prefixTerms returns the edge n-grams indexed in the prefix sub-field of a
+// search_as_you_type field
+func prefixTerms(terms []string) []string {
+	var prefixes []string
+	for _, term := range terms {
+		runes := []rune(term)
+		for n := 1; n <= maxPrefixChars && n <= len(runes); n++ {
+			prefixes = append(prefixes, string(runes[:n]))
+		}
+	}
+	return prefixes
+}
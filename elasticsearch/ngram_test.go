@@ -0,0 +1,132 @@
+package hamfts
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestNgramFilters(t *testing.T) {
+	a, err := newAnalysis(AnalysisSettings{
+		Analyzers: map[string]AnalyzerConfig{
+			"ngram":     {Filters: []string{"lowercase", "ngram"}},
+			"edge":      {Filters: []string{"lowercase", "edge_ngram"}},
+			"ngram_2_3": {Filters: []string{"lowercase", "trigrams"}},
+			"edge_1_3":  {Filters: []string{"lowercase", "prefixes"}},
+		},
+		Filters: map[string]FilterConfig{
+			"trigrams": {Type: "ngram", MinGram: 2, MaxGram: 3},
+			"prefixes": {Type: "edge_ngram", MinGram: 1, MaxGram: 3, PreserveOriginal: true},
+		},
+	}, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		analyzer string
+		text     string
+		want     []string
+	}{
+		{"ngram", "Fox", []string{"f", "fo", "o", "ox", "x"}},
+		{"edge", "Fox", []string{"f", "fo"}},
+		{"ngram_2_3", "Café", []string{"ca", "caf", "af", "afé", "fé"}},
+		{"edge_1_3", "quick", []string{"q", "qu", "qui", "quick"}},
+	}
+	for _, tt := range tests {
+		if got := a.analyzers[tt.analyzer].Analyze(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s(%q) = %q, want %q", tt.analyzer, tt.text, got, tt.want)
+		}
+	}
+
+	if _, err := newAnalysis(AnalysisSettings{
+		Analyzers: map[string]AnalyzerConfig{"bad": {Filters: []string{"bad_grams"}}},
+		Filters:   map[string]FilterConfig{"bad_grams": {Type: "ngram", MinGram: 3, MaxGram: 2}},
+	}, "", nil); err == nil {
+		t.Error("expected an error for max_gram below min_gram")
+	}
+}
+
+func TestSearchAsYouType(t *testing.T) {
+	testDir, err := os.MkdirTemp("", "hamfts_test_ngram")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+
+	idx, err := NewIndexWithOptions(testDir, IndexOptions{
+		Analysis: &AnalysisSettings{
+			Analyzers: map[string]AnalyzerConfig{
+				"infix": {Filters: []string{"lowercase", "grams"}},
+			},
+			Filters: map[string]FilterConfig{
+				"grams": {Type: "ngram", MinGram: 3, MaxGram: 4},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+
+	if err := idx.PutMapping(Mapping{Fields: map[string]*FieldMapping{
+		"title": {Type: FieldSearchAsYouType},
+		"sku":   {Type: FieldText, Analyzer: "infix", SearchAnalyzer: "standard"},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+
+	docs := []struct {
+		id, title, sku string
+	}{
+		{"1", "Quick Brown Fox", "AB1234"},
+		{"2", "Quiet Night", "CD5678"},
+		{"3", "Brown Bear", "EF1299"},
+	}
+	for _, d := range docs {
+		doc := NewDocument(d.id, "")
+		doc.Metadata["title"] = d.title
+		doc.Metadata["sku"] = d.sku
+		if err := idx.AddDocument(doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		query Query
+		want  []string
+	}{
+		{"prefix", Query{Prefix: map[string]string{"title": "Qui"}}, []string{"1", "2"}},
+		{"prefix content", Query{Prefix: map[string]string{"content": "x"}}, nil},
+		{"bool prefix", Query{MatchBoolPrefix: map[string]string{"title": "brown f"}}, []string{"1"}},
+		{"bool prefix single", Query{MatchBoolPrefix: map[string]string{"title": "br"}}, []string{"1", "3"}},
+		{"whole word", Query{Match: map[string]string{"title": "quiet"}}, []string{"2"}},
+		{"infix", Query{Match: map[string]string{"sku": "123"}}, []string{"1"}},
+	}
+	for _, tt := range tests {
+		results, err := idx.SearchQuery(tt.query)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		var got []string
+		for _, doc := range results {
+			got = append(got, doc.ID)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// Prefix postings are removed with the document
+	if err := idx.DeleteDocument("1"); err != nil {
+		t.Fatal(err)
+	}
+	results, err := idx.SearchQuery(Query{Prefix: map[string]string{"title": "qui"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].ID != "2" {
+		t.Errorf("after delete: got %d results, want only 2", len(results))
+	}
+}
@@ -0,0 +1,141 @@
+package hamfts
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// A point in time keeps the view of the index it was opened on, so that
+// searches paging through the results of a query see the same documents
+// however the index changes in between. It also keeps the files of that
+// view open: a docs.dat replaced by a compaction only goes away once the
+// points in time using it are closed or expire.
+
+// maxKeepAlive bounds how long a point in time lives without being used
+const maxKeepAlive = 24 * time.Hour
+
+type pointInTime struct {
+	view    *view
+	expires time.Time
+	timer   *time.Timer
+}
+
+type pitManager struct {
+	mu   sync.Mutex
+	pits map[string]*pointInTime
+}
+
+func newPitManager() *pitManager {
+	return &pitManager{pits: make(map[string]*pointInTime)}
+}
+
+func checkKeepAlive(keepAlive time.Duration) error {
+	if keepAlive <= 0 || keepAlive > maxKeepAlive {
+		return queryError("keep_alive must be positive and at most %s, got %s", maxKeepAlive, keepAlive)
+	}
+	return nil
+}
+
+// OpenPointInTime freezes the current state of the index for searches made
+// with SearchQueryAt, and returns the ID they refer to it by. It is released
+// by ClosePointInTime, or once it has not been used for keepAlive.
+func (idx *Index) OpenPointInTime(keepAlive time.Duration) (string, error) {
+	if err := checkKeepAlive(keepAlive); err != nil {
+		return "", err
+	}
+	m := idx.pits
+	p := &pointInTime{view: idx.acquire(), expires: time.Now().Add(keepAlive)}
+	id := newTaskID()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pits[id] = p
+	p.timer = time.AfterFunc(keepAlive, func() { m.expire(id, p) })
+	return id, nil
+}
+
+// expire releases p unless it has been kept alive since its timer was set
+func (m *pitManager) expire(id string, p *pointInTime) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.pits[id] != p {
+		return
+	}
+	if left := time.Until(p.expires); left > 0 {
+		p.timer.Reset(left)
+		return
+	}
+	delete(m.pits, id)
+	p.view.release()
+}
+
+// ClosePointInTime releases a point in time before it expires
+func (idx *Index) ClosePointInTime(id string) error {
+	m := idx.pits
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p, ok := m.pits[id]
+	if !ok {
+		return fmt.Errorf("point in time %q: %w", id, ErrNotFound)
+	}
+	delete(m.pits, id)
+	p.timer.Stop()
+	p.view.release()
+	return nil
+}
+
+// use returns the view of a point in time, acquired for the caller, and
+// extends its life by keepAlive when that is set
+func (m *pitManager) use(id string, keepAlive time.Duration) (*view, error) {
+	if keepAlive != 0 {
+		if err := checkKeepAlive(keepAlive); err != nil {
+			return nil, err
+		}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p, ok := m.pits[id]
+	if !ok {
+		return nil, fmt.Errorf("point in time %q, which may have expired: %w", id, ErrNotFound)
+	}
+	if keepAlive != 0 {
+		p.expires = time.Now().Add(keepAlive)
+	}
+	// The point in time holds a reference, so the files are still open
+	p.view.files.refs.Add(1)
+	return p.view, nil
+}
+
+// closeAll releases every point in time, when the index closes
+func (m *pitManager) closeAll() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, p := range m.pits {
+		p.timer.Stop()
+		p.view.release()
+		delete(m.pits, id)
+	}
+}
+
+// SearchQueryAt runs a structured query against the point in time id, in
+// the order the documents were created. A non-zero keepAlive extends
+// the life of the point in time from now.
+func (idx *Index) SearchQueryAt(id string, keepAlive time.Duration, q Query) ([]*Document, error) {
+	v, err := idx.pits.use(id, keepAlive)
+	if err != nil {
+		return nil, err
+	}
+	defer v.release()
+	return v.searchQuery(q)
+}
+
+// SearchQueryAtPage is SearchQueryPage against the point in time id
+func (idx *Index) SearchQueryAtPage(id string, keepAlive time.Duration, q Query, from, size int) ([]*Document, int, error) {
+	v, err := idx.pits.use(id, keepAlive)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer v.release()
+	return v.searchPage(q, from, size)
+}
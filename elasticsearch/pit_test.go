@@ -0,0 +1,149 @@
+package hamfts
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPointInTime(t *testing.T) {
+	testDir, err := os.MkdirTemp("", "hamfts_test_pit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+
+	idx, err := NewIndexWithOptions(testDir, IndexOptions{AutoCompactRatio: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+	for i := 0; i < 5; i++ {
+		if err := idx.AddDocument(NewDocument(fmt.Sprintf("doc%d", i), fmt.Sprintf("page item%d", i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	id, err := idx.OpenPointInTime(time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Change the index under the point in time
+	if err := idx.DeleteDocument("doc1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := idx.AddDocument(NewDocument("doc3", "replaced")); err != nil {
+		t.Fatal(err)
+	}
+	if err := idx.AddDocument(NewDocument("doc9", "page new")); err != nil {
+		t.Fatal(err)
+	}
+	if err := idx.Compact(); err != nil {
+		t.Fatal(err)
+	}
+
+	q := Query{Match: map[string]string{"content": "page"}}
+	contents := func(docs []*Document) []string {
+		var got []string
+		for _, doc := range docs {
+			got = append(got, doc.ID+":"+doc.Content)
+		}
+		return got
+	}
+	frozen, err := idx.SearchQueryAt(id, time.Minute, q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"doc0:page item0", "doc1:page item1", "doc2:page item2", "doc3:page item3", "doc4:page item4"}
+	if got := contents(frozen); !reflect.DeepEqual(got, want) {
+		t.Errorf("point in time: expected %v, got %v", want, got)
+	}
+	current, err := idx.SearchQuery(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []string{"doc0:page item0", "doc2:page item2", "doc4:page item4", "doc9:page new"}
+	if got := contents(current); !reflect.DeepEqual(got, want) {
+		t.Errorf("current: expected %v, got %v", want, got)
+	}
+
+	// Pages count every match but only hold their own documents
+	pageDocs, total, err := idx.SearchQueryAtPage(id, 0, q, 1, 2)
+	want = []string{"doc1:page item1", "doc2:page item2"}
+	if got := contents(pageDocs); err != nil || total != 5 || !reflect.DeepEqual(got, want) {
+		t.Errorf("point in time page: expected %v of 5, got %v of %d, %v", want, got, total, err)
+	}
+	pageDocs, total, err = idx.SearchQueryPage(q, 3, 0)
+	want = []string{"doc9:page new"}
+	if got := contents(pageDocs); err != nil || total != 4 || !reflect.DeepEqual(got, want) {
+		t.Errorf("current page: expected %v of 4, got %v of %d, %v", want, got, total, err)
+	}
+	if pageDocs, total, err := idx.SearchQueryPage(q, 10, 2); err != nil || total != 4 || len(pageDocs) != 0 {
+		t.Errorf("page past the end: got %d documents of %d, %v", len(pageDocs), total, err)
+	}
+
+	if err := idx.ClosePointInTime(id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := idx.SearchQueryAt(id, 0, q); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected a closed point in time to be gone, got %v", err)
+	}
+	if err := idx.ClosePointInTime(id); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected closing twice to fail, got %v", err)
+	}
+	if _, err := idx.OpenPointInTime(0); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("expected a zero keep alive to be rejected, got %v", err)
+	}
+}
+
+func TestPointInTimeExpires(t *testing.T) {
+	testDir, err := os.MkdirTemp("", "hamfts_test_pit_expiry")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+
+	idx, err := NewIndex(testDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+	if err := idx.AddDocument(NewDocument("1", "text")); err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := idx.OpenPointInTime(100 * time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := idx.files
+	if err := idx.Compact(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Using it keeps it alive past its first deadline
+	for i := 0; i < 4; i++ {
+		time.Sleep(50 * time.Millisecond)
+		if _, err := idx.SearchQueryAt(id, 100*time.Millisecond, Query{MatchAll: &MatchAllQuery{}}); err != nil {
+			t.Fatalf("search %d: %v", i, err)
+		}
+	}
+	if files.closed.Load() {
+		t.Fatal("files of a live point in time were closed")
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !files.closed.Load() && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	if !files.closed.Load() {
+		t.Error("expected the files to be closed once the point in time expired")
+	}
+	if _, err := idx.SearchQueryAt(id, 0, Query{MatchAll: &MatchAllQuery{}}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected an expired point in time to be gone, got %v", err)
+	}
+}
@@ -0,0 +1,830 @@
+package hamfts
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// contentField is the field name queries use for Document.Content
+const contentField = "content"
+
+// Query is a structured search request. Exactly one clause must be set.
+type Query struct {
+	MatchAll *MatchAllQuery         `json:"match_all,omitempty"`
+	Match    map[string]string      `json:"match,omitempty"` // field -> text, every term must match
+	Term     map[string]interface{} `json:"term,omitempty"`  // field -> exact value
+	Range    map[string]RangeQuery  `json:"range,omitempty"`
+	Bool     *BoolQuery             `json:"bool,omitempty"`
+	Nested   *NestedQuery           `json:"nested,omitempty"`
+	// Prefix matches terms starting with the given value
+	Prefix map[string]string `json:"prefix,omitempty"`
+	// MatchBoolPrefix is a match query whose last term is a prefix, for
+	// search-as-you-type input
+	MatchBoolPrefix map[string]string `json:"match_bool_prefix,omitempty"`
+	// MoreLikeThis matches documents sharing the significant terms of a
+	// document or text
+	MoreLikeThis *MoreLikeThisQuery `json:"more_like_this,omitempty"`
+}
+
+type MatchAllQuery struct{}
+
+type RangeQuery struct {
+	GT  interface{} `json:"gt,omitempty"`
+	GTE interface{} `json:"gte,omitempty"`
+	LT  interface{} `json:"lt,omitempty"`
+	LTE interface{} `json:"lte,omitempty"`
+}
+
+// BoolQuery combines clauses. Should clauses only restrict the result when
+// there are no Must clauses.
+type BoolQuery struct {
+	Must    []Query `json:"must,omitempty"`
+	Should  []Query `json:"should,omitempty"`
+	MustNot []Query `json:"must_not,omitempty"`
+}
+
+// NestedQuery matches documents where a single element of the array at Path
+// satisfies Query on its own.
+type NestedQuery struct {
+	Path  string `json:"path"`
+	Query Query  `json:"query"`
+}
+
+// QueryError is returned when a query is malformed or does not fit the mapping
+type QueryError struct {
+	Reason string
+}
+
+func (e *QueryError) Error() string {
+	return "invalid query: " + e.Reason
+}
+
+func queryError(format string, args ...interface{}) *QueryError {
+	return &QueryError{Reason: fmt.Sprintf(format, args...)}
+}
+
+// docSet is a set of doc numbers
+type docSet map[int64]struct{}
+
+// SearchQuery runs a structured query and returns the matching documents in
+// the order they were created, then by ID.
+func (idx *Index) SearchQuery(q Query) ([]*Document, error) {
+	v := idx.acquire()
+	defer v.release()
+	return v.searchQuery(q)
+}
+
+func (v *view) searchQuery(q Query) ([]*Document, error) {
+	docs := []*Document{}
+	err := v.iterate(q, func(doc *Document) error {
+		docs = append(docs, doc)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return docs, nil
+}
+
+// Iterate calls fn with every document matching q, in the order they were
+// created, and stops at the first error fn returns. The documents come
+// from the index as it was when Iterate was called: writes made meanwhile
+// neither wait for it nor show up in it. Unlike SearchQuery it holds one
+// document at a time, so it suits exporting a whole index.
+func (idx *Index) Iterate(q Query, fn func(*Document) error) error {
+	v := idx.acquire()
+	defer v.release()
+	return v.iterate(q, fn)
+}
+
+// SearchQueryPage runs a structured query and returns size of the matching
+// documents, in the order they were created, starting from the from-th,
+// along with the number of matches. Only the documents of the page are read.
+// A size of zero returns all of them from from on.
+func (idx *Index) SearchQueryPage(q Query, from, size int) ([]*Document, int, error) {
+	v := idx.acquire()
+	defer v.release()
+	return v.searchPage(q, from, size)
+}
+
+func (v *view) searchPage(q Query, from, size int) ([]*Document, int, error) {
+	nums, err := v.matches(q)
+	if err != nil {
+		return nil, 0, err
+	}
+	total := len(nums)
+	nums = page(nums, from, size)
+
+	docs := make([]*Document, 0, len(nums))
+	for _, num := range nums {
+		doc, err := v.readDocument(num)
+		if err != nil {
+			return nil, 0, err
+		}
+		docs = append(docs, doc)
+	}
+	return docs, total, nil
+}
+
+// page returns size of items starting from the from-th; a size of zero
+// returns the rest
+func page[T any](items []T, from, size int) []T {
+	items = items[min(from, len(items)):]
+	if size > 0 && len(items) > size {
+		items = items[:size]
+	}
+	return items
+}
+
+// matches returns the doc numbers of the documents matching q, in the order
+// they were created; see hitKey
+func (v *view) matches(q Query) ([]int64, error) {
+	nums, err := v.evalQuery(q)
+	if err != nil {
+		return nil, err
+	}
+
+	sorted := make([]int64, 0, len(nums))
+	for num := range nums {
+		sorted = append(sorted, num)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return v.metadata.hitKey(sorted[i]).before(v.metadata.hitKey(sorted[j]))
+	})
+	return sorted, nil
+}
+
+func (v *view) iterate(q Query, fn func(*Document) error) error {
+	sorted, err := v.matches(q)
+	if err != nil {
+		return err
+	}
+
+	for _, num := range sorted {
+		doc, err := v.readDocument(num)
+		if err != nil {
+			return err
+		}
+		if err := fn(doc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (q Query) clause() (string, error) {
+	set := make([]string, 0, 1)
+	if q.MatchAll != nil {
+		set = append(set, "match_all")
+	}
+	if q.Match != nil {
+		set = append(set, "match")
+	}
+	if q.Term != nil {
+		set = append(set, "term")
+	}
+	if q.Range != nil {
+		set = append(set, "range")
+	}
+	if q.Bool != nil {
+		set = append(set, "bool")
+	}
+	if q.Nested != nil {
+		set = append(set, "nested")
+	}
+	if q.Prefix != nil {
+		set = append(set, "prefix")
+	}
+	if q.MatchBoolPrefix != nil {
+		set = append(set, "match_bool_prefix")
+	}
+	if q.MoreLikeThis != nil {
+		set = append(set, "more_like_this")
+	}
+
+	switch len(set) {
+	case 0:
+		return "", queryError("no clause set")
+	case 1:
+		return set[0], nil
+	}
+	return "", queryError("multiple clauses set (%s)", strings.Join(set, ", "))
+}
+
+// singleField returns the only entry of a field -> value clause
+func singleField[V any](clause string, fields map[string]V) (string, V, error) {
+	var zero V
+	if len(fields) != 1 {
+		return "", zero, queryError("%s expects exactly one field, got %d", clause, len(fields))
+	}
+	for field, value := range fields {
+		return field, value, nil
+	}
+	return "", zero, nil
+}
+
+// lookupField resolves a field path, including text sub-fields such as
+// "title.keyword".
+func (m Mapping) lookupField(path string) (*FieldMapping, bool) {
+	if fm, ok := m.Fields[path]; ok {
+		return fm, true
+	}
+	if i := strings.LastIndex(path, "."); i > 0 {
+		if parent, ok := m.Fields[path[:i]]; ok {
+			sub, ok := parent.Fields[path[i+1:]]
+			return sub, ok
+		}
+	}
+	return nil, false
+}
+
+func (v *view) evalQuery(q Query) (docSet, error) {
+	clause, err := q.clause()
+	if err != nil {
+		return nil, err
+	}
+
+	switch clause {
+	case "match_all":
+		return v.allDocs(), nil
+
+	case "match":
+		field, text, err := singleField(clause, q.Match)
+		if err != nil {
+			return nil, err
+		}
+		slots, err := v.matchSlots(field, text)
+		if err != nil {
+			return nil, err
+		}
+		return v.slotDocs(field, slots), nil
+
+	case "term":
+		field, value, err := singleField(clause, q.Term)
+		if err != nil {
+			return nil, err
+		}
+		t := FieldText
+		if field != contentField {
+			fm, ok := v.metadata.Mapping.lookupField(field)
+			if !ok {
+				return docSet{}, nil
+			}
+			t = fm.Type
+		}
+		term, err := normalizeTerm(t, value)
+		if err != nil {
+			return nil, queryError("field %q: %v", field, err)
+		}
+		return v.termDocs(field, term), nil
+
+	case "range":
+		field, r, err := singleField(clause, q.Range)
+		if err != nil {
+			return nil, err
+		}
+		fm, ok := v.metadata.Mapping.lookupField(field)
+		if !ok {
+			return docSet{}, nil
+		}
+		bounds, err := r.normalize(fm.Type)
+		if err != nil {
+			return nil, queryError("field %q: %v", field, err)
+		}
+		result := make(docSet)
+		for term, nums := range v.metadata.FieldEntries[field] {
+			if ok, err := bounds.contains(term); err != nil {
+				return nil, err
+			} else if ok {
+				for _, num := range nums {
+					result[num] = struct{}{}
+				}
+			}
+		}
+		return result, nil
+
+	case "bool":
+		return v.evalBool(q.Bool)
+
+	case "nested":
+		return v.evalNested(q.Nested)
+
+	case "prefix":
+		field, prefix, err := singleField(clause, q.Prefix)
+		if err != nil {
+			return nil, err
+		}
+		return v.prefixDocs(field, v.normalizePrefix(field, prefix)), nil
+
+	case "match_bool_prefix":
+		field, text, err := singleField(clause, q.MatchBoolPrefix)
+		if err != nil {
+			return nil, err
+		}
+		slots, err := v.matchSlots(field, text)
+		if err != nil {
+			return nil, err
+		}
+		if len(slots) == 0 {
+			return docSet{}, nil
+		}
+
+		// The last word may still be incomplete, so it only has to be a prefix
+		result := make(docSet)
+		for _, alt := range slots[len(slots)-1] {
+			nums := v.prefixDocs(field, alt[len(alt)-1])
+			for _, term := range alt[:len(alt)-1] {
+				nums = intersect(nums, v.termDocs(field, term))
+			}
+			for num := range nums {
+				result[num] = struct{}{}
+			}
+		}
+		if len(slots) > 1 {
+			result = intersect(result, v.slotDocs(field, slots[:len(slots)-1]))
+		}
+		return result, nil
+
+	case "more_like_this":
+		return v.evalMoreLikeThis(q.MoreLikeThis)
+	}
+	return nil, queryError("unsupported clause %s", clause)
+}
+
+func (v *view) evalBool(b *BoolQuery) (docSet, error) {
+	var result docSet
+	for _, sub := range b.Must {
+		nums, err := v.evalQuery(sub)
+		if err != nil {
+			return nil, err
+		}
+		if result == nil {
+			result = nums
+		} else {
+			result = intersect(result, nums)
+		}
+	}
+
+	if len(b.Must) == 0 && len(b.Should) > 0 {
+		result = make(docSet)
+		for _, sub := range b.Should {
+			nums, err := v.evalQuery(sub)
+			if err != nil {
+				return nil, err
+			}
+			for num := range nums {
+				result[num] = struct{}{}
+			}
+		}
+	}
+
+	if result == nil {
+		result = v.allDocs()
+	}
+
+	for _, sub := range b.MustNot {
+		nums, err := v.evalQuery(sub)
+		if err != nil {
+			return nil, err
+		}
+		for num := range nums {
+			delete(result, num)
+		}
+	}
+	return result, nil
+}
+
+func (v *view) evalNested(n *NestedQuery) (docSet, error) {
+	fm, ok := v.metadata.Mapping.Fields[n.Path]
+	if !ok || fm.Type != FieldNested {
+		return nil, queryError("field %q is not mapped as nested", n.Path)
+	}
+
+	// The index narrows candidates down, then each element is checked alone
+	candidates, err := v.evalQuery(candidateQuery(n.Query))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(docSet)
+	for num := range candidates {
+		doc, err := v.readDocument(num)
+		if err != nil {
+			return nil, err
+		}
+		for _, elem := range nestedElements(doc.Metadata, n.Path) {
+			values := make(map[string][]interface{})
+			flattenMetadata(n.Path, elem, values)
+			matched, err := v.matchValues(n.Query, values)
+			if err != nil {
+				return nil, err
+			}
+			if matched {
+				result[num] = struct{}{}
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+// candidateQuery returns q without its must_not clauses. Run against the
+// values of all the elements of a document at once, it matches every
+// document where one element matches q: the other clauses can only match
+// more pooled values, but an excluded value of one element would drop the
+// document even when another element matches.
+func candidateQuery(q Query) Query {
+	if q.Bool == nil {
+		return q
+	}
+	b := &BoolQuery{}
+	for _, sub := range q.Bool.Must {
+		b.Must = append(b.Must, candidateQuery(sub))
+	}
+	for _, sub := range q.Bool.Should {
+		b.Should = append(b.Should, candidateQuery(sub))
+	}
+	if len(b.Must) == 0 && len(b.Should) == 0 {
+		return Query{MatchAll: &MatchAllQuery{}}
+	}
+	return Query{Bool: b}
+}
+
+// matchValues evaluates q against the flattened values of a single object
+func (v *view) matchValues(q Query, values map[string][]interface{}) (bool, error) {
+	m := v.metadata.Mapping
+	clause, err := q.clause()
+	if err != nil {
+		return false, err
+	}
+
+	switch clause {
+	case "match_all":
+		return true, nil
+
+	case "match":
+		field, text, err := singleField(clause, q.Match)
+		if err != nil {
+			return false, err
+		}
+		fm, ok := m.lookupField(field)
+		if !ok {
+			return false, nil
+		}
+		have := make(map[string]struct{})
+		for _, value := range values[fieldSource(m, field)] {
+			for _, term := range valueTerms(fm, value, v.analysis) {
+				have[term] = struct{}{}
+			}
+		}
+		slots, err := v.matchSlots(field, text)
+		if err != nil {
+			return false, err
+		}
+		if len(slots) == 0 {
+			return false, nil
+		}
+		for _, alts := range slots {
+			if !containsAnyAlternative(have, alts) {
+				return false, nil
+			}
+		}
+		return true, nil
+
+	case "term":
+		field, value, err := singleField(clause, q.Term)
+		if err != nil {
+			return false, err
+		}
+		fm, ok := m.lookupField(field)
+		if !ok {
+			return false, nil
+		}
+		want, err := normalizeTerm(fm.Type, value)
+		if err != nil {
+			return false, queryError("field %q: %v", field, err)
+		}
+		for _, value := range values[fieldSource(m, field)] {
+			for _, term := range valueTerms(fm, value, v.analysis) {
+				if term == want {
+					return true, nil
+				}
+			}
+		}
+		return false, nil
+
+	case "range":
+		field, r, err := singleField(clause, q.Range)
+		if err != nil {
+			return false, err
+		}
+		fm, ok := m.lookupField(field)
+		if !ok {
+			return false, nil
+		}
+		bounds, err := r.normalize(fm.Type)
+		if err != nil {
+			return false, queryError("field %q: %v", field, err)
+		}
+		for _, value := range values[fieldSource(m, field)] {
+			for _, term := range valueTerms(fm, value, v.analysis) {
+				if ok, err := bounds.contains(term); err != nil || ok {
+					return ok, err
+				}
+			}
+		}
+		return false, nil
+
+	case "prefix":
+		field, prefix, err := singleField(clause, q.Prefix)
+		if err != nil {
+			return false, err
+		}
+		fm, ok := m.lookupField(field)
+		if !ok {
+			return false, nil
+		}
+		prefix = v.normalizePrefix(field, prefix)
+		for _, value := range values[fieldSource(m, field)] {
+			for _, term := range valueTerms(fm, value, v.analysis) {
+				if strings.HasPrefix(term, prefix) {
+					return true, nil
+				}
+			}
+		}
+		return false, nil
+
+	case "bool":
+		for _, sub := range q.Bool.Must {
+			if ok, err := v.matchValues(sub, values); err != nil || !ok {
+				return false, err
+			}
+		}
+		for _, sub := range q.Bool.MustNot {
+			if ok, err := v.matchValues(sub, values); err != nil || ok {
+				return false, err
+			}
+		}
+		if len(q.Bool.Must) > 0 || len(q.Bool.Should) == 0 {
+			return true, nil
+		}
+		for _, sub := range q.Bool.Should {
+			if ok, err := v.matchValues(sub, values); err != nil || ok {
+				return ok, err
+			}
+		}
+		return false, nil
+	}
+	return false, queryError("%s is not supported inside nested", clause)
+}
+
+// matchSlots analyzes the text of a match clause with the field's search
+// analyzer and groups the terms into slots of synonym alternatives
+func (v *view) matchSlots(field, text string) ([][][]string, error) {
+	if field == contentField {
+		return querySlots(v.analysis.forSearch(nil).AnalyzeTokens(text)), nil
+	}
+
+	fm, ok := v.metadata.Mapping.lookupField(field)
+	if ok && !fm.Type.analyzed() {
+		term, err := normalizeTerm(fm.Type, text)
+		if err != nil {
+			return nil, queryError("field %q: %v", field, err)
+		}
+		return [][][]string{{{term}}}, nil
+	}
+	return querySlots(v.analysis.forSearch(fm).AnalyzeTokens(text)), nil
+}
+
+// slotDocs returns the documents that match every slot with at least
+// one of its alternatives
+func (v *view) slotDocs(field string, slots [][][]string) docSet {
+	if len(slots) == 0 {
+		return docSet{}
+	}
+
+	var result docSet
+	for _, alts := range slots {
+		matched := make(docSet)
+		for _, alt := range alts {
+			nums := v.termDocs(field, alt[0])
+			for _, term := range alt[1:] {
+				nums = intersect(nums, v.termDocs(field, term))
+			}
+			for num := range nums {
+				matched[num] = struct{}{}
+			}
+		}
+		if result == nil {
+			result = matched
+		} else {
+			result = intersect(result, matched)
+		}
+	}
+	return result
+}
+
+func containsAnyAlternative(have map[string]struct{}, alts [][]string) bool {
+	for _, alt := range alts {
+		found := true
+		for _, term := range alt {
+			if _, ok := have[term]; !ok {
+				found = false
+				break
+			}
+		}
+		if found {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldSource maps a sub-field such as "title.keyword" back to the metadata
+// path its values come from.
+func fieldSource(m Mapping, field string) string {
+	if _, ok := m.Fields[field]; ok {
+		return field
+	}
+	if i := strings.LastIndex(field, "."); i > 0 {
+		return field[:i]
+	}
+	return field
+}
+
+// nestedElements returns the objects stored at a dotted path of meta
+func nestedElements(meta map[string]interface{}, path string) []map[string]interface{} {
+	var current []interface{}
+	current = append(current, meta)
+	for _, key := range strings.Split(path, ".") {
+		var next []interface{}
+		for _, node := range current {
+			obj, ok := node.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			switch v := obj[key].(type) {
+			case []interface{}:
+				next = append(next, v...)
+			case nil:
+			default:
+				next = append(next, v)
+			}
+		}
+		current = next
+	}
+
+	elems := make([]map[string]interface{}, 0, len(current))
+	for _, node := range current {
+		if obj, ok := node.(map[string]interface{}); ok {
+			elems = append(elems, obj)
+		}
+	}
+	return elems
+}
+
+// rangeBounds holds the normalized bounds of a range; hasLower and hasUpper
+// tell a missing bound from one that is the empty string
+type rangeBounds struct {
+	fieldType FieldType
+	lower     string
+	upper     string
+	hasLower  bool
+	hasUpper  bool
+	lowerIncl bool
+	upperIncl bool
+}
+
+func (r RangeQuery) normalize(t FieldType) (rangeBounds, error) {
+	b := rangeBounds{fieldType: t}
+	if r.GT != nil && r.GTE != nil || r.LT != nil && r.LTE != nil {
+		return b, fmt.Errorf("conflicting range bounds")
+	}
+
+	var err error
+	switch {
+	case r.GTE != nil:
+		b.lower, err = normalizeTerm(t, r.GTE)
+		b.hasLower, b.lowerIncl = true, true
+	case r.GT != nil:
+		b.lower, err = normalizeTerm(t, r.GT)
+		b.hasLower = true
+	}
+	if err != nil {
+		return b, err
+	}
+
+	switch {
+	case r.LTE != nil:
+		b.upper, err = normalizeTerm(t, r.LTE)
+		b.hasUpper, b.upperIncl = true, true
+	case r.LT != nil:
+		b.upper, err = normalizeTerm(t, r.LT)
+		b.hasUpper = true
+	}
+	if err != nil {
+		return b, err
+	}
+
+	if r.GT == nil && r.GTE == nil && r.LT == nil && r.LTE == nil {
+		return b, fmt.Errorf("range needs at least one bound")
+	}
+	return b, nil
+}
+
+func (b rangeBounds) contains(term string) (bool, error) {
+	if b.hasLower {
+		c, err := compareTerms(b.fieldType, term, b.lower)
+		if err != nil {
+			return false, err
+		}
+		if c < 0 || c == 0 && !b.lowerIncl {
+			return false, nil
+		}
+	}
+	if b.hasUpper {
+		c, err := compareTerms(b.fieldType, term, b.upper)
+		if err != nil {
+			return false, err
+		}
+		if c > 0 || c == 0 && !b.upperIncl {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// normalizePrefix lowercases the prefix for analyzed fields, whose terms are
+// stored lowercased
+func (v *view) normalizePrefix(field, prefix string) string {
+	if field == contentField {
+		return strings.ToLower(prefix)
+	}
+	if fm, ok := v.metadata.Mapping.lookupField(field); ok && fm.Type.analyzed() {
+		return strings.ToLower(prefix)
+	}
+	return prefix
+}
+
+// prefixDocs returns the documents with a term starting with prefix.
+// search_as_you_type fields look the prefix up in their prefix sub-field,
+// other fields scan their terms.
+func (v *view) prefixDocs(field, prefix string) docSet {
+	entries := v.metadata.IndexEntries
+	if field != contentField {
+		fm, ok := v.metadata.Mapping.lookupField(field)
+		if ok && fm.Type == FieldSearchAsYouType && utf8.RuneCountInString(prefix) <= maxPrefixChars {
+			return v.termDocs(field+indexPrefixSuffix, prefix)
+		}
+		entries = v.metadata.FieldEntries[field]
+	}
+
+	result := make(docSet)
+	for term, nums := range entries {
+		if strings.HasPrefix(term, prefix) {
+			for _, num := range nums {
+				result[num] = struct{}{}
+			}
+		}
+	}
+	return result
+}
+
+// termDocs looks up a term in the content index or a metadata field
+func (v *view) termDocs(field, term string) docSet {
+	var nums []int64
+	if field == contentField {
+		nums = v.metadata.IndexEntries[term]
+	} else {
+		nums = v.metadata.FieldEntries[field][term]
+	}
+
+	result := make(docSet, len(nums))
+	for _, num := range nums {
+		result[num] = struct{}{}
+	}
+	return result
+}
+
+func (v *view) allDocs() docSet {
+	result := make(docSet, len(v.metadata.DocumentNumbers))
+	for _, num := range v.metadata.DocumentNumbers {
+		result[num] = struct{}{}
+	}
+	return result
+}
+
+func intersect(a, b docSet) docSet {
+	if len(b) < len(a) {
+		a, b = b, a
+	}
+	result := make(docSet, len(a))
+	for num := range a {
+		if _, ok := b[num]; ok {
+			result[num] = struct{}{}
+		}
+	}
+	return result
+}
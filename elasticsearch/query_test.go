@@ -0,0 +1,175 @@
+package hamfts
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestMetadataQueries(t *testing.T) {
+	testDir, err := os.MkdirTemp("", "hamfts_test_query")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+
+	idx, err := NewIndex(testDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+
+	if err := idx.PutMapping(Mapping{Fields: map[string]*FieldMapping{
+		"comments": {Type: FieldNested},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Metadata as it arrives from the JSON API
+	raw := []string{
+		`{"author": {"name": "Alice"}, "tags": ["go", "search"], "comments": [{"author": "bob", "score": 2}, {"author": "carol", "score": 9}]}`,
+		`{"author": {"name": "Bob"}, "tags": ["rust"], "comments": [{"author": "bob", "score": 7}]}`,
+		`{"author": {"name": "Carol Smith"}, "tags": ["go"], "published": "2024-05-01T00:00:00Z"}`,
+	}
+	for i, meta := range raw {
+		doc := NewDocument(string(rune('1'+i)), "post number")
+		if err := json.Unmarshal([]byte(meta), &doc.Metadata); err != nil {
+			t.Fatal(err)
+		}
+		if err := idx.AddDocument(doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{"dotted path", `{"match": {"author.name": "carol"}}`, []string{"3"}},
+		{"keyword sub-field", `{"term": {"author.name.keyword": "Carol Smith"}}`, []string{"3"}},
+		{"multi-valued", `{"term": {"tags.keyword": "go"}}`, []string{"1", "3"}},
+		{"range", `{"range": {"comments.score": {"gt": 5}}}`, []string{"1", "2"}},
+		{"date range", `{"range": {"published": {"gte": "2024-01-01T00:00:00Z"}}}`, []string{"3"}},
+		{"keyword range", `{"range": {"tags.keyword": {"gt": "go", "lte": "search"}}}`, []string{"1", "2"}},
+		{"empty keyword bound", `{"range": {"tags.keyword": {"lt": ""}}}`, []string{}},
+		{"content", `{"match": {"content": "post"}}`, []string{"1", "2", "3"}},
+		{"must not", `{"bool": {"must_not": [{"term": {"tags.keyword": "go"}}]}}`, []string{"2"}},
+		{"cross-element bool", `{"bool": {"must": [
+			{"term": {"comments.author.keyword": "bob"}},
+			{"range": {"comments.score": {"gt": 5}}}
+		]}}`, []string{"1", "2"}},
+		{"nested", `{"nested": {"path": "comments", "query": {"bool": {"must": [
+			{"term": {"comments.author.keyword": "bob"}},
+			{"range": {"comments.score": {"gt": 5}}}
+		]}}}}`, []string{"2"}},
+		{"nested must not", `{"nested": {"path": "comments", "query": {"bool": {
+			"must": [{"term": {"comments.author.keyword": "bob"}}],
+			"must_not": [{"range": {"comments.score": {"gt": 5}}}]
+		}}}}`, []string{"1"}},
+	}
+
+	for _, tt := range tests {
+		var q Query
+		if err := json.Unmarshal([]byte(tt.query), &q); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		docs, err := idx.SearchQuery(q)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		got := make([]string, len(docs))
+		for i, doc := range docs {
+			got[i] = doc.ID
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+				break
+			}
+		}
+	}
+
+	if _, err := idx.SearchQuery(Query{}); err == nil {
+		t.Error("expected error for empty query")
+	}
+	if _, err := idx.SearchQuery(Query{Nested: &NestedQuery{Path: "tags", Query: Query{MatchAll: &MatchAllQuery{}}}}); err == nil {
+		t.Error("expected error for nested query on non-nested field")
+	}
+
+	// Field postings are removed with the document
+	if err := idx.DeleteDocument("2"); err != nil {
+		t.Fatal(err)
+	}
+	docs, err := idx.SearchQuery(Query{Term: map[string]interface{}{"tags.keyword": "rust"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 0 {
+		t.Errorf("Expected 0 results after deletion, got %d", len(docs))
+	}
+}
+
+func TestIterate(t *testing.T) {
+	testDir, err := os.MkdirTemp("", "hamfts_test_iterate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+
+	idx, err := NewIndex(testDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+	for i := 0; i < 10; i++ {
+		if err := idx.AddDocument(NewDocument(fmt.Sprintf("doc%d", i), fmt.Sprintf("item parity%d", i%2))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Writes made while iterating do not show up in the iteration
+	var ids []string
+	err = idx.Iterate(Query{Match: map[string]string{"content": "parity0"}}, func(doc *Document) error {
+		ids = append(ids, doc.ID)
+		if doc.ID == "doc0" {
+			if err := idx.DeleteDocument("doc4"); err != nil {
+				return err
+			}
+			return idx.AddDocument(NewDocument("doc10", "item parity0"))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"doc0", "doc2", "doc4", "doc6", "doc8"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("expected %v, got %v", want, ids)
+	}
+
+	// An error from the callback stops the iteration
+	stop := errors.New("stop")
+	seen := 0
+	err = idx.Iterate(Query{MatchAll: &MatchAllQuery{}}, func(doc *Document) error {
+		seen++
+		if seen == 3 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop || seen != 3 {
+		t.Errorf("expected to stop after 3 documents, got %d, %v", seen, err)
+	}
+
+	if err := idx.Iterate(Query{}, func(*Document) error { return nil }); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("expected an invalid query, got %v", err)
+	}
+}
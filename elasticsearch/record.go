@@ -0,0 +1,219 @@
+package hamfts
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+	"os"
+)
+
+// Record formats of docs.dat, stored in IndexMetadata.RecordFormat. Indexes
+// created with an older format keep it until Compact rewrites them in the
+// current one.
+const (
+	recordFormatGob         = 0 // a gob encoded Document
+	recordFormatChecksummed = 1 // header followed by a gob encoded Document
+	recordFormatBlocks      = 2 // compressed blocks and docs.tail; see blocks.go
+
+	currentRecordFormat = recordFormatBlocks
+)
+
+// recordHeaderSize is the size of the header of a checksummed record: the
+// payload length and its CRC-32C, both little endian uint32
+const recordHeaderSize = 8
+
+// maxRecordSize bounds the length read from a header, so a damaged header
+// cannot make a reader allocate gigabytes
+const maxRecordSize = 1 << 30
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// encodeRecord returns doc as a record in the given format
+func encodeRecord(doc *Document, format int) ([]byte, error) {
+	var buf bytes.Buffer
+	if format == recordFormatChecksummed {
+		buf.Write(make([]byte, recordHeaderSize))
+	}
+	if err := gob.NewEncoder(&buf).Encode(doc); err != nil {
+		return nil, err
+	}
+	record := buf.Bytes()
+	if format == recordFormatChecksummed {
+		payload := record[recordHeaderSize:]
+		binary.LittleEndian.PutUint32(record[0:4], uint32(len(payload)))
+		binary.LittleEndian.PutUint32(record[4:8], crc32.Checksum(payload, crcTable))
+	}
+	return record, nil
+}
+
+// readRecordAt decodes the record at pos of one of the unblocked formats.
+// ReadAt keeps the file offset untouched, so concurrent readers need no
+// coordination.
+func readRecordAt(r io.ReaderAt, pos int64, format int) (*Document, error) {
+	if format == recordFormatGob {
+		doc := &Document{}
+		if err := gob.NewDecoder(io.NewSectionReader(r, pos, math.MaxInt64-pos)).Decode(doc); err != nil {
+			return nil, fmt.Errorf("%w: document at offset %d: %v", ErrCorrupt, pos, err)
+		}
+		return doc, nil
+	}
+
+	payload, err := readPayload(r, pos)
+	if err != nil {
+		return nil, err
+	}
+	return decodeDocument(payload, pos)
+}
+
+// decodeDocument decodes a gob encoded Document found at pos
+func decodeDocument(data []byte, pos int64) (*Document, error) {
+	doc := &Document{}
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(doc); err != nil {
+		return nil, fmt.Errorf("%w: document at offset %d: %v", ErrCorrupt, pos, err)
+	}
+	return doc, nil
+}
+
+// readPayload reads a checksummed record and verifies it. From a reader
+// that can slice its bytes, such as a mapped docs.dat, the payload is not
+// copied.
+func readPayload(r io.ReaderAt, pos int64) ([]byte, error) {
+	if s, ok := r.(byteSlicer); ok {
+		if header, ok := s.slice(pos, recordHeaderSize); ok {
+			size := binary.LittleEndian.Uint32(header[0:4])
+			if size > maxRecordSize {
+				return nil, fmt.Errorf("%w: record at offset %d claims %d bytes", ErrCorrupt, pos, size)
+			}
+			if payload, ok := s.slice(pos+recordHeaderSize, int64(size)); ok {
+				return checkPayload(header, payload, pos)
+			}
+		}
+	}
+
+	header := make([]byte, recordHeaderSize)
+	if _, err := r.ReadAt(header, pos); err != nil {
+		return nil, fmt.Errorf("%w: record header at offset %d: %v", ErrCorrupt, pos, err)
+	}
+	size := binary.LittleEndian.Uint32(header[0:4])
+	if size > maxRecordSize {
+		return nil, fmt.Errorf("%w: record at offset %d claims %d bytes", ErrCorrupt, pos, size)
+	}
+	payload := make([]byte, size)
+	if _, err := r.ReadAt(payload, pos+recordHeaderSize); err != nil {
+		return nil, fmt.Errorf("%w: record at offset %d: %v", ErrCorrupt, pos, err)
+	}
+	return checkPayload(header, payload, pos)
+}
+
+func checkPayload(header, payload []byte, pos int64) ([]byte, error) {
+	if crc32.Checksum(payload, crcTable) != binary.LittleEndian.Uint32(header[4:8]) {
+		return nil, fmt.Errorf("%w: record at offset %d fails its checksum", ErrCorrupt, pos)
+	}
+	return payload, nil
+}
+
+// docStore reads documents by location. In the block format a location
+// either names a slot of a compressed block in docs.dat or, with
+// tailLocation set, a checksummed record in docs.tail; older formats only
+// use offsets into docs.dat.
+type docStore struct {
+	docs   io.ReaderAt
+	tail   io.ReaderAt
+	format int
+	blocks *blockCache
+}
+
+func (s docStore) read(loc int64) (*Document, error) {
+	if s.format != recordFormatBlocks {
+		return readRecordAt(s.docs, loc, s.format)
+	}
+	if loc&tailLocation != 0 {
+		return readRecordAt(s.tail, loc&^tailLocation, recordFormatChecksummed)
+	}
+	b, err := s.block(blockOffset(loc))
+	if err != nil {
+		return nil, err
+	}
+	return b.document(loc)
+}
+
+// block returns the decoded block at pos, from the cache when possible
+func (s docStore) block(pos int64) (*block, error) {
+	if b := s.blocks.get(pos); b != nil {
+		return b, nil
+	}
+	b, err := readBlock(s.docs, pos)
+	if err != nil {
+		return nil, err
+	}
+	s.blocks.put(pos, b)
+	return b, nil
+}
+
+// readDocumentAt reads a document of this index, for writers holding the
+// index lock
+func (idx *Index) readDocumentAt(loc int64) (*Document, error) {
+	return idx.live().store.read(loc)
+}
+
+// appendDocument writes doc at the end of the document file, or of
+// docs.tail in the block format, and returns its location
+func (idx *Index) appendDocument(doc *Document) (int64, error) {
+	file, format := idx.docFile, idx.metadata.RecordFormat
+	if format == recordFormatBlocks {
+		file, format = idx.tailFile, recordFormatChecksummed
+	}
+	record, err := encodeRecord(doc, format)
+	if err != nil {
+		return 0, err
+	}
+	pos, err := file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := file.Write(record); err != nil {
+		return 0, err
+	}
+	if file == idx.tailFile {
+		return tailLocation | pos, nil
+	}
+	return pos, nil
+}
+
+// markDead counts the record of a document as dead once it is deleted or
+// replaced. It is called before the location of num changes. A document in
+// a block is charged its share of the compressed block.
+func (idx *Index) markDead(num int64, doc *Document) {
+	loc, ok := idx.metadata.location(num)
+	if !ok {
+		return
+	}
+	format := idx.metadata.RecordFormat
+	if format == recordFormatBlocks {
+		if loc&tailLocation == 0 {
+			if b, err := idx.live().store.block(blockOffset(loc)); err == nil {
+				idx.metadata.DeadBytes += b.stored / int64(len(b.offsets))
+			}
+			return
+		}
+		format = recordFormatChecksummed
+	}
+	if record, err := encodeRecord(doc, format); err == nil {
+		idx.metadata.DeadBytes += int64(len(record))
+	}
+}
+
+// storedBytes is the size of docs.dat and docs.tail
+func (idx *Index) storedBytes() int64 {
+	var size int64
+	for _, f := range []*os.File{idx.docFile, idx.tailFile} {
+		if info, err := f.Stat(); err == nil {
+			size += info.Size()
+		}
+	}
+	return size
+}
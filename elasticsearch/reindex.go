@@ -0,0 +1,276 @@
+package hamfts
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"reflect"
+)
+
+// Reindex rebuilds the postings and suggestions from the documents stored in
+// docs.dat, analyzing them with the current analyzers and mapping. Searches
+// and writes go on while it runs; see reindex.
+func (idx *Index) Reindex() error {
+	if !idx.rewriting.CompareAndSwap(false, true) {
+		return fmt.Errorf("%w: a compaction or reindex is already running", ErrConflict)
+	}
+	defer idx.rewriting.Store(false)
+	return idx.reindex(context.Background(), nil, nil)
+}
+
+// StartReindex runs Reindex as a background task. Cancelling the task leaves
+// the index as it was.
+func (idx *Index) StartReindex() (*Task, error) {
+	return idx.startReindex(nil)
+}
+
+// ReindexWithAnalysis rebuilds the postings like Reindex, analyzing the
+// documents with new analysis settings, and switches the index to them once
+// done. This is how the analyzers used at index time change on an index
+// holding documents; until then searches keep using the old ones.
+func (idx *Index) ReindexWithAnalysis(settings AnalysisSettings) error {
+	a, err := idx.checkAnalysis(settings)
+	if err != nil {
+		return err
+	}
+	if !idx.rewriting.CompareAndSwap(false, true) {
+		return fmt.Errorf("%w: a compaction or reindex is already running", ErrConflict)
+	}
+	defer idx.rewriting.Store(false)
+	return idx.reindex(context.Background(), nil, &newAnalysisSettings{settings, a})
+}
+
+// StartReindexWithAnalysis runs ReindexWithAnalysis as a background task.
+// Cancelling the task leaves the index and its settings as they were.
+func (idx *Index) StartReindexWithAnalysis(settings AnalysisSettings) (*Task, error) {
+	a, err := idx.checkAnalysis(settings)
+	if err != nil {
+		return nil, err
+	}
+	return idx.startReindex(&newAnalysisSettings{settings, a})
+}
+
+func (idx *Index) startReindex(next *newAnalysisSettings) (*Task, error) {
+	if !idx.rewriting.CompareAndSwap(false, true) {
+		return nil, fmt.Errorf("%w: a compaction or reindex is already running", ErrConflict)
+	}
+	task, err := idx.tasks.start("reindex", func(t *Task) error {
+		defer idx.rewriting.Store(false)
+		return idx.reindex(t.ctx, t, next)
+	})
+	if err != nil {
+		idx.rewriting.Store(false)
+	}
+	return task, err
+}
+
+// newAnalysisSettings are the settings a reindex switches the index to
+type newAnalysisSettings struct {
+	settings AnalysisSettings
+	analysis *analysis
+}
+
+// checkAnalysis builds the analyzers of settings and checks that the
+// mapping only refers to analyzers they define
+func (idx *Index) checkAnalysis(settings AnalysisSettings) (*analysis, error) {
+	a, err := newAnalysis(settings, idx.analysisDir(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidQuery, err)
+	}
+	idx.mutex.RLock()
+	defer idx.mutex.RUnlock()
+	for path, fm := range idx.metadata.Mapping.Fields {
+		if err := validateFieldAnalyzers(path, fm, a); err != nil {
+			return nil, err
+		}
+	}
+	return a, nil
+}
+
+// rebuild is the fresh index built by reindex
+type rebuild struct {
+	entries      map[string][]int64
+	fieldEntries map[string]map[string][]int64
+	suggest      *completionIndex
+	analysis     *analysis       // the documents are analyzed with
+	analyzed     map[int64]int64 // doc number -> location it was analyzed at
+}
+
+// reindex analyzes the documents live at the start in batches under the
+// read lock, then takes the write lock to catch up with the documents
+// written in the meantime and swap the postings, and the analysis settings
+// when next is set. The record of a replaced or deleted document stays
+// readable until a compaction, which cannot run at the same time, so its
+// postings can be taken out again.
+func (idx *Index) reindex(ctx context.Context, t *Task, next *newAnalysisSettings) error {
+	idx.mutex.RLock()
+	nums := idx.metadata.liveDocNumbers()
+	settings := idx.metadata.Analysis
+	r := &rebuild{
+		entries:      make(map[string][]int64),
+		fieldEntries: make(map[string]map[string][]int64),
+		suggest:      &completionIndex{path: idx.suggest.path, fields: make(map[string]*completionField), dirty: true},
+		analysis:     idx.analysis,
+		analyzed:     make(map[int64]int64, len(nums)),
+	}
+	if next != nil {
+		r.analysis = next.analysis
+	}
+	idx.mutex.RUnlock()
+
+	if t != nil {
+		t.update(func(p *TaskProgress) { p.Total = len(nums) })
+	}
+	for start := 0; start < len(nums); start += byQueryBatchSize {
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err := idx.reindexBatch(t, r, nums[start:min(start+byQueryBatchSize, len(nums))]); err != nil {
+			return err
+		}
+	}
+
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+	if ctx.Err() != nil {
+		return nil
+	}
+	if next != nil {
+		// Settings or mappings changed meanwhile may not fit the new analyzers
+		if !reflect.DeepEqual(idx.metadata.Analysis, settings) {
+			return fmt.Errorf("%w: the analysis settings changed during the reindex", ErrConflict)
+		}
+		for path, fm := range idx.metadata.Mapping.Fields {
+			if err := validateFieldAnalyzers(path, fm, next.analysis); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Take out the documents replaced or deleted meanwhile, then analyze
+	// the current version of every document not analyzed yet
+	for num, analyzedAt := range r.analyzed {
+		if pos, ok := idx.metadata.location(num); ok && pos == analyzedAt {
+			continue
+		}
+		old, err := idx.readDocumentAt(analyzedAt)
+		if err != nil {
+			return fmt.Errorf("doc number %d: %w", num, err)
+		}
+		idx.removePostings(r.entries, r.fieldEntries, r.analysis, old, num)
+		r.suggest.removeDocument(old.ID)
+		delete(r.analyzed, num)
+	}
+	var pending []int64
+	for _, num := range idx.metadata.liveDocNumbers() {
+		if _, ok := r.analyzed[num]; !ok {
+			pending = append(pending, num)
+		}
+	}
+	if t != nil {
+		t.update(func(p *TaskProgress) { p.Total += len(pending) })
+	}
+	if err := idx.reindexDocuments(t, r, pending); err != nil {
+		return err
+	}
+
+	idx.metadata.IndexEntries = r.entries
+	idx.metadata.FieldEntries = r.fieldEntries
+	idx.suggest = r.suggest
+	if next != nil {
+		idx.metadata.Analysis = next.settings
+		idx.analysis = next.analysis
+	}
+	return idx.saveMetadata()
+}
+
+func (idx *Index) reindexBatch(t *Task, r *rebuild, nums []int64) error {
+	idx.mutex.RLock()
+	defer idx.mutex.RUnlock()
+	return idx.reindexDocuments(t, r, nums)
+}
+
+// reindexDocuments analyzes the documents still live among nums into r
+func (idx *Index) reindexDocuments(t *Task, r *rebuild, nums []int64) error {
+	indexed := 0
+	for _, num := range nums {
+		pos, ok := idx.metadata.location(num)
+		if !ok {
+			continue
+		}
+		doc, err := idx.readDocumentAt(pos)
+		if err != nil {
+			return fmt.Errorf("doc number %d: %w", num, err)
+		}
+		idx.addPostings(r.entries, r.fieldEntries, r.analysis, doc, num)
+		r.suggest.addDocument(doc, idx.metadata.Mapping)
+		r.analyzed[num] = pos
+		indexed++
+	}
+	if t != nil {
+		t.update(func(p *TaskProgress) {
+			p.Done += len(nums)
+			p.Indexed += indexed
+		})
+	}
+	return nil
+}
+
+// ReindexInto copies the documents matching q into dest in a background
+// task of this index. dest analyzes them with its own settings and mapping,
+// so this is how documents move to an index with different analyzers.
+// Documents already in dest under the same ID are replaced. The caller keeps
+// dest open until the task has finished.
+func (idx *Index) ReindexInto(dest *Index, q Query) (*Task, error) {
+	src, srcErr := filepath.Abs(idx.baseDir)
+	dst, dstErr := filepath.Abs(dest.baseDir)
+	if dest == idx || (srcErr == nil && dstErr == nil && src == dst) {
+		return nil, queryError("cannot reindex an index into itself")
+	}
+	ids, err := idx.matchingIDs(q)
+	if err != nil {
+		return nil, err
+	}
+
+	return idx.tasks.start("reindex", func(t *Task) error {
+		t.update(func(p *TaskProgress) { p.Total = len(ids) })
+		for start := 0; start < len(ids); start += byQueryBatchSize {
+			if t.ctx.Err() != nil {
+				return nil
+			}
+			batch := ids[start:min(start+byQueryBatchSize, len(ids))]
+			docs, err := idx.readDocuments(batch)
+			if err != nil {
+				return err
+			}
+			if err := dest.AddDocuments(docs); err != nil {
+				return err
+			}
+			t.update(func(p *TaskProgress) {
+				p.Done += len(batch)
+				p.Indexed += len(docs)
+			})
+		}
+		return nil
+	})
+}
+
+// readDocuments reads the documents still live among ids
+func (idx *Index) readDocuments(ids []string) ([]*Document, error) {
+	idx.mutex.RLock()
+	defer idx.mutex.RUnlock()
+
+	docs := make([]*Document, 0, len(ids))
+	for _, id := range ids {
+		num, ok := idx.metadata.DocumentNumbers[id]
+		if !ok {
+			continue
+		}
+		doc, err := idx.readDocument(num)
+		if err != nil {
+			return nil, fmt.Errorf("document %q: %w", id, err)
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
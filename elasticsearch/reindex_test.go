@@ -0,0 +1,148 @@
+package hamfts
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+)
+
+func TestReindex(t *testing.T) {
+	testDir, err := os.MkdirTemp("", "hamfts_test_reindex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+
+	idx, err := NewIndex(testDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { idx.Close() }()
+
+	for i := 0; i < 150; i++ {
+		doc := NewDocument(fmt.Sprintf("doc%d", i), "running dogs")
+		doc.Metadata["title"] = fmt.Sprintf("Jumping cats %d", i)
+		if err := idx.AddDocument(doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := idx.DeleteDocument("doc0"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The index-time analyzer only changes through a reindex
+	english := AnalysisSettings{Analyzer: "english"}
+	if err := idx.UpdateAnalysis(english); !errors.Is(err, ErrConflict) {
+		t.Fatalf("changing the analyzer of a non-empty index: got %v, want ErrConflict", err)
+	}
+	if _, err := idx.StartReindexWithAnalysis(AnalysisSettings{Analyzer: "missing"}); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("reindexing with an unknown analyzer: got %v, want ErrInvalidQuery", err)
+	}
+
+	task, err := idx.StartReindexWithAnalysis(english)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := idx.Compact(); !errors.Is(err, ErrConflict) && task.Info().Status == TaskRunning {
+		t.Errorf("compacting during a reindex: got %v, want ErrConflict", err)
+	}
+	if info := task.Wait(); info.Status != TaskCompleted || info.Progress.Indexed != 149 {
+		t.Errorf("reindex: got %+v", info)
+	}
+
+	if got := idx.GetAnalysis(); got.Analyzer != "english" {
+		t.Errorf("expected the english analyzer after reindexing, got %+v", got)
+	}
+	if report, err := idx.Verify(); err != nil || !report.OK() {
+		t.Errorf("after reindex: got %+v, %v", report, err)
+	}
+	if docs, _ := idx.Search("run", false); len(docs) != 149 {
+		t.Errorf("expected 149 documents for the stemmed term, got %d", len(docs))
+	}
+	docs, err := idx.SearchQuery(Query{Match: map[string]string{"title": "jumps"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 149 {
+		t.Errorf("expected 149 documents for the stemmed title, got %d", len(docs))
+	}
+
+	// A plain reindex keeps the settings
+	if err := idx.Reindex(); err != nil {
+		t.Fatal(err)
+	}
+	if report, err := idx.Verify(); err != nil || !report.OK() || idx.GetAnalysis().Analyzer != "english" {
+		t.Errorf("after a second reindex: got %+v, %v", report, err)
+	}
+
+	// The settings are saved with the postings
+	idx.Close()
+	idx, err = NewIndex(testDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := idx.GetAnalysis(); got.Analyzer != "english" {
+		t.Errorf("expected the english analyzer after reopening, got %+v", got)
+	}
+	if docs, _ := idx.Search("running", false); len(docs) != 149 {
+		t.Errorf("expected 149 documents after reopening, got %d", len(docs))
+	}
+}
+
+func TestReindexInto(t *testing.T) {
+	srcDir, err := os.MkdirTemp("", "hamfts_test_reindex_src")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(srcDir)
+	destDir, err := os.MkdirTemp("", "hamfts_test_reindex_dest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(destDir)
+
+	src, err := NewIndex(srcDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+	for i := 0; i < 10; i++ {
+		doc := NewDocument(fmt.Sprintf("doc%d", i), "the running dogs")
+		doc.Metadata["kind"] = "odd"
+		if i%2 == 0 {
+			doc.Metadata["kind"] = "even"
+		}
+		if err := src.AddDocument(doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dest, err := NewIndexWithOptions(destDir, IndexOptions{Analysis: &AnalysisSettings{Analyzer: "english"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dest.Close()
+
+	if _, err := src.ReindexInto(src, Query{}); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("reindexing into itself: got %v, want ErrInvalidQuery", err)
+	}
+
+	task, err := src.ReindexInto(dest, Query{Term: map[string]interface{}{"kind.keyword": "even"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info := task.Wait(); info.Status != TaskCompleted || info.Progress != (TaskProgress{Total: 5, Done: 5, Indexed: 5}) {
+		t.Errorf("reindex: got %+v", info)
+	}
+
+	if dest.DocumentCount() != 5 {
+		t.Errorf("expected 5 documents in dest, got %d", dest.DocumentCount())
+	}
+	if docs, _ := dest.Search("run", false); len(docs) != 5 {
+		t.Errorf("expected dest to stem, got %d documents", len(docs))
+	}
+	if docs, _ := src.Search("run", false); len(docs) != 0 {
+		t.Errorf("expected the source to be unchanged, got %d documents", len(docs))
+	}
+}
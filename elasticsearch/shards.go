@@ -0,0 +1,911 @@
+package hamfts
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A ShardedIndex splits its documents over several Index shards by a hash of
+// their ID. Writes go to the shard that owns the document; searches run on
+// every shard at once and their results are merged.
+// The number of shards is fixed when the index is created:
+//
+//	<baseDir>/shards.json   the number of shards
+//	<baseDir>/shard<N>/     an Index
+//	<baseDir>/tasks/        tasks that run on every shard
+//
+// Shards share one mapping, so a field cannot be a long in one shard and a
+// keyword in another. Analysis settings come from the IndexOptions every
+// shard is opened with. Compaction, the by-query tasks, Verify, Repair and
+// ReloadSearchAnalyzers run on every shard. Reindexing, snapshots and
+// changing analysis settings are only done on a single Index, through
+// Shards.
+//
+// Searches return documents ordered by creation time, then ID.
+type ShardedIndex struct {
+	shards []*Index
+	tasks  *taskManager
+
+	// mappingMu serializes mapping changes; mapping is the one every shard
+	// has been given
+	mappingMu sync.Mutex
+	mapping   Mapping
+}
+
+// shardSettings is persisted as shards.json
+type shardSettings struct {
+	Shards int `json:"shards"`
+}
+
+// NewShardedIndex opens the sharded index in baseDir, creating it with the
+// given number of shards. An existing index keeps the number it was created
+// with; asking for another one is an error, and zero accepts any.
+func NewShardedIndex(baseDir string, shards int, opts IndexOptions) (*ShardedIndex, error) {
+	if shards < 0 {
+		return nil, fmt.Errorf("invalid number of shards %d", shards)
+	}
+	if err := os.MkdirAll(baseDir, 0755); err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(baseDir, "shards.json")
+	var settings shardSettings
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		// Shards would end up next to the files of the unsharded index
+		if _, statErr := os.Stat(filepath.Join(baseDir, "documents")); statErr == nil {
+			return nil, fmt.Errorf("%w: %s holds an index that is not sharded", ErrConflict, baseDir)
+		}
+	}
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &settings); err != nil || settings.Shards <= 0 {
+			return nil, fmt.Errorf("%w: %s is invalid", ErrCorrupt, path)
+		}
+		if shards != 0 && shards != settings.Shards {
+			return nil, fmt.Errorf("%w: index has %d shards, not %d", ErrConflict, settings.Shards, shards)
+		}
+	case errors.Is(err, os.ErrNotExist):
+		settings.Shards = max(shards, 1)
+		data, err := json.Marshal(settings)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, err
+	}
+
+	s := &ShardedIndex{}
+	for i := 0; i < settings.Shards; i++ {
+		shard, err := NewIndexWithOptions(filepath.Join(baseDir, fmt.Sprintf("shard%d", i)), opts)
+		if err != nil {
+			s.Close()
+			return nil, err
+		}
+		s.shards = append(s.shards, shard)
+	}
+
+	// Shards created alongside each other have the same mapping; merging
+	// them covers one left behind by a failed write
+	s.mapping = s.shards[0].GetMapping()
+	for _, shard := range s.shards[1:] {
+		if err := s.mapping.merge(shard.GetMapping()); err != nil {
+			s.Close()
+			return nil, err
+		}
+	}
+	if err := s.putMapping(s.mapping); err != nil {
+		s.Close()
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Join(baseDir, "tasks"), 0755); err != nil {
+		s.Close()
+		return nil, err
+	}
+	if s.tasks, err = newTaskManager(filepath.Join(baseDir, "tasks")); err != nil {
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Shards returns the shards, in routing order
+func (s *ShardedIndex) Shards() []*Index {
+	return s.shards
+}
+
+// shardFor returns the shard that owns the document id
+func (s *ShardedIndex) shardFor(id string) int {
+	h := fnv.New32a()
+	h.Write([]byte(id))
+	return int(h.Sum32() % uint32(len(s.shards)))
+}
+
+// each runs fn on every shard in its own goroutine and returns the error of
+// the lowest failing shard
+func (s *ShardedIndex) each(fn func(i int, shard *Index) error) error {
+	errs := make([]error, len(s.shards))
+	var wg sync.WaitGroup
+	for i, shard := range s.shards {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = fn(i, shard)
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyMapping checks the metadata of docs against the shared mapping and
+// gives every shard the fields it adds, before any shard writes them
+func (s *ShardedIndex) applyMapping(docs []*Document) error {
+	s.mappingMu.Lock()
+	defer s.mappingMu.Unlock()
+
+	mapping := s.mapping.clone()
+	for _, doc := range docs {
+		if err := mapping.apply(doc.Metadata); err != nil {
+			if len(docs) == 1 {
+				return err
+			}
+			return fmt.Errorf("document %q: %w", doc.ID, err)
+		}
+	}
+	if len(mapping.Fields) == len(s.mapping.Fields) {
+		return nil
+	}
+	added := Mapping{Fields: make(map[string]*FieldMapping)}
+	for path, fm := range mapping.Fields {
+		if _, ok := s.mapping.Fields[path]; !ok {
+			added.Fields[path] = fm
+		}
+	}
+	if err := s.putMapping(added); err != nil {
+		return err
+	}
+	s.mapping = mapping
+	return nil
+}
+
+func (s *ShardedIndex) putMapping(m Mapping) error {
+	return s.each(func(_ int, shard *Index) error { return shard.PutMapping(m) })
+}
+
+func (s *ShardedIndex) AddDocument(doc *Document) error {
+	if err := s.applyMapping([]*Document{doc}); err != nil {
+		return err
+	}
+	return s.shards[s.shardFor(doc.ID)].AddDocument(doc)
+}
+
+// AddDocuments writes each shard's part of docs in parallel. The batch is
+// checked against the mapping as a whole first; a shard failing to write
+// does not undo the others.
+func (s *ShardedIndex) AddDocuments(docs []*Document) error {
+	if err := s.applyMapping(docs); err != nil {
+		return err
+	}
+	batches := make([][]*Document, len(s.shards))
+	for _, doc := range docs {
+		i := s.shardFor(doc.ID)
+		batches[i] = append(batches[i], doc)
+	}
+	return s.each(func(i int, shard *Index) error {
+		if len(batches[i]) == 0 {
+			return nil
+		}
+		return shard.AddDocuments(batches[i])
+	})
+}
+
+func (s *ShardedIndex) GetDocument(id string) (*Document, error) {
+	return s.shards[s.shardFor(id)].GetDocument(id)
+}
+
+// MultiGet fetches several documents at once. The result is aligned with
+// ids and holds nil for documents that do not exist.
+func (s *ShardedIndex) MultiGet(ids []string) ([]*Document, error) {
+	batches := make([][]string, len(s.shards))
+	slots := make([][]int, len(s.shards))
+	for i, id := range ids {
+		shard := s.shardFor(id)
+		batches[shard] = append(batches[shard], id)
+		slots[shard] = append(slots[shard], i)
+	}
+	docs := make([]*Document, len(ids))
+	err := s.each(func(i int, shard *Index) error {
+		if len(batches[i]) == 0 {
+			return nil
+		}
+		found, err := shard.MultiGet(batches[i])
+		for j, doc := range found {
+			docs[slots[i][j]] = doc
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return docs, nil
+}
+
+func (s *ShardedIndex) DeleteDocument(id string) error {
+	return s.shards[s.shardFor(id)].DeleteDocument(id)
+}
+
+// createdBefore orders documents by creation time, then ID, like the hits
+// of a single index
+func createdBefore(a, b *Document) bool {
+	return documentKey(a).before(documentKey(b))
+}
+
+// gather runs search on every shard and merges the results by creation
+// time. A shard returns its documents in the order of the search, so each
+// is sorted first.
+func (s *ShardedIndex) gather(search func(i int, shard *Index) ([]*Document, error)) ([]*Document, error) {
+	results := make([][]*Document, len(s.shards))
+	err := s.each(func(i int, shard *Index) error {
+		docs, err := search(i, shard)
+		sort.Slice(docs, func(a, b int) bool { return createdBefore(docs[a], docs[b]) })
+		results[i] = docs
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	total := 0
+	for _, docs := range results {
+		total += len(docs)
+	}
+	merged := make([]*Document, 0, total)
+	heads := make([]int, len(results))
+	for len(merged) < total {
+		next := -1
+		for i, docs := range results {
+			if heads[i] == len(docs) {
+				continue
+			}
+			if next < 0 || createdBefore(docs[heads[i]], results[next][heads[next]]) {
+				next = i
+			}
+		}
+		merged = append(merged, results[next][heads[next]])
+		heads[next]++
+	}
+	return merged, nil
+}
+
+func (s *ShardedIndex) Search(query string, containsMode bool) ([]*Document, error) {
+	return s.gather(func(_ int, shard *Index) ([]*Document, error) { return shard.Search(query, containsMode) })
+}
+
+func (s *ShardedIndex) PatternSearch(pattern string) ([]*Document, error) {
+	return s.gather(func(_ int, shard *Index) ([]*Document, error) { return shard.PatternSearch(pattern) })
+}
+
+// SearchQuery runs q on every shard and returns the matching documents in
+// the order they were created
+func (s *ShardedIndex) SearchQuery(q Query) ([]*Document, error) {
+	return s.gather(func(_ int, shard *Index) ([]*Document, error) { return shard.SearchQuery(q) })
+}
+
+// SearchQueryPage returns size of the documents matching q, in the order
+// they were created, starting from the from-th, along with the number of
+// matches. Like Index.SearchQueryPage it only reads the documents of the
+// page; see searchPage.
+func (s *ShardedIndex) SearchQueryPage(q Query, from, size int) ([]*Document, int, error) {
+	return s.searchPage(func(_ int, shard *Index) (*view, error) { return shard.acquire(), nil }, q, from, size)
+}
+
+// shardHits are the first matches of q on a shard, in the view they were
+// found in
+type shardHits struct {
+	v     *view
+	nums  []int64
+	total int
+}
+
+// searchPage runs q on the views acquire returns and reads the page of the
+// merged hits. Each shard orders its matches by hitKey without reading
+// them and keeps the first from+size; merging those picks the page, and
+// only its documents are read, from the views they matched in.
+func (s *ShardedIndex) searchPage(acquire func(i int, shard *Index) (*view, error), q Query, from, size int) ([]*Document, int, error) {
+	hits := make([]shardHits, len(s.shards))
+	defer func() {
+		for _, h := range hits {
+			if h.v != nil {
+				h.v.release()
+			}
+		}
+	}()
+	err := s.each(func(i int, shard *Index) error {
+		v, err := acquire(i, shard)
+		if err != nil {
+			return err
+		}
+		hits[i].v = v
+		nums, err := v.matches(q)
+		if err != nil {
+			return err
+		}
+		hits[i].total = len(nums)
+		if size > 0 {
+			nums = page(nums, 0, from+size)
+		}
+		hits[i].nums = nums
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total := 0
+	for _, h := range hits {
+		total += h.total
+	}
+	heads := make([]int, len(hits))
+	docs := make([]*Document, 0, size)
+	for n := 0; size == 0 || n < from+size; n++ {
+		next := -1
+		for i, h := range hits {
+			if heads[i] == len(h.nums) {
+				continue
+			}
+			if next < 0 || h.v.metadata.hitKey(h.nums[heads[i]]).before(hits[next].v.metadata.hitKey(hits[next].nums[heads[next]])) {
+				next = i
+			}
+		}
+		if next < 0 {
+			break
+		}
+		if n >= from {
+			h := hits[next]
+			doc, err := h.v.readDocument(h.nums[heads[next]])
+			if err != nil {
+				return nil, 0, err
+			}
+			docs = append(docs, doc)
+		}
+		heads[next]++
+	}
+	return docs, total, nil
+}
+
+// OpenPointInTime opens a point in time on every shard and returns an ID
+// made of theirs. The shards are frozen one after another, so a batch being
+// written to several shards at the time can be seen in some of them only.
+func (s *ShardedIndex) OpenPointInTime(keepAlive time.Duration) (string, error) {
+	ids := make([]string, 0, len(s.shards))
+	for _, shard := range s.shards {
+		id, err := shard.OpenPointInTime(keepAlive)
+		if err != nil {
+			s.closePointsInTime(ids)
+			return "", err
+		}
+		ids = append(ids, id)
+	}
+	return strings.Join(ids, "."), nil
+}
+
+// shardPointsInTime splits a point in time ID into those of the shards
+func (s *ShardedIndex) shardPointsInTime(id string) ([]string, error) {
+	ids := strings.Split(id, ".")
+	if len(ids) != len(s.shards) {
+		return nil, fmt.Errorf("point in time %q: %w", id, ErrNotFound)
+	}
+	return ids, nil
+}
+
+func (s *ShardedIndex) closePointsInTime(ids []string) error {
+	var first error
+	for i, id := range ids {
+		if err := s.shards[i].ClosePointInTime(id); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// ClosePointInTime releases the point in time on every shard
+func (s *ShardedIndex) ClosePointInTime(id string) error {
+	ids, err := s.shardPointsInTime(id)
+	if err != nil {
+		return err
+	}
+	if err := s.closePointsInTime(ids); err != nil {
+		return fmt.Errorf("point in time %q: %w", id, ErrNotFound)
+	}
+	return nil
+}
+
+// SearchQueryAt runs q against the point in time id on every shard and
+// returns the matching documents in the order they were created
+func (s *ShardedIndex) SearchQueryAt(id string, keepAlive time.Duration, q Query) ([]*Document, error) {
+	ids, err := s.shardPointsInTime(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.gather(func(i int, shard *Index) ([]*Document, error) {
+		return shard.SearchQueryAt(ids[i], keepAlive, q)
+	})
+}
+
+// SearchQueryAtPage is SearchQueryPage against the point in time id. It
+// only reads the documents of the page as well.
+func (s *ShardedIndex) SearchQueryAtPage(id string, keepAlive time.Duration, q Query, from, size int) ([]*Document, int, error) {
+	ids, err := s.shardPointsInTime(id)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.searchPage(func(i int, shard *Index) (*view, error) {
+		return shard.pits.use(ids[i], keepAlive)
+	}, q, from, size)
+}
+
+// MoreLikeThis returns the documents most similar to the source, best first.
+// Every shard picks the query terms by its own term statistics and returns
+// its best q.Size documents, from which the best q.Size are kept.
+func (s *ShardedIndex) MoreLikeThis(q MoreLikeThisQuery) ([]*Document, error) {
+	var source *Document
+	if q.ID != "" && q.Text == "" {
+		doc, err := s.GetDocument(q.ID)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return nil, fmt.Errorf("more_like_this document %q: %w", q.ID, ErrNotFound)
+			}
+			return nil, err
+		}
+		source = doc
+	}
+
+	results := make([][]scoredDocument, len(s.shards))
+	err := s.each(func(i int, shard *Index) error {
+		v := shard.acquire()
+		defer v.release()
+		var err error
+		results[i], err = v.moreLikeThis(q, source)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var hits []scoredDocument
+	for _, shardHits := range results {
+		hits = append(hits, shardHits...)
+	}
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].score > hits[j].score })
+	size := q.Size
+	if size <= 0 {
+		size = 10
+	}
+	if len(hits) > size {
+		hits = hits[:size]
+	}
+	docs := make([]*Document, 0, len(hits))
+	for _, hit := range hits {
+		docs = append(docs, hit.doc)
+	}
+	return docs, nil
+}
+
+// Suggest returns the highest weighted completions of a completion field
+// across all shards
+func (s *ShardedIndex) Suggest(req SuggestRequest) ([]Suggestion, error) {
+	results := make([][]Suggestion, len(s.shards))
+	err := s.each(func(i int, shard *Index) error {
+		var err error
+		results[i], err = shard.Suggest(req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var merged []Suggestion
+	for _, suggestions := range results {
+		merged = append(merged, suggestions...)
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		if merged[i].Weight != merged[j].Weight {
+			return merged[i].Weight > merged[j].Weight
+		}
+		return merged[i].Text < merged[j].Text
+	})
+	size := req.Size
+	if size <= 0 {
+		size = defaultSuggestSize
+	}
+	texts := make(map[string]bool)
+	suggestions := []Suggestion{}
+	for _, suggestion := range merged {
+		if len(suggestions) == size {
+			break
+		}
+		if req.SkipDuplicates {
+			if texts[suggestion.Text] {
+				continue
+			}
+			texts[suggestion.Text] = true
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+	return suggestions, nil
+}
+
+func (s *ShardedIndex) DocumentCount() int {
+	count := 0
+	for _, shard := range s.shards {
+		count += shard.DocumentCount()
+	}
+	return count
+}
+
+func (s *ShardedIndex) ListDocumentIDs() []string {
+	var ids []string
+	for _, shard := range s.shards {
+		ids = append(ids, shard.ListDocumentIDs()...)
+	}
+	return ids
+}
+
+// Iterate calls fn for each document matching q, shard by shard and in the
+// order each shard added them, without holding all of them in memory
+func (s *ShardedIndex) Iterate(q Query, fn func(*Document) error) error {
+	for _, shard := range s.shards {
+		if err := shard.Iterate(q, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DidYouMean returns term and phrase corrections for a query. Term options
+// add up their frequencies over the shards, and a term only counts as
+// missing when no shard has it; phrases are scored by the shard that
+// proposes them.
+func (s *ShardedIndex) DidYouMean(text string) DidYouMean {
+	opts := TermSuggestOptions{}.withDefaults()
+	always := opts
+	always.Mode = SuggestAlways
+
+	terms := make([][]TermSuggestion, len(s.shards))
+	phrases := make([][]PhraseSuggestion, len(s.shards))
+	freqs := make([][]int, len(s.shards))
+	s.each(func(i int, shard *Index) error {
+		v := shard.acquire()
+		defer v.release()
+		terms[i] = v.suggestTerms(text, always)
+		for _, term := range terms[i] {
+			freqs[i] = append(freqs[i], len(v.metadata.IndexEntries[term.Text]))
+		}
+		phrases[i] = v.suggestPhrases(text, 0)
+		return nil
+	})
+
+	// Every shard analyzes the text the same way, so the terms line up
+	merged := terms[0]
+	for j := range merged {
+		freq := 0
+		byText := make(map[string]int)
+		var options []TermOption
+		for i := range s.shards {
+			freq += freqs[i][j]
+			for _, option := range terms[i][j].Options {
+				if k, ok := byText[option.Text]; ok {
+					options[k].Freq += option.Freq
+					continue
+				}
+				byText[option.Text] = len(options)
+				options = append(options, option)
+			}
+		}
+		if freq > 0 {
+			options = nil
+		}
+		sort.Slice(options, func(a, b int) bool {
+			if options[a].Score != options[b].Score {
+				return options[a].Score > options[b].Score
+			}
+			if options[a].Freq != options[b].Freq {
+				return options[a].Freq > options[b].Freq
+			}
+			return options[a].Text < options[b].Text
+		})
+		if len(options) > opts.Size {
+			options = options[:opts.Size]
+		}
+		merged[j].Options = append([]TermOption{}, options...)
+	}
+
+	best := make(map[string]float64)
+	for _, shardPhrases := range phrases {
+		for _, phrase := range shardPhrases {
+			if score, ok := best[phrase.Text]; !ok || phrase.Score > score {
+				best[phrase.Text] = phrase.Score
+			}
+		}
+	}
+	suggestions := []PhraseSuggestion{}
+	for text, score := range best {
+		suggestions = append(suggestions, PhraseSuggestion{Text: text, Score: score})
+	}
+	sort.Slice(suggestions, func(a, b int) bool {
+		if suggestions[a].Score != suggestions[b].Score {
+			return suggestions[a].Score > suggestions[b].Score
+		}
+		return suggestions[a].Text < suggestions[b].Text
+	})
+	// As many as SuggestPhrases returns by default
+	if len(suggestions) > 3 {
+		suggestions = suggestions[:3]
+	}
+	return DidYouMean{Terms: merged, Phrases: suggestions}
+}
+
+// GetAnalysis returns the analysis settings the shards were opened with
+func (s *ShardedIndex) GetAnalysis() AnalysisSettings {
+	return s.shards[0].GetAnalysis()
+}
+
+// Analyze runs text through an analyzer of the shards
+func (s *ShardedIndex) Analyze(name, text string) ([]string, error) {
+	return s.shards[0].Analyze(name, text)
+}
+
+// GetMapping returns a copy of the mapping shared by the shards
+func (s *ShardedIndex) GetMapping() Mapping {
+	s.mappingMu.Lock()
+	defer s.mappingMu.Unlock()
+	return s.mapping.clone()
+}
+
+// PutMapping adds explicit field mappings to every shard and optionally
+// changes the dynamic mode. Every shard checks m before any is changed, so
+// an invalid mapping leaves them all as they were; a shard failing to save
+// it is caught up when the index is opened again.
+func (s *ShardedIndex) PutMapping(m Mapping) error {
+	s.mappingMu.Lock()
+	defer s.mappingMu.Unlock()
+
+	mapping := s.mapping.clone()
+	if err := mapping.merge(m); err != nil {
+		return err
+	}
+	for _, shard := range s.shards {
+		shard.mutex.RLock()
+		_, err := shard.mergeMapping(m)
+		shard.mutex.RUnlock()
+		if err != nil {
+			return err
+		}
+	}
+	if err := s.putMapping(m); err != nil {
+		return err
+	}
+	s.mapping = s.shards[0].GetMapping()
+	return nil
+}
+
+// Compact compacts the shards one after another
+func (s *ShardedIndex) Compact() error {
+	for _, shard := range s.shards {
+		if err := shard.Compact(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// shardTaskPoll is how often a task of the sharded index adds up the
+// progress of the shard tasks it follows
+const shardTaskPoll = 100 * time.Millisecond
+
+// startOnShards starts a task on every shard with start, and a task of the
+// sharded index that follows them: its progress adds up theirs, cancelling
+// it cancels them, and it fails when one of them does. If a shard cannot
+// start its task, the ones already started are cancelled.
+func (s *ShardedIndex) startOnShards(action string, start func(shard *Index) (*Task, error)) (*Task, error) {
+	children := make([]*Task, 0, len(s.shards))
+	cancelAll := func() {
+		for _, child := range children {
+			child.Cancel()
+		}
+	}
+	for i, shard := range s.shards {
+		child, err := start(shard)
+		if err != nil {
+			cancelAll()
+			return nil, fmt.Errorf("shard %d: %w", i, err)
+		}
+		children = append(children, child)
+	}
+
+	progress := func(p *TaskProgress) {
+		*p = TaskProgress{}
+		for _, child := range children {
+			p.add(child.Info().Progress)
+		}
+	}
+	task, err := s.tasks.start(action, func(t *Task) error {
+		done := make(chan struct{})
+		go func() {
+			for _, child := range children {
+				child.Wait()
+			}
+			close(done)
+		}()
+		ticker := time.NewTicker(shardTaskPoll)
+		defer ticker.Stop()
+
+		cancelled := t.ctx.Done()
+		for {
+			select {
+			case <-ticker.C:
+				t.update(progress)
+			case <-cancelled:
+				cancelAll()
+				cancelled = nil
+			case <-done:
+				t.update(progress)
+				for i, child := range children {
+					if info := child.Info(); info.Status == TaskFailed {
+						return fmt.Errorf("shard %d: %s", i, info.Error)
+					}
+				}
+				return nil
+			}
+		}
+	})
+	if err != nil {
+		cancelAll()
+	}
+	return task, err
+}
+
+// StartCompact compacts every shard at once, as a task
+func (s *ShardedIndex) StartCompact() (*Task, error) {
+	return s.startOnShards("compact", (*Index).StartCompact)
+}
+
+// DeleteByQuery deletes the documents matching q on every shard, as a task
+func (s *ShardedIndex) DeleteByQuery(q Query) (*Task, error) {
+	return s.startOnShards("delete_by_query", func(shard *Index) (*Task, error) {
+		return shard.DeleteByQuery(q)
+	})
+}
+
+// UpdateByQuery patches the documents matching q on every shard, as a task.
+// It fails when the patch fails on any shard; documents already patched
+// stay patched, as on a single Index.
+func (s *ShardedIndex) UpdateByQuery(q Query, patch Patch) (*Task, error) {
+	return s.startOnShards("update_by_query", func(shard *Index) (*Task, error) {
+		return shard.UpdateByQuery(q, patch)
+	})
+}
+
+// Task returns the state of a task of the sharded index
+func (s *ShardedIndex) Task(id string) (TaskInfo, error) {
+	return s.tasks.info(id)
+}
+
+// Tasks returns the tasks of the sharded index, newest first
+func (s *ShardedIndex) Tasks() ([]TaskInfo, error) {
+	return s.tasks.list()
+}
+
+// CancelTask cancels a task of the sharded index along with the shard tasks
+// it follows
+func (s *ShardedIndex) CancelTask(id string) error {
+	return s.tasks.cancel(id)
+}
+
+// Verify checks every shard. The report adds theirs up, and each problem
+// names the shard it was found in.
+func (s *ShardedIndex) Verify() (*VerifyReport, error) {
+	return s.checkShards((*Index).Verify)
+}
+
+// Repair repairs every shard; see Verify for the report
+func (s *ShardedIndex) Repair() (*VerifyReport, error) {
+	return s.checkShards((*Index).Repair)
+}
+
+func (s *ShardedIndex) checkShards(check func(shard *Index) (*VerifyReport, error)) (*VerifyReport, error) {
+	reports := make([]*VerifyReport, len(s.shards))
+	err := s.each(func(i int, shard *Index) error {
+		report, err := check(shard)
+		reports[i] = report
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	merged := &VerifyReport{}
+	for i, report := range reports {
+		merged.Documents += report.Documents
+		merged.Records += report.Records
+		merged.CorruptDocuments += report.CorruptDocuments
+		merged.MissingPostings += report.MissingPostings
+		merged.StalePostings += report.StalePostings
+		for _, problem := range report.Problems {
+			merged.problem("shard %d: %s", i, problem)
+		}
+		// Problems the shard counted but did not list
+		merged.problemsReported += report.problemsReported - len(report.Problems)
+		merged.Repaired = merged.Repaired || report.Repaired
+		merged.LostDocumentIDs = append(merged.LostDocumentIDs, report.LostDocumentIDs...)
+	}
+	sort.Strings(merged.LostDocumentIDs)
+	return merged, nil
+}
+
+// ReloadSearchAnalyzers reloads the updateable synonym filters of every
+// shard. Shards share their analysis settings, so the analyzers reloaded
+// are those of the first.
+func (s *ShardedIndex) ReloadSearchAnalyzers() ([]string, error) {
+	reloaded := make([][]string, len(s.shards))
+	err := s.each(func(i int, shard *Index) error {
+		names, err := shard.ReloadSearchAnalyzers()
+		reloaded[i] = names
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reloaded[0], nil
+}
+
+// GetStats adds up the statistics of the shards; uniqueWords counts a word
+// once per shard that has it
+func (s *ShardedIndex) GetStats() map[string]interface{} {
+	stats := map[string]interface{}{"shards": len(s.shards)}
+	for _, shard := range s.shards {
+		for key, value := range shard.GetStats() {
+			switch v := value.(type) {
+			case int:
+				total, _ := stats[key].(int)
+				stats[key] = total + v
+			case int64:
+				total, _ := stats[key].(int64)
+				stats[key] = total + v
+			}
+		}
+	}
+	return stats
+}
+
+func (s *ShardedIndex) Close() error {
+	// Tasks of the sharded index follow those of the shards, so stop them
+	// first
+	if s.tasks != nil {
+		s.tasks.stop()
+	}
+	var first error
+	for _, shard := range s.shards {
+		if err := shard.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
@@ -0,0 +1,592 @@
+package hamfts
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestShardedIndex(t *testing.T) {
+	testDir, err := os.MkdirTemp("", "hamfts_test_shards")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+
+	s, err := NewShardedIndex(testDir, 4, IndexOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	created := time.Now()
+	var docs []*Document
+	for i := 0; i < 200; i++ {
+		doc := NewDocument(fmt.Sprintf("doc%d", i), fmt.Sprintf("shared words number%d", i%10))
+		doc.CreatedAt = created.Add(time.Duration(i) * time.Second)
+		doc.Metadata["n"] = i
+		docs = append(docs, doc)
+	}
+	if err := s.AddDocuments(docs[:150]); err != nil {
+		t.Fatal(err)
+	}
+	for _, doc := range docs[150:] {
+		if err := s.AddDocument(doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := s.DocumentCount(); got != 200 {
+		t.Errorf("expected 200 documents, got %d", got)
+	}
+	for i, shard := range s.Shards() {
+		if n := shard.DocumentCount(); n == 0 || n == 200 {
+			t.Errorf("shard %d holds %d documents", i, n)
+		}
+		if fm, ok := shard.GetMapping().Fields["n"]; !ok || fm.Type != FieldLong {
+			t.Errorf("shard %d maps n as %+v", i, fm)
+		}
+	}
+	if stats := s.GetStats(); stats["documentCount"] != 200 || stats["shards"] != 4 {
+		t.Errorf("unexpected stats %v", stats)
+	}
+
+	// Results from all shards come back in creation order
+	results, err := s.SearchQuery(Query{Match: map[string]string{"content": "number3"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, doc := range results {
+		ids = append(ids, doc.ID)
+	}
+	var want []string
+	for i := 3; i < 200; i += 10 {
+		want = append(want, fmt.Sprintf("doc%d", i))
+	}
+	if !reflect.DeepEqual(ids, want) {
+		t.Errorf("expected %v, got %v", want, ids)
+	}
+	if results, err := s.Search("shared number7", false); err != nil || len(results) != 20 {
+		t.Errorf("search: got %d results, %v", len(results), err)
+	}
+
+	got, err := s.MultiGet([]string{"doc5", "missing", "doc199"})
+	if err != nil || got[0].ID != "doc5" || got[1] != nil || got[2].ID != "doc199" {
+		t.Errorf("multi get: got %v, %v", got, err)
+	}
+	if err := s.DeleteDocument("doc5"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.GetDocument("doc5"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected doc5 to be gone, got %v", err)
+	}
+
+	// The mapping is shared: a type set through one shard holds in all
+	bad := NewDocument("bad", "x")
+	bad.Metadata["n"] = "not a number"
+	var mappingErr *MappingError
+	if err := s.AddDocument(bad); !errors.As(err, &mappingErr) {
+		t.Errorf("expected a mapping error, got %v", err)
+	}
+
+	// Shards are fixed at creation
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewShardedIndex(testDir, 2, IndexOptions{}); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected a conflict reopening with 2 shards, got %v", err)
+	}
+	s, err = NewShardedIndex(testDir, 0, IndexOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if len(s.Shards()) != 4 || s.DocumentCount() != 199 {
+		t.Errorf("reopened with %d shards and %d documents", len(s.Shards()), s.DocumentCount())
+	}
+	all := s.ListDocumentIDs()
+	sort.Strings(all)
+	if len(all) != 199 || all[0] != "doc0" {
+		t.Errorf("unexpected ids %v", all[:3])
+	}
+
+	// An unsharded index cannot be opened as a sharded one
+	plainDir, err := os.MkdirTemp("", "hamfts_test_unsharded")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(plainDir)
+	plain, err := NewIndex(plainDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain.Close()
+	if _, err := NewShardedIndex(plainDir, 2, IndexOptions{}); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected a conflict sharding an unsharded index, got %v", err)
+	}
+}
+
+func TestShardedMoreLikeThisAndSuggest(t *testing.T) {
+	testDir, err := os.MkdirTemp("", "hamfts_test_shards_mlt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+
+	s, err := NewShardedIndex(testDir, 3, IndexOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if err := s.PutMapping(Mapping{Fields: map[string]*FieldMapping{"suggest": {Type: FieldCompletion}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	docs := []struct{ id, content, suggest string }{
+		{"go1", "go channels and goroutines make concurrency simple", "golang"},
+		{"go2", "goroutines and channels in go", "gopher"},
+		{"go3", "error handling in go", "gofmt"},
+		{"py1", "python generators and asyncio", "python"},
+	}
+	for i, d := range docs {
+		doc := NewDocument(d.id, d.content)
+		doc.Metadata["suggest"] = map[string]interface{}{"input": d.suggest, "weight": float64(10 * (i + 1))}
+		if err := s.AddDocument(doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	results, err := s.MoreLikeThis(MoreLikeThisQuery{ID: "go1", Size: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].ID != "go2" {
+		t.Errorf("expected go2 first of 2, got %v", results)
+	}
+	for _, doc := range results {
+		if doc.ID == "go1" {
+			t.Error("the source document matched itself")
+		}
+	}
+	if _, err := s.MoreLikeThis(MoreLikeThisQuery{ID: "missing"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected not found, got %v", err)
+	}
+
+	suggestions, err := s.Suggest(SuggestRequest{Field: "suggest", Prefix: "go", Size: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var texts []string
+	for _, suggestion := range suggestions {
+		texts = append(texts, suggestion.Text)
+	}
+	if !reflect.DeepEqual(texts, []string{"gofmt", "gopher"}) {
+		t.Errorf("expected the two heaviest completions, got %v", texts)
+	}
+}
+
+func TestShardedSearchOrderAndPointInTime(t *testing.T) {
+	testDir, err := os.MkdirTemp("", "hamfts_test_shards_pit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+
+	s, err := NewShardedIndex(testDir, 3, IndexOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	// Documents created at the same time come back by ID, whatever order a
+	// shard finds them in
+	created := time.Now()
+	var docs []*Document
+	var want []string
+	for i := 0; i < 30; i++ {
+		doc := NewDocument(fmt.Sprintf("doc%02d", 29-i), "searching the shards")
+		doc.CreatedAt = created
+		docs = append(docs, doc)
+		want = append(want, fmt.Sprintf("doc%02d", i))
+	}
+	if err := s.AddDocuments(docs); err != nil {
+		t.Fatal(err)
+	}
+	for _, search := range []func() ([]*Document, error){
+		func() ([]*Document, error) { return s.Search("searching", false) },
+		func() ([]*Document, error) { return s.PatternSearch("hard") },
+		func() ([]*Document, error) {
+			return s.SearchQuery(Query{Match: map[string]string{"content": "shards"}})
+		},
+	} {
+		results, err := search()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []string
+		for _, doc := range results {
+			ids = append(ids, doc.ID)
+		}
+		if !reflect.DeepEqual(ids, want) {
+			t.Errorf("expected %v, got %v", want, ids)
+		}
+	}
+
+	count := 0
+	if err := s.Iterate(Query{MatchAll: &MatchAllQuery{}}, func(*Document) error { count++; return nil }); err != nil || count != 30 {
+		t.Errorf("iterate: visited %d documents, %v", count, err)
+	}
+
+	pit, err := s.OpenPointInTime(time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddDocument(NewDocument("later", "searching again")); err != nil {
+		t.Fatal(err)
+	}
+	if results, err := s.SearchQueryAt(pit, 0, Query{Match: map[string]string{"content": "searching"}}); err != nil || len(results) != 30 {
+		t.Errorf("point in time: got %d results, %v", len(results), err)
+	}
+	pageDocs, total, err := s.SearchQueryAtPage(pit, 0, Query{Match: map[string]string{"content": "searching"}}, 28, 5)
+	if err != nil || total != 30 || len(pageDocs) != 2 || pageDocs[0].ID != "doc28" {
+		t.Errorf("point in time page: got %v of %d, %v", pageDocs, total, err)
+	}
+	if err := s.ClosePointInTime(pit); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ClosePointInTime(pit); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected a closed point in time to be gone, got %v", err)
+	}
+	if _, err := s.SearchQueryAt("nonsense", 0, Query{MatchAll: &MatchAllQuery{}}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected an unknown point in time to be not found, got %v", err)
+	}
+}
+
+func TestShardedPutMappingAllOrNothing(t *testing.T) {
+	testDir, err := os.MkdirTemp("", "hamfts_test_shards_mapping")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+
+	s, err := NewShardedIndex(testDir, 3, IndexOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	// A shard that was given a field directly rejects another type for it
+	if err := s.Shards()[1].PutMapping(Mapping{Fields: map[string]*FieldMapping{"x": {Type: FieldKeyword}}}); err != nil {
+		t.Fatal(err)
+	}
+	err = s.PutMapping(Mapping{Fields: map[string]*FieldMapping{
+		"x": {Type: FieldLong},
+		"y": {Type: FieldLong},
+	}})
+	var mappingErr *MappingError
+	if !errors.As(err, &mappingErr) {
+		t.Fatalf("expected a mapping error, got %v", err)
+	}
+	for i, shard := range s.Shards() {
+		if _, ok := shard.GetMapping().Fields["y"]; ok {
+			t.Errorf("shard %d took part of a rejected mapping", i)
+		}
+	}
+	if _, ok := s.GetMapping().Fields["y"]; ok {
+		t.Error("the shared mapping took part of a rejected mapping")
+	}
+}
+
+func TestShardedDidYouMean(t *testing.T) {
+	testDir, err := os.MkdirTemp("", "hamfts_test_shards_spell")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+
+	s, err := NewShardedIndex(testDir, 3, IndexOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	for i := 0; i < 12; i++ {
+		if err := s.AddDocument(NewDocument(fmt.Sprintf("doc%d", i), "elephant")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.AddDocument(NewDocument("rare", "elephent")); err != nil {
+		t.Fatal(err)
+	}
+
+	// elephent is in one shard only, so no shard may correct it
+	got := s.DidYouMean("elephent")
+	if len(got.Terms) != 1 || len(got.Terms[0].Options) != 0 {
+		t.Errorf("expected no corrections for an indexed term, got %+v", got.Terms)
+	}
+
+	got = s.DidYouMean("elefant")
+	if len(got.Terms) != 1 || len(got.Terms[0].Options) == 0 {
+		t.Fatalf("expected corrections, got %+v", got.Terms)
+	}
+	if option := got.Terms[0].Options[0]; option.Text != "elephant" || option.Freq != 12 {
+		t.Errorf("expected elephant in all 12 documents first, got %+v", option)
+	}
+	if len(got.Phrases) == 0 || got.Phrases[0].Text != "elephant" {
+		t.Errorf("expected the phrase elephant, got %+v", got.Phrases)
+	}
+}
+
+func TestShardedPagingMatchesIndex(t *testing.T) {
+	testDir, err := os.MkdirTemp("", "hamfts_test_shards_paging")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+
+	idx, err := NewIndex(filepath.Join(testDir, "single"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+	s, err := NewShardedIndex(filepath.Join(testDir, "sharded"), 3, IndexOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	// Creation times out of insertion order, with ties, and a document
+	// replaced by a newer version
+	base := time.Now()
+	var docs []*Document
+	for i := 0; i < 40; i++ {
+		doc := NewDocument(fmt.Sprintf("doc%02d", i), "paging through shards")
+		doc.CreatedAt = base.Add(time.Duration((i*7)%10) * time.Second)
+		docs = append(docs, doc)
+	}
+	replaced := NewDocument("doc03", "paging through shards again")
+	replaced.CreatedAt = base.Add(time.Minute)
+	for _, add := range []func(docs []*Document) error{idx.AddDocuments, s.AddDocuments} {
+		if err := add(docs); err != nil {
+			t.Fatal(err)
+		}
+		if err := add([]*Document{replaced}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	q := Query{Match: map[string]string{"content": "paging"}}
+	all, err := idx.SearchQuery(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 40 || all[39].ID != "doc03" {
+		t.Fatalf("expected 40 hits ending with the replaced document, got %d", len(all))
+	}
+	for i := 1; i < len(all); i++ {
+		if !createdBefore(all[i-1], all[i]) {
+			t.Fatalf("hits %s and %s are out of order", all[i-1].ID, all[i].ID)
+		}
+	}
+
+	ids := func(docs []*Document) []string {
+		ids := []string{}
+		for _, doc := range docs {
+			ids = append(ids, doc.ID)
+		}
+		return ids
+	}
+	for _, size := range []int{0, 1, 7, 40} {
+		for from := 0; from <= 42; from += 3 {
+			want := ids(page(all, from, size))
+			single, total, err := idx.SearchQueryPage(q, from, size)
+			if err != nil || total != 40 || !reflect.DeepEqual(ids(single), want) {
+				t.Errorf("index page %d+%d: got %v of %d, %v, want %v", from, size, ids(single), total, err, want)
+			}
+			sharded, total, err := s.SearchQueryPage(q, from, size)
+			if err != nil || total != 40 || !reflect.DeepEqual(ids(sharded), want) {
+				t.Errorf("sharded page %d+%d: got %v of %d, %v, want %v", from, size, ids(sharded), total, err, want)
+			}
+		}
+	}
+
+	// Pages of a point in time come from the shards as they were, in the
+	// same order
+	pit, err := s.OpenPointInTime(time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.ClosePointInTime(pit)
+	early := NewDocument("early", "paging before everything")
+	early.CreatedAt = base.Add(-time.Minute)
+	if err := s.AddDocument(early); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteDocument("doc05"); err != nil {
+		t.Fatal(err)
+	}
+	for from := 0; from <= 40; from += 5 {
+		want := ids(page(all, from, 5))
+		got, total, err := s.SearchQueryAtPage(pit, 0, q, from, 5)
+		if err != nil || total != 40 || !reflect.DeepEqual(ids(got), want) {
+			t.Errorf("point in time page %d: got %v of %d, %v, want %v", from, ids(got), total, err, want)
+		}
+	}
+	if _, _, err := s.SearchQueryAtPage("nonsense", 0, q, 0, 5); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected an unknown point in time to be not found, got %v", err)
+	}
+}
+
+func TestShardedTasksAndChecks(t *testing.T) {
+	testDir, err := os.MkdirTemp("", "hamfts_test_shards_tasks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+
+	s, err := NewShardedIndex(testDir, 3, IndexOptions{AutoCompactRatio: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var docs []*Document
+	for i := 0; i < 30; i++ {
+		doc := NewDocument(fmt.Sprintf("doc%d", i), "task fodder")
+		doc.Metadata["status"] = "stale"
+		if i%3 == 0 {
+			doc.Metadata["status"] = "active"
+		}
+		docs = append(docs, doc)
+	}
+	if err := s.AddDocuments(docs); err != nil {
+		t.Fatal(err)
+	}
+
+	stale := Query{Term: map[string]interface{}{"status.keyword": "stale"}}
+	task, err := s.UpdateByQuery(stale, Patch{Set: map[string]interface{}{"reviewed": true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info := task.Wait(); info.Status != TaskCompleted || info.Progress.Total != 20 || info.Progress.Updated != 20 {
+		t.Errorf("update by query: got %+v", info)
+	}
+	task, err = s.DeleteByQuery(stale)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deleted := task.Wait()
+	if deleted.Status != TaskCompleted || deleted.Progress.Deleted != 20 || s.DocumentCount() != 10 {
+		t.Errorf("delete by query: got %+v with %d documents left", deleted, s.DocumentCount())
+	}
+	task, err = s.StartCompact()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info := task.Wait(); info.Status != TaskCompleted || info.Progress.Done != 10 {
+		t.Errorf("compact: got %+v", info)
+	}
+	if dead := s.GetStats()["deadBytes"]; dead != int64(0) {
+		t.Errorf("expected no dead bytes after compaction, got %v", dead)
+	}
+
+	report, err := s.Verify()
+	if err != nil || !report.OK() || report.Documents != 10 {
+		t.Errorf("verify: got %+v, %v", report, err)
+	}
+
+	// A problem on one shard shows up in the merged report, named after it
+	broken := s.Shards()[1]
+	broken.mutex.Lock()
+	broken.metadata.DocumentCount++
+	broken.mutex.Unlock()
+	report, err = s.Verify()
+	if err != nil || report.OK() || len(report.Problems) != 1 || !strings.HasPrefix(report.Problems[0], "shard 1: ") {
+		t.Errorf("verify a broken shard: got %+v, %v", report, err)
+	}
+	if report, err := s.Repair(); err != nil || !report.Repaired {
+		t.Errorf("repair: got %+v, %v", report, err)
+	}
+	if report, err := s.Verify(); err != nil || !report.OK() {
+		t.Errorf("verify after repair: got %+v, %v", report, err)
+	}
+
+	// Tasks of the sharded index outlive it
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	s, err = NewShardedIndex(testDir, 0, IndexOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if info, err := s.Task(deleted.ID); err != nil || info.Progress != deleted.Progress {
+		t.Errorf("persisted task: got %+v, %v", info, err)
+	}
+	if tasks, err := s.Tasks(); err != nil || len(tasks) != 3 || tasks[1].ID != deleted.ID {
+		t.Errorf("expected 3 tasks, got %+v, %v", tasks, err)
+	}
+	if err := s.CancelTask("abcd"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("cancel an unknown task: got %v", err)
+	}
+}
+
+func TestShardedTaskCancel(t *testing.T) {
+	testDir, err := os.MkdirTemp("", "hamfts_test_shards_cancel")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+
+	s, err := NewShardedIndex(testDir, 2, IndexOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	var docs []*Document
+	for i := 0; i < 10; i++ {
+		docs = append(docs, NewDocument(fmt.Sprintf("doc%d", i), "cancel me"))
+	}
+	if err := s.AddDocuments(docs); err != nil {
+		t.Fatal(err)
+	}
+
+	// Hold the shard tasks until cancelling the sharded task reached them
+	release := make(chan struct{})
+	byQueryMatched = func() { <-release }
+	defer func() { byQueryMatched = nil }()
+	task, err := s.DeleteByQuery(Query{MatchAll: &MatchAllQuery{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.CancelTask(task.Info().ID); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(10 * time.Second)
+	for _, shard := range s.Shards() {
+		shard.tasks.mu.Lock()
+		children := slices.Collect(maps.Values(shard.tasks.tasks))
+		shard.tasks.mu.Unlock()
+		for _, child := range children {
+			for child.ctx.Err() == nil {
+				if time.Now().After(deadline) {
+					t.Fatal("the shard tasks were not cancelled")
+				}
+				time.Sleep(time.Millisecond)
+			}
+		}
+	}
+	close(release)
+	if info := task.Wait(); info.Status != TaskCancelled {
+		t.Errorf("expected the task to be cancelled, got %+v", info)
+	}
+	if n := s.DocumentCount(); n != 10 {
+		t.Errorf("expected the cancelled shard tasks to leave every document, got %d", n)
+	}
+}
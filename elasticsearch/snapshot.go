@@ -0,0 +1,397 @@
+package hamfts
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// snapshotChunkSize is the unit of deduplication. docs.dat only grows
+// between compactions, so a new snapshot mostly shares its leading chunks
+// with the previous one and stores just the tail.
+const snapshotChunkSize = 1 << 20
+
+// A snapshot repository is a directory holding
+//
+//	blobs/<sha256>          file chunks, shared by every snapshot
+//	snapshots/<name>.json   a SnapshotInfo listing the chunks of each file
+//	lock                    locked while a snapshot is created, deleted or restored
+//
+// Creating a snapshot skips the chunks already in blobs/, so deleting one
+// at the same time could remove chunks the new manifest is about to list.
+// Snapshot, DeleteSnapshot and Restore therefore take the repository lock.
+
+// repoLocks serializes the operations on each repository within the
+// process; the lock file keeps other processes out
+var repoLocks sync.Map // absolute repository path -> *sync.Mutex
+
+// lockRepository waits for the other operations of this process on the
+// repository, and fails with ErrConflict when another process holds it
+func lockRepository(repoPath string) (unlock func(), err error) {
+	abs, err := filepath.Abs(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	m, _ := repoLocks.LoadOrStore(abs, &sync.Mutex{})
+	mu := m.(*sync.Mutex)
+	mu.Lock()
+
+	f, err := os.OpenFile(filepath.Join(repoPath, "lock"), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		mu.Unlock()
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("snapshot repository %s: %w", repoPath, ErrNotFound)
+		}
+		return nil, err
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		mu.Unlock()
+		return nil, fmt.Errorf("%w: snapshot repository %s is in use by another process", ErrConflict, repoPath)
+	}
+	return func() {
+		unlockFile(f)
+		f.Close()
+		mu.Unlock()
+	}, nil
+}
+
+// SnapshotInfo describes a snapshot in a repository
+type SnapshotInfo struct {
+	Name          string         `json:"name"`
+	StartTime     time.Time      `json:"start_time"`
+	EndTime       time.Time      `json:"end_time"`
+	DocumentCount int            `json:"document_count"`
+	Size          int64          `json:"size"`        // of all files
+	AddedBytes    int64          `json:"added_bytes"` // chunks not already in the repository
+	Files         []SnapshotFile `json:"files"`
+}
+
+// SnapshotFile is an index file as a list of chunks
+type SnapshotFile struct {
+	Path   string   `json:"path"` // relative to the index directory
+	Size   int64    `json:"size"`
+	Chunks []string `json:"chunks"`
+}
+
+// snapshotSource is an index file to copy into a snapshot
+type snapshotSource struct {
+	path string
+	r    io.Reader
+	size int64
+}
+
+// Snapshot stores a consistent copy of the index in the repository at
+// repoPath. The read lock is only held while the file sizes and the small
+// files are captured; docs.dat is copied afterwards from its own handle,
+// up to the size it had then, which records written later do not change.
+func (idx *Index) Snapshot(repoPath, name string) (*SnapshotInfo, error) {
+	if !validSnapshotName(name) {
+		return nil, queryError("invalid snapshot name %q", name)
+	}
+	if err := os.MkdirAll(filepath.Join(repoPath, "blobs"), 0755); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Join(repoPath, "snapshots"), 0755); err != nil {
+		return nil, err
+	}
+	unlock, err := lockRepository(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	manifest := snapshotManifestPath(repoPath, name)
+	if _, err := os.Stat(manifest); err == nil {
+		return nil, fmt.Errorf("%w: snapshot %q already exists", ErrConflict, name)
+	}
+
+	info := &SnapshotInfo{Name: name, StartTime: time.Now()}
+	sources, docFile, err := idx.snapshotSources(info)
+	if err != nil {
+		return nil, err
+	}
+	defer docFile.Close()
+
+	for _, src := range sources {
+		file, added, err := storeChunks(repoPath, src)
+		if err != nil {
+			return nil, err
+		}
+		info.Files = append(info.Files, file)
+		info.Size += file.Size
+		info.AddedBytes += added
+	}
+	info.EndTime = time.Now()
+
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	// The manifest goes last: a snapshot interrupted before it never exists
+	if err := os.WriteFile(manifest+".tmp", data, 0644); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(manifest+".tmp", manifest); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// snapshotSources captures the index files under the read lock. Every write
+// saves the metadata before releasing the lock, so the files on disk match.
+func (idx *Index) snapshotSources(info *SnapshotInfo) ([]snapshotSource, *os.File, error) {
+	idx.mutex.RLock()
+	defer idx.mutex.RUnlock()
+
+	info.DocumentCount = idx.metadata.DocumentCount
+
+	var sources []snapshotSource
+	small := []string{
+		"metadata.json",
+		filepath.Join("suggest", "completion.json"),
+		filepath.Join("indexes", "inverted.idx"),
+		// At most a block's worth of documents, emptied under the write lock
+		filepath.Join("documents", "docs.tail"),
+	}
+	err := filepath.WalkDir(idx.analysisDir(), func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(idx.baseDir, path)
+		small = append(small, rel)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, rel := range small {
+		data, err := os.ReadFile(filepath.Join(idx.baseDir, rel))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		sources = append(sources, snapshotSource{path: rel, r: bytes.NewReader(data), size: int64(len(data))})
+	}
+
+	docFile, err := os.Open(filepath.Join(idx.baseDir, "documents", "docs.dat"))
+	if err != nil {
+		return nil, nil, err
+	}
+	stat, err := docFile.Stat()
+	if err != nil {
+		docFile.Close()
+		return nil, nil, err
+	}
+	sources = append(sources, snapshotSource{
+		path: filepath.Join("documents", "docs.dat"),
+		r:    io.NewSectionReader(docFile, 0, stat.Size()),
+		size: stat.Size(),
+	})
+	return sources, docFile, nil
+}
+
+// storeChunks writes the chunks of src missing from the repository and
+// returns how many bytes were new
+func storeChunks(repoPath string, src snapshotSource) (SnapshotFile, int64, error) {
+	file := SnapshotFile{Path: filepath.ToSlash(src.path), Size: src.size, Chunks: []string{}}
+	var added int64
+	buf := make([]byte, snapshotChunkSize)
+	for {
+		n, err := io.ReadFull(src.r, buf)
+		if n > 0 {
+			sum := sha256.Sum256(buf[:n])
+			hash := hex.EncodeToString(sum[:])
+			blob := filepath.Join(repoPath, "blobs", hash)
+			if _, statErr := os.Stat(blob); os.IsNotExist(statErr) {
+				if err := os.WriteFile(blob+".tmp", buf[:n], 0644); err != nil {
+					return file, added, err
+				}
+				if err := os.Rename(blob+".tmp", blob); err != nil {
+					return file, added, err
+				}
+				added += int64(n)
+			}
+			file.Chunks = append(file.Chunks, hash)
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return file, added, nil
+		}
+		if err != nil {
+			return file, added, err
+		}
+	}
+}
+
+// GetSnapshot reads the description of a snapshot
+func GetSnapshot(repoPath, name string) (*SnapshotInfo, error) {
+	if !validSnapshotName(name) {
+		return nil, fmt.Errorf("snapshot %q: %w", name, ErrNotFound)
+	}
+	data, err := os.ReadFile(snapshotManifestPath(repoPath, name))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("snapshot %q: %w", name, ErrNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+	info := &SnapshotInfo{}
+	if err := json.Unmarshal(data, info); err != nil {
+		return nil, fmt.Errorf("%w: snapshot %q: %v", ErrCorrupt, name, err)
+	}
+	return info, nil
+}
+
+// ListSnapshots returns the snapshots of a repository, oldest first
+func ListSnapshots(repoPath string) ([]*SnapshotInfo, error) {
+	entries, err := os.ReadDir(filepath.Join(repoPath, "snapshots"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var infos []*SnapshotInfo
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok {
+			continue
+		}
+		info, err := GetSnapshot(repoPath, name)
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].StartTime.Before(infos[j].StartTime) })
+	return infos, nil
+}
+
+// DeleteSnapshot removes a snapshot and the chunks no other snapshot uses
+func DeleteSnapshot(repoPath, name string) error {
+	unlock, err := lockRepository(repoPath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if _, err := GetSnapshot(repoPath, name); err != nil {
+		return err
+	}
+	if err := os.Remove(snapshotManifestPath(repoPath, name)); err != nil {
+		return err
+	}
+
+	infos, err := ListSnapshots(repoPath)
+	if err != nil {
+		return err
+	}
+	used := make(map[string]bool)
+	for _, info := range infos {
+		for _, file := range info.Files {
+			for _, hash := range file.Chunks {
+				used[hash] = true
+			}
+		}
+	}
+	blobs, err := os.ReadDir(filepath.Join(repoPath, "blobs"))
+	if err != nil {
+		return err
+	}
+	for _, blob := range blobs {
+		if !used[blob.Name()] {
+			if err := os.Remove(filepath.Join(repoPath, "blobs", blob.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Restore writes the files of a snapshot to dir, which must not exist or be
+// empty. Open the restored index with NewIndex(dir).
+func Restore(repoPath, name, dir string) error {
+	unlock, err := lockRepository(repoPath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	info, err := GetSnapshot(repoPath, name)
+	if err != nil {
+		return err
+	}
+	if entries, err := os.ReadDir(dir); err == nil && len(entries) > 0 {
+		return fmt.Errorf("%w: restore target %s is not empty", ErrConflict, dir)
+	}
+
+	for _, file := range info.Files {
+		rel := filepath.FromSlash(file.Path)
+		if !filepath.IsLocal(rel) {
+			return fmt.Errorf("%w: snapshot %q has file %q outside the index", ErrCorrupt, name, file.Path)
+		}
+		if err := restoreFile(repoPath, file, filepath.Join(dir, rel)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func restoreFile(repoPath string, file SnapshotFile, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	var size int64
+	for _, hash := range file.Chunks {
+		data, err := os.ReadFile(filepath.Join(repoPath, "blobs", hash))
+		if err != nil {
+			return fmt.Errorf("%w: chunk %s of %s: %v", ErrCorrupt, hash, file.Path, err)
+		}
+		if sum := sha256.Sum256(data); hex.EncodeToString(sum[:]) != hash {
+			return fmt.Errorf("%w: chunk %s of %s does not match its checksum", ErrCorrupt, hash, file.Path)
+		}
+		if _, err := out.Write(data); err != nil {
+			return err
+		}
+		size += int64(len(data))
+	}
+	if size != file.Size {
+		return fmt.Errorf("%w: %s restored with %d bytes, expected %d", ErrCorrupt, file.Path, size, file.Size)
+	}
+	return out.Sync()
+}
+
+func snapshotManifestPath(repoPath, name string) string {
+	return filepath.Join(repoPath, "snapshots", name+".json")
+}
+
+// validSnapshotName keeps names usable as file names
+func validSnapshotName(name string) bool {
+	if name == "" || name == "." || name == ".." {
+		return false
+	}
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
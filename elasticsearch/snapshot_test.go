@@ -0,0 +1,148 @@
+package hamfts
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSnapshotRestore(t *testing.T) {
+	testDir, err := os.MkdirTemp("", "hamfts_test_snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+	indexDir := filepath.Join(testDir, "index")
+	repo := filepath.Join(testDir, "repo")
+
+	idx, err := NewIndex(indexDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+
+	for i := 0; i < 20; i++ {
+		doc := NewDocument(fmt.Sprintf("doc%d", i), fmt.Sprintf("snapshot text %d", i))
+		doc.Metadata["n"] = i
+		if err := idx.AddDocument(doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	first, err := idx.Snapshot(repo, "first")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.DocumentCount != 20 || first.AddedBytes != first.Size {
+		t.Errorf("first snapshot: got %+v", first)
+	}
+
+	// Nothing changed, so nothing new is stored
+	same, err := idx.Snapshot(repo, "same")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if same.AddedBytes != 0 {
+		t.Errorf("unchanged snapshot added %d bytes", same.AddedBytes)
+	}
+	if _, err := idx.Snapshot(repo, "same"); !errors.Is(err, ErrConflict) {
+		t.Errorf("duplicate name: got %v, want ErrConflict", err)
+	}
+	if _, err := idx.Snapshot(repo, "../escape"); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("bad name: got %v, want ErrInvalidQuery", err)
+	}
+
+	if err := idx.DeleteDocument("doc0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := idx.AddDocument(NewDocument("new", "snapshot later")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := idx.Snapshot(repo, "second"); err != nil {
+		t.Fatal(err)
+	}
+
+	infos, err := ListSnapshots(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 3 || infos[0].Name != "first" || infos[2].Name != "second" {
+		t.Errorf("unexpected snapshot list %v", infos)
+	}
+
+	// Deleting a snapshot keeps the chunks others still use
+	if err := DeleteSnapshot(repo, "first"); err != nil {
+		t.Fatal(err)
+	}
+	restored := filepath.Join(testDir, "restored")
+	if err := Restore(repo, "same", restored); err != nil {
+		t.Fatal(err)
+	}
+	if err := Restore(repo, "same", restored); !errors.Is(err, ErrConflict) {
+		t.Errorf("restore into a non-empty directory: got %v, want ErrConflict", err)
+	}
+
+	old, err := NewIndex(restored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer old.Close()
+	if old.DocumentCount() != 20 {
+		t.Errorf("restored %d documents, want 20", old.DocumentCount())
+	}
+	docs, err := old.SearchQuery(Query{Range: map[string]RangeQuery{"n": {GTE: 19}}})
+	if err != nil || len(docs) != 1 || docs[0].ID != "doc19" {
+		t.Errorf("restored search: got %v, %v", docs, err)
+	}
+	if _, err := old.GetDocument("new"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("document added after the snapshot: got %v", err)
+	}
+
+	if err := Restore(repo, "first", filepath.Join(testDir, "gone")); !errors.Is(err, ErrNotFound) {
+		t.Errorf("deleted snapshot: got %v, want ErrNotFound", err)
+	}
+}
+
+func TestSnapshotRepositoryLock(t *testing.T) {
+	testDir, err := os.MkdirTemp("", "hamfts_test_snapshot_lock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+	repo := filepath.Join(testDir, "repo")
+
+	idx, err := NewIndex(filepath.Join(testDir, "index"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+	if err := idx.AddDocument(NewDocument("1", "text")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := idx.Snapshot(repo, "first"); err != nil {
+		t.Fatal(err)
+	}
+
+	// A delete waits for the snapshot holding the repository
+	unlock, err := lockRepository(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error)
+	go func() { done <- DeleteSnapshot(repo, "first") }()
+	select {
+	case err := <-done:
+		t.Fatalf("delete ran while the repository was locked: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+	unlock()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := lockRepository(filepath.Join(testDir, "missing")); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected a missing repository to be not found, got %v", err)
+	}
+}
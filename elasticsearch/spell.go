@@ -0,0 +1,315 @@
+package hamfts
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// Suggest modes of the term suggester
+const (
+	SuggestMissing = "missing" // only terms that are not in the index
+	SuggestPopular = "popular" // also terms that have more frequent corrections
+	SuggestAlways  = "always"  // every term
+)
+
+// TermSuggestOptions tunes the term suggester. Zero values pick defaults.
+type TermSuggestOptions struct {
+	Size          int    `json:"size,omitempty"`            // options per term, default 5
+	Mode          string `json:"suggest_mode,omitempty"`    // default SuggestMissing
+	MaxEdits      int    `json:"max_edits,omitempty"`       // 1 or 2, default 2
+	PrefixLength  int    `json:"prefix_length,omitempty"`   // leading characters that must match, default 1
+	MinWordLength int    `json:"min_word_length,omitempty"` // shorter terms are not corrected, default 4
+}
+
+// TermSuggestion lists corrections for one term of the text. Offset and
+// Length locate the term in the original text.
+type TermSuggestion struct {
+	Text    string       `json:"text"`
+	Offset  int          `json:"offset"`
+	Length  int          `json:"length"`
+	Options []TermOption `json:"options"`
+}
+
+type TermOption struct {
+	Text  string  `json:"text"`
+	Score float64 `json:"score"`
+	Freq  int     `json:"freq"`
+}
+
+// PhraseSuggestion is a corrected version of the whole text
+type PhraseSuggestion struct {
+	Text  string  `json:"text"`
+	Score float64 `json:"score"`
+}
+
+// DidYouMean bundles term and phrase corrections for a query
+type DidYouMean struct {
+	Terms   []TermSuggestion   `json:"terms"`
+	Phrases []PhraseSuggestion `json:"phrases"`
+}
+
+func (o TermSuggestOptions) withDefaults() TermSuggestOptions {
+	if o.Size <= 0 {
+		o.Size = 5
+	}
+	if o.Mode == "" {
+		o.Mode = SuggestMissing
+	}
+	if o.MaxEdits <= 0 || o.MaxEdits > 2 {
+		o.MaxEdits = 2
+	}
+	if o.PrefixLength <= 0 {
+		o.PrefixLength = 1
+	}
+	if o.MinWordLength <= 0 {
+		o.MinWordLength = 4
+	}
+	return o
+}
+
+// SuggestTerms proposes corrections for the terms of text from the content
+// term dictionary, ranked by edit distance and then document frequency.
+func (idx *Index) SuggestTerms(text string, opts TermSuggestOptions) []TermSuggestion {
+	v := idx.acquire()
+	defer v.release()
+	return v.suggestTerms(text, opts.withDefaults())
+}
+
+func (v *view) suggestTerms(text string, opts TermSuggestOptions) []TermSuggestion {
+	suggestions := []TermSuggestion{}
+	for _, token := range v.analysis.forSearch(nil).AnalyzeTokens(text) {
+		// Terms added by synonym filters are not what the user typed
+		if token.Term == "" || token.Slot != 0 {
+			continue
+		}
+		suggestions = append(suggestions, TermSuggestion{
+			Text:    token.Term,
+			Offset:  token.Start,
+			Length:  token.End - token.Start,
+			Options: v.termOptions(token.Term, opts),
+		})
+	}
+	return suggestions
+}
+
+// termOptions scans the term dictionary for corrections of term
+func (v *view) termOptions(term string, opts TermSuggestOptions) []TermOption {
+	options := []TermOption{}
+	runes := []rune(term)
+	freq := len(v.metadata.IndexEntries[term])
+	if len(runes) < opts.MinWordLength || (opts.Mode == SuggestMissing && freq > 0) {
+		return options
+	}
+
+	prefix := string(runes[:min(opts.PrefixLength, len(runes))])
+	for candidate, nums := range v.metadata.IndexEntries {
+		if candidate == term || !strings.HasPrefix(candidate, prefix) {
+			continue
+		}
+		if opts.Mode == SuggestPopular && len(nums) <= freq {
+			continue
+		}
+		other := []rune(candidate)
+		if abs(len(other)-len(runes)) > opts.MaxEdits {
+			continue
+		}
+		edits := editDistance(runes, other)
+		if edits > opts.MaxEdits {
+			continue
+		}
+		options = append(options, TermOption{
+			Text:  candidate,
+			Score: 1 - float64(edits)/float64(max(len(runes), len(other))),
+			Freq:  len(nums),
+		})
+	}
+
+	sort.Slice(options, func(i, j int) bool {
+		if options[i].Score != options[j].Score {
+			return options[i].Score > options[j].Score
+		}
+		if options[i].Freq != options[j].Freq {
+			return options[i].Freq > options[j].Freq
+		}
+		return options[i].Text < options[j].Text
+	})
+	if len(options) > opts.Size {
+		options = options[:opts.Size]
+	}
+	return options
+}
+
+// editDistance counts the insertions, deletions, substitutions and
+// transpositions of adjacent characters that turn a into b
+func editDistance(a, b []rune) int {
+	prevPrev := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				cur[j] = min(cur[j], prevPrev[j-2]+1)
+			}
+		}
+		prevPrev, prev, cur = prev, cur, prevPrev
+	}
+	return prev[len(b)]
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// Phrase suggester scoring. Every edit costs a constant factor, and word
+// pairs are scored by how often they occur in the same document, backing
+// off to single word frequencies (stupid backoff).
+const (
+	phraseEditPenalty = 0.1
+	phraseBackoff     = 0.4
+	phraseUnseen      = 0.01 // stands in for the frequency of unknown words
+	phraseBeamWidth   = 10
+	phraseCandidates  = 4
+)
+
+type phraseCandidate struct {
+	terms []string
+	score float64 // log probability
+}
+
+// SuggestPhrases proposes corrected versions of the whole text. Every
+// suggestion differs from the text and matches at least one document.
+func (idx *Index) SuggestPhrases(text string, size int) []PhraseSuggestion {
+	v := idx.acquire()
+	defer v.release()
+	return v.suggestPhrases(text, size)
+}
+
+func (v *view) suggestPhrases(text string, size int) []PhraseSuggestion {
+	if size <= 0 {
+		size = 3
+	}
+	opts := TermSuggestOptions{Size: phraseCandidates, Mode: SuggestAlways}.withDefaults()
+	terms := v.suggestTerms(text, opts)
+	if len(terms) == 0 {
+		return []PhraseSuggestion{}
+	}
+
+	// Beam search over the candidates for each term
+	beam := []phraseCandidate{{}}
+	for _, term := range terms {
+		type choice struct {
+			text  string
+			edits int
+		}
+		choices := []choice{{text: term.Text}}
+		for _, option := range term.Options {
+			choices = append(choices, choice{text: option.Text, edits: editDistance([]rune(term.Text), []rune(option.Text))})
+		}
+
+		var next []phraseCandidate
+		for _, cand := range beam {
+			for _, c := range choices {
+				score := cand.score + v.phraseTermScore(cand.terms, c.text) + float64(c.edits)*math.Log(phraseEditPenalty)
+				next = append(next, phraseCandidate{terms: append(append([]string(nil), cand.terms...), c.text), score: score})
+			}
+		}
+		sort.SliceStable(next, func(i, j int) bool { return next[i].score > next[j].score })
+		if len(next) > phraseBeamWidth {
+			next = next[:phraseBeamWidth]
+		}
+		beam = next
+	}
+
+	suggestions := []PhraseSuggestion{}
+	for _, cand := range beam {
+		changed := false
+		for i, t := range cand.terms {
+			if t != terms[i].Text {
+				changed = true
+			}
+		}
+		if !changed || !v.phraseMatches(cand.terms) {
+			continue
+		}
+		suggestions = append(suggestions, PhraseSuggestion{
+			Text:  correctedText(text, terms, cand.terms),
+			Score: math.Exp(cand.score),
+		})
+		if len(suggestions) == size {
+			break
+		}
+	}
+	return suggestions
+}
+
+// phraseTermScore is the log probability of term following prev
+func (v *view) phraseTermScore(prev []string, term string) float64 {
+	docs := float64(v.metadata.DocumentCount + 1)
+	freq := float64(len(v.metadata.IndexEntries[term]))
+	if freq == 0 {
+		freq = phraseUnseen
+	}
+	unigram := freq / docs
+	if len(prev) == 0 {
+		return math.Log(unigram)
+	}
+
+	last := prev[len(prev)-1]
+	together := len(intersect(v.termDocs(contentField, last), v.termDocs(contentField, term)))
+	if together > 0 {
+		return math.Log(float64(together) / float64(len(v.metadata.IndexEntries[last])))
+	}
+	return math.Log(phraseBackoff * unigram)
+}
+
+// phraseMatches reports whether a document contains all terms
+func (v *view) phraseMatches(terms []string) bool {
+	result := v.termDocs(contentField, terms[0])
+	for _, term := range terms[1:] {
+		result = intersect(result, v.termDocs(contentField, term))
+	}
+	return len(result) > 0
+}
+
+// correctedText replaces the original terms in text with their corrections
+func correctedText(text string, terms []TermSuggestion, corrected []string) string {
+	var b strings.Builder
+	last := 0
+	for i, term := range terms {
+		if term.Offset < last || term.Offset+term.Length > len(text) {
+			continue
+		}
+		b.WriteString(text[last:term.Offset])
+		if corrected[i] == term.Text {
+			b.WriteString(text[term.Offset : term.Offset+term.Length])
+		} else {
+			b.WriteString(corrected[i])
+		}
+		last = term.Offset + term.Length
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// DidYouMean returns term and phrase corrections for a query
+func (idx *Index) DidYouMean(text string) DidYouMean {
+	v := idx.acquire()
+	defer v.release()
+	return DidYouMean{
+		Terms:   v.suggestTerms(text, TermSuggestOptions{}.withDefaults()),
+		Phrases: v.suggestPhrases(text, 0),
+	}
+}
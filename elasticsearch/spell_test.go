@@ -0,0 +1,77 @@
+package hamfts
+
+import (
+	"os"
+	"testing"
+)
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"kitten", "sitting", 3},
+		{"quikc", "quick", 1},
+		{"brwn", "brown", 1},
+		{"same", "same", 0},
+		{"", "abc", 3},
+	}
+	for _, tt := range tests {
+		if got := editDistance([]rune(tt.a), []rune(tt.b)); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestDidYouMean(t *testing.T) {
+	testDir, err := os.MkdirTemp("", "hamfts_test_spell")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+
+	idx, err := NewIndex(testDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+
+	for id, content := range map[string]string{
+		"1": "the quick brown fox",
+		"2": "quick brown dogs",
+		"3": "a quiet night",
+		"4": "the black cat",
+		"5": "brawn and brain",
+	} {
+		if err := idx.AddDocument(NewDocument(id, content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	terms := idx.SuggestTerms("quikc brwn fox", TermSuggestOptions{})
+	if len(terms) != 3 {
+		t.Fatalf("got %d term suggestions, want 3", len(terms))
+	}
+	if len(terms[0].Options) == 0 || terms[0].Options[0].Text != "quick" {
+		t.Errorf("quikc: got %+v, want quick first", terms[0].Options)
+	}
+	if terms[1].Offset != 6 || terms[1].Length != 4 {
+		t.Errorf("brwn at %d+%d, want 6+4", terms[1].Offset, terms[1].Length)
+	}
+	if len(terms[1].Options) == 0 || terms[1].Options[0].Text != "brown" {
+		t.Errorf("brwn: got %+v, want brown first", terms[1].Options)
+	}
+	if len(terms[2].Options) != 0 {
+		t.Errorf("fox is in the index, got options %+v", terms[2].Options)
+	}
+
+	// The phrase suggester prefers the words that occur together
+	phrases := idx.SuggestPhrases("Quikc brawn", 3)
+	if len(phrases) == 0 || phrases[0].Text != "quick brown" {
+		t.Errorf("got phrases %+v, want quick brown first", phrases)
+	}
+
+	if dym := idx.DidYouMean("black cat"); len(dym.Phrases) != 0 {
+		t.Errorf("correct query got phrases %+v", dym.Phrases)
+	}
+}
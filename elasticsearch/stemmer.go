@@ -0,0 +1,420 @@
+package hamfts
+
+import "unicode"
+
+// porterStem implements the Porter stemming algorithm for English. Tokens
+// that are not plain lowercase ASCII are returned unchanged.
+func porterStem(word string) string {
+	if len(word) <= 2 {
+		return word
+	}
+	for i := 0; i < len(word); i++ {
+		if word[i] < 'a' || word[i] > 'z' {
+			return word
+		}
+	}
+
+	z := &porter{b: []byte(word), k: len(word) - 1}
+	z.step1ab()
+	if z.k > 0 {
+		z.step1c()
+		z.step2()
+		z.step3()
+		z.step4()
+		z.step5()
+	}
+	return string(z.b[:z.k+1])
+}
+
+// porter holds the word being stemmed in b[0..k]; j marks the end of the
+// stem once a suffix has been matched by ends.
+type porter struct {
+	b []byte
+	k int
+	j int
+}
+
+func (z *porter) cons(i int) bool {
+	switch z.b[i] {
+	case 'a', 'e', 'i', 'o', 'u':
+		return false
+	case 'y':
+		if i == 0 {
+			return true
+		}
+		return !z.cons(i - 1)
+	}
+	return true
+}
+
+// m counts the consonant-vowel sequences in b[0..j]
+func (z *porter) m() int {
+	n := 0
+	i := 0
+	for {
+		if i > z.j {
+			return n
+		}
+		if !z.cons(i) {
+			break
+		}
+		i++
+	}
+	i++
+	for {
+		for {
+			if i > z.j {
+				return n
+			}
+			if z.cons(i) {
+				break
+			}
+			i++
+		}
+		i++
+		n++
+		for {
+			if i > z.j {
+				return n
+			}
+			if !z.cons(i) {
+				break
+			}
+			i++
+		}
+		i++
+	}
+}
+
+func (z *porter) vowelInStem() bool {
+	for i := 0; i <= z.j; i++ {
+		if !z.cons(i) {
+			return true
+		}
+	}
+	return false
+}
+
+func (z *porter) doublec(j int) bool {
+	if j < 1 || z.b[j] != z.b[j-1] {
+		return false
+	}
+	return z.cons(j)
+}
+
+// cvc reports whether b[i-2..i] is consonant-vowel-consonant and the last
+// consonant is not w, x or y
+func (z *porter) cvc(i int) bool {
+	if i < 2 || !z.cons(i) || z.cons(i-1) || !z.cons(i-2) {
+		return false
+	}
+	switch z.b[i] {
+	case 'w', 'x', 'y':
+		return false
+	}
+	return true
+}
+
+func (z *porter) ends(s string) bool {
+	l := len(s)
+	if l > z.k+1 || string(z.b[z.k-l+1:z.k+1]) != s {
+		return false
+	}
+	z.j = z.k - l
+	return true
+}
+
+func (z *porter) setto(s string) {
+	z.b = append(z.b[:z.j+1], s...)
+	z.k = z.j + len(s)
+}
+
+func (z *porter) r(s string) {
+	if z.m() > 0 {
+		z.setto(s)
+	}
+}
+
+// step1ab removes plurals and -ed or -ing
+func (z *porter) step1ab() {
+	if z.b[z.k] == 's' {
+		switch {
+		case z.ends("sses"):
+			z.k -= 2
+		case z.ends("ies"):
+			z.setto("i")
+		case z.b[z.k-1] != 's':
+			z.k--
+		}
+	}
+
+	if z.ends("eed") {
+		if z.m() > 0 {
+			z.k--
+		}
+	} else if (z.ends("ed") || z.ends("ing")) && z.vowelInStem() {
+		z.k = z.j
+		switch {
+		case z.ends("at"):
+			z.setto("ate")
+		case z.ends("bl"):
+			z.setto("ble")
+		case z.ends("iz"):
+			z.setto("ize")
+		case z.doublec(z.k):
+			z.k--
+			switch z.b[z.k] {
+			case 'l', 's', 'z':
+				z.k++
+			}
+		case z.m() == 1 && z.cvc(z.k):
+			z.setto("e")
+		}
+	}
+}
+
+// step1c turns a terminal y into i when there is another vowel in the stem
+func (z *porter) step1c() {
+	if z.ends("y") && z.vowelInStem() {
+		z.b[z.k] = 'i'
+	}
+}
+
+// step2 maps double suffixes to single ones
+func (z *porter) step2() {
+	rules := porterStep2[z.b[z.k-1]]
+	for _, rule := range rules {
+		if z.ends(rule[0]) {
+			z.r(rule[1])
+			return
+		}
+	}
+}
+
+var porterStep2 = map[byte][][2]string{
+	'a': {{"ational", "ate"}, {"tional", "tion"}},
+	'c': {{"enci", "ence"}, {"anci", "ance"}},
+	'e': {{"izer", "ize"}},
+	'l': {{"bli", "ble"}, {"alli", "al"}, {"entli", "ent"}, {"eli", "e"}, {"ousli", "ous"}},
+	'o': {{"ization", "ize"}, {"ation", "ate"}, {"ator", "ate"}},
+	's': {{"alism", "al"}, {"iveness", "ive"}, {"fulness", "ful"}, {"ousness", "ous"}},
+	't': {{"aliti", "al"}, {"iviti", "ive"}, {"biliti", "ble"}},
+	'g': {{"logi", "log"}},
+}
+
+// step3 handles -ic-, -full, -ness etc.
+func (z *porter) step3() {
+	rules := porterStep3[z.b[z.k]]
+	for _, rule := range rules {
+		if z.ends(rule[0]) {
+			z.r(rule[1])
+			return
+		}
+	}
+}
+
+var porterStep3 = map[byte][][2]string{
+	'e': {{"icate", "ic"}, {"ative", ""}, {"alize", "al"}},
+	'i': {{"iciti", "ic"}},
+	'l': {{"ical", "ic"}, {"ful", ""}},
+	's': {{"ness", ""}},
+}
+
+// step4 removes -ant, -ence etc. in a context of m > 1
+func (z *porter) step4() {
+	matched := false
+	for _, suffix := range porterStep4[z.b[z.k-1]] {
+		if z.ends(suffix) {
+			if suffix == "ion" && (z.j < 0 || z.b[z.j] != 's' && z.b[z.j] != 't') {
+				continue
+			}
+			matched = true
+			break
+		}
+	}
+	if matched && z.m() > 1 {
+		z.k = z.j
+	}
+}
+
+var porterStep4 = map[byte][]string{
+	'a': {"al"},
+	'c': {"ance", "ence"},
+	'e': {"er"},
+	'i': {"ic"},
+	'l': {"able", "ible"},
+	'n': {"ant", "ement", "ment", "ent"},
+	'o': {"ion", "ou"},
+	's': {"ism"},
+	't': {"ate", "iti"},
+	'u': {"ous"},
+	'v': {"ive"},
+	'z': {"ize"},
+}
+
+// step5 removes a final -e if m > 1 and changes -ll to -l if m > 1
+func (z *porter) step5() {
+	z.j = z.k
+	if z.b[z.k] == 'e' {
+		a := z.m()
+		if a > 1 || a == 1 && !z.cvc(z.k-1) {
+			z.k--
+		}
+	}
+	if z.b[z.k] == 'l' && z.doublec(z.k) && z.m() > 1 {
+		z.k--
+	}
+}
+
+// The stemmers below follow the "light" and "minimal" stemmers of Jacques
+// Savoy that Lucene ships for these languages. They only strip common
+// inflectional suffixes, which keeps them predictable.
+
+var accentFolds = map[rune]rune{
+	'à': 'a', 'á': 'a', 'â': 'a', 'ä': 'a',
+	'è': 'e', 'é': 'e', 'ê': 'e', 'ë': 'e',
+	'ì': 'i', 'í': 'i', 'î': 'i', 'ï': 'i',
+	'ò': 'o', 'ó': 'o', 'ô': 'o', 'ö': 'o',
+	'ù': 'u', 'ú': 'u', 'û': 'u', 'ü': 'u',
+}
+
+func foldAccents(s []rune) {
+	for i, r := range s {
+		if folded, ok := accentFolds[r]; ok {
+			s[i] = folded
+		}
+	}
+}
+
+func frenchMinimalStem(word string) string {
+	s := []rune(word)
+	n := len(s)
+	if n < 6 {
+		return word
+	}
+
+	if s[n-1] == 'x' {
+		if s[n-3] == 'a' && s[n-2] == 'u' {
+			s[n-2] = 'l'
+		}
+		return string(s[:n-1])
+	}
+	if s[n-1] == 's' {
+		n--
+	}
+	if s[n-1] == 'r' {
+		n--
+	}
+	if s[n-1] == 'e' {
+		n--
+	}
+	if s[n-1] == 'é' {
+		n--
+	}
+	if s[n-1] == s[n-2] && unicode.IsLetter(s[n-1]) {
+		n--
+	}
+	return string(s[:n])
+}
+
+func germanLightStem(word string) string {
+	s := []rune(word)
+	for i, r := range s {
+		switch r {
+		case 'ä', 'à', 'á', 'â':
+			s[i] = 'a'
+		case 'ö', 'ò', 'ó', 'ô':
+			s[i] = 'o'
+		case 'ï', 'ì', 'í', 'î':
+			s[i] = 'i'
+		case 'ü', 'ù', 'ú', 'û':
+			s[i] = 'u'
+		}
+	}
+
+	stEnding := func(r rune) bool {
+		switch r {
+		case 'b', 'd', 'f', 'g', 'h', 'k', 'l', 'm', 'n', 't':
+			return true
+		}
+		return false
+	}
+
+	n := len(s)
+	switch {
+	case n > 5 && s[n-3] == 'e' && s[n-2] == 'r' && s[n-1] == 'n':
+		n -= 3
+	case n > 4 && s[n-2] == 'e' && (s[n-1] == 'm' || s[n-1] == 'n' || s[n-1] == 'r' || s[n-1] == 's'):
+		n -= 2
+	case n > 3 && s[n-1] == 'e':
+		n--
+	case n > 3 && s[n-1] == 's' && stEnding(s[n-2]):
+		n--
+	}
+
+	switch {
+	case n > 5 && s[n-3] == 'e' && s[n-2] == 's' && s[n-1] == 't':
+		n -= 3
+	case n > 4 && s[n-2] == 'e' && (s[n-1] == 'r' || s[n-1] == 'n'):
+		n -= 2
+	case n > 4 && s[n-2] == 's' && s[n-1] == 't' && stEnding(s[n-3]):
+		n -= 2
+	}
+	return string(s[:n])
+}
+
+func spanishLightStem(word string) string {
+	s := []rune(word)
+	n := len(s)
+	if n < 5 {
+		return word
+	}
+	foldAccents(s)
+
+	switch s[n-1] {
+	case 'o', 'a', 'e':
+		n--
+	case 's':
+		switch {
+		case s[n-2] == 'e' && s[n-3] == 's' && s[n-4] == 'e':
+			n -= 2
+		case s[n-2] == 'e' && s[n-3] == 'c':
+			s[n-3] = 'z'
+			n -= 2
+		case s[n-2] == 'o' || s[n-2] == 'a' || s[n-2] == 'e':
+			n -= 2
+		}
+	}
+	return string(s[:n])
+}
+
+func italianLightStem(word string) string {
+	s := []rune(word)
+	n := len(s)
+	if n < 6 {
+		return word
+	}
+	foldAccents(s)
+
+	switch s[n-1] {
+	case 'e':
+		if s[n-2] == 'i' || s[n-2] == 'h' {
+			n -= 2
+		} else {
+			n--
+		}
+	case 'i':
+		if s[n-2] == 'h' || s[n-2] == 'i' {
+			n -= 2
+		} else {
+			n--
+		}
+	case 'a', 'o':
+		if s[n-2] == 'i' {
+			n -= 2
+		} else {
+			n--
+		}
+	}
+	return string(s[:n])
+}
@@ -0,0 +1,55 @@
+package hamfts
+
+func wordSet(words ...string) map[string]struct{} {
+	set := make(map[string]struct{}, len(words))
+	for _, w := range words {
+		set[w] = struct{}{}
+	}
+	return set
+}
+
+var stopWords = map[string]map[string]struct{}{
+	"english": wordSet(
+		"a", "an", "and", "are", "as", "at", "be", "but", "by", "for", "if",
+		"in", "into", "is", "it", "no", "not", "of", "on", "or", "such", "that",
+		"the", "their", "then", "there", "these", "they", "this", "to", "was",
+		"will", "with",
+	),
+	"french": wordSet(
+		"au", "aux", "avec", "ce", "ces", "dans", "de", "des", "du", "elle",
+		"en", "et", "eux", "il", "je", "la", "le", "les", "leur", "lui", "ma",
+		"mais", "me", "même", "mes", "moi", "mon", "ne", "nos", "notre", "nous",
+		"on", "ou", "par", "pas", "pour", "qu", "que", "qui", "sa", "se", "ses",
+		"son", "sur", "ta", "te", "tes", "toi", "ton", "tu", "un", "une", "vos",
+		"votre", "vous", "c", "d", "j", "l", "m", "n", "s", "t", "y", "été",
+		"est", "sont", "était",
+	),
+	"german": wordSet(
+		"aber", "als", "am", "an", "auch", "auf", "aus", "bei", "bin", "bis",
+		"da", "das", "dass", "dem", "den", "der", "des", "die", "doch", "du",
+		"ein", "eine", "einem", "einen", "einer", "eines", "er", "es", "für",
+		"hat", "ich", "ihr", "im", "in", "ist", "ja", "mit", "nach", "nicht",
+		"noch", "nur", "oder", "sich", "sie", "sind", "so", "um", "und", "uns",
+		"von", "vor", "war", "wie", "wir", "zu", "zum", "zur",
+	),
+	"spanish": wordSet(
+		"a", "al", "como", "con", "de", "del", "el", "ella", "en", "era", "es",
+		"esta", "este", "fue", "ha", "la", "las", "le", "les", "lo", "los",
+		"más", "me", "mi", "muy", "no", "o", "para", "pero", "por", "que", "se",
+		"si", "sin", "son", "su", "sus", "también", "te", "tu", "un", "una",
+		"uno", "y", "ya", "yo",
+	),
+	"italian": wordSet(
+		"a", "ad", "al", "alla", "anche", "che", "chi", "con", "da", "dal",
+		"dei", "del", "della", "di", "e", "è", "ed", "gli", "ha", "i", "il",
+		"in", "io", "la", "le", "lei", "lo", "lui", "ma", "mi", "ne", "nel",
+		"nella", "non", "o", "per", "più", "se", "si", "sono", "su", "sua",
+		"suo", "tra", "tu", "un", "una", "uno",
+	),
+}
+
+// elisionArticles are the articles stripped from tokens such as "l'avion"
+var elisionArticles = map[string]map[string]struct{}{
+	"french":  wordSet("l", "m", "t", "qu", "n", "s", "j", "d", "c", "jusqu", "quoiqu", "lorsqu", "puisqu"),
+	"italian": wordSet("c", "l", "all", "dall", "dell", "nell", "sull", "coll", "pell", "gl", "agl", "dagl", "degl", "negl", "sugl", "un", "m", "t", "s", "v", "d"),
+}
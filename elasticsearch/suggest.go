@@ -0,0 +1,587 @@
+package hamfts
+
+import (
+	"container/heap"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+const defaultSuggestSize = 5
+
+// SuggestRequest asks a completion field for the best suggestions starting
+// with Prefix.
+type SuggestRequest struct {
+	Field  string `json:"field"`
+	Prefix string `json:"prefix"`
+	Size   int    `json:"size,omitempty"` // defaults to 5
+	// Fuzzy also completes prefixes within a few edits of Prefix
+	Fuzzy *FuzzyOptions `json:"fuzzy,omitempty"`
+	// Contexts keeps suggestions having one of the listed values for every
+	// given context
+	Contexts map[string][]string `json:"contexts,omitempty"`
+	// SkipDuplicates returns every suggestion text only once
+	SkipDuplicates bool `json:"skip_duplicates,omitempty"`
+}
+
+type FuzzyOptions struct {
+	// Fuzziness is the maximum number of edits. Zero picks it from the
+	// prefix length: none below 3 characters, 1 up to 5 and 2 beyond.
+	Fuzziness int `json:"fuzziness,omitempty"`
+	// PrefixLength leading characters must match exactly
+	PrefixLength int `json:"prefix_length,omitempty"`
+}
+
+// Suggestion is a completion returned by Suggest
+type Suggestion struct {
+	Text   string `json:"text"`
+	ID     string `json:"_id"`
+	Weight int    `json:"weight"`
+}
+
+// completionInput is one suggestion taken from a completion field value
+type completionInput struct {
+	Input    []string
+	Weight   int
+	Contexts map[string][]string
+}
+
+// parseCompletionValue reads a completion field value: a string, an array,
+// or an object with "input", "weight" and "contexts".
+func parseCompletionValue(value interface{}) ([]completionInput, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []completionInput{{Input: []string{v}, Weight: 1}}, nil
+	case []string:
+		return []completionInput{{Input: v, Weight: 1}}, nil
+	case []interface{}:
+		var inputs []completionInput
+		for _, elem := range v {
+			parsed, err := parseCompletionValue(elem)
+			if err != nil {
+				return nil, err
+			}
+			inputs = append(inputs, parsed...)
+		}
+		return inputs, nil
+	case map[string]interface{}:
+		in := completionInput{Weight: 1}
+		for key, field := range v {
+			switch key {
+			case "input":
+				strs, err := stringList(field)
+				if err != nil {
+					return nil, fmt.Errorf("completion input: %v", err)
+				}
+				in.Input = strs
+			case "weight":
+				f, err := toFloat(field)
+				if err != nil || f < 0 || f != float64(int(f)) {
+					return nil, fmt.Errorf("completion weight must be a non-negative integer, got %v", field)
+				}
+				in.Weight = int(f)
+			case "contexts":
+				contexts, ok := field.(map[string]interface{})
+				if !ok {
+					return nil, fmt.Errorf("completion contexts must be an object, got %T", field)
+				}
+				in.Contexts = make(map[string][]string, len(contexts))
+				for name, values := range contexts {
+					strs, err := stringList(values)
+					if err != nil {
+						return nil, fmt.Errorf("completion context %q: %v", name, err)
+					}
+					in.Contexts[name] = strs
+				}
+			default:
+				return nil, fmt.Errorf("unknown completion property %q", key)
+			}
+		}
+		if len(in.Input) == 0 {
+			return nil, fmt.Errorf("completion value has no input")
+		}
+		return []completionInput{in}, nil
+	}
+	return nil, fmt.Errorf("expected completion input, got %T", value)
+}
+
+func stringList(value interface{}) ([]string, error) {
+	switch v := value.(type) {
+	case string:
+		return []string{v}, nil
+	case []string:
+		return v, nil
+	case []interface{}:
+		strs := make([]string, 0, len(v))
+		for _, elem := range v {
+			s, ok := elem.(string)
+			if !ok {
+				return nil, fmt.Errorf("expected string, got %T", elem)
+			}
+			strs = append(strs, s)
+		}
+		return strs, nil
+	}
+	return nil, fmt.Errorf("expected string or array of strings, got %T", value)
+}
+
+// completionKey normalizes a suggestion input or prefix for lookup
+func completionKey(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+// suggestEntry is a single input of a completion field
+type suggestEntry struct {
+	Input    string              `json:"input"`
+	Weight   int                 `json:"weight"`
+	DocID    string              `json:"id"`
+	Contexts map[string][]string `json:"contexts,omitempty"`
+}
+
+// suggestNode is a node of the prefix tree over normalized inputs. Each
+// node knows the highest weight below it, so the best completions are found
+// without visiting the whole subtree.
+type suggestNode struct {
+	children  map[rune]*suggestNode
+	entries   []*suggestEntry
+	maxWeight int
+}
+
+type completionField struct {
+	docs map[string][]*suggestEntry // docID -> entries, for removal
+	root *suggestNode
+}
+
+// completionIndex holds the suggestion trees of all completion fields. The
+// entries are persisted as JSON and the trees rebuilt when the index opens.
+// Writers change the trees in place, so unlike the postings they are
+// guarded by a lock of their own, held for the change only.
+type completionIndex struct {
+	mu     sync.RWMutex
+	path   string
+	fields map[string]*completionField
+	dirty  bool
+}
+
+func loadCompletionIndex(path string) (*completionIndex, error) {
+	c := &completionIndex{path: path, fields: make(map[string]*completionField)}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return c, nil
+		}
+		return nil, err
+	}
+
+	var stored map[string]map[string][]*suggestEntry
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrCorrupt, path, err)
+	}
+	for field, docs := range stored {
+		for _, entries := range docs {
+			for _, entry := range entries {
+				c.insert(field, entry)
+			}
+		}
+	}
+	return c, nil
+}
+
+// save writes the entries if they changed. Only writers change them, and
+// writers take turns, so they stay as marshalled until written.
+func (c *completionIndex) save() error {
+	c.mu.RLock()
+	if !c.dirty {
+		c.mu.RUnlock()
+		return nil
+	}
+	stored := make(map[string]map[string][]*suggestEntry, len(c.fields))
+	for name, field := range c.fields {
+		stored[name] = field.docs
+	}
+	data, err := json.Marshal(stored)
+	c.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(c.path, data, 0644); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.dirty = false
+	c.mu.Unlock()
+	return nil
+}
+
+// addDocument records the suggestions of every completion field of doc,
+// replacing any it had before
+func (c *completionIndex) addDocument(doc *Document, m Mapping) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.remove(doc.ID)
+
+	var values map[string][]interface{}
+	for _, path := range m.FieldNames() {
+		fm := m.Fields[path]
+		if fm.Type != FieldCompletion {
+			continue
+		}
+		inputs, err := parseCompletionValue(metadataValue(doc.Metadata, path))
+		if err != nil || len(inputs) == 0 {
+			continue
+		}
+
+		// Contexts with a path take their values from other metadata fields
+		pathContexts := make(map[string][]string)
+		for _, ctx := range fm.Contexts {
+			if ctx.Path == "" {
+				continue
+			}
+			if values == nil {
+				values = make(map[string][]interface{})
+				flattenMetadata("", doc.Metadata, values)
+			}
+			for _, v := range values[ctx.Path] {
+				if term, err := normalizeTerm(FieldKeyword, v); err == nil {
+					pathContexts[ctx.Name] = append(pathContexts[ctx.Name], term)
+				}
+			}
+		}
+
+		for _, in := range inputs {
+			contexts := make(map[string][]string)
+			for name, vals := range pathContexts {
+				contexts[name] = append(contexts[name], vals...)
+			}
+			for name, vals := range in.Contexts {
+				contexts[name] = append(contexts[name], vals...)
+			}
+			if len(contexts) == 0 {
+				contexts = nil
+			}
+			for _, input := range in.Input {
+				if completionKey(input) == "" {
+					continue
+				}
+				c.insert(path, &suggestEntry{Input: input, Weight: in.Weight, DocID: doc.ID, Contexts: contexts})
+			}
+		}
+	}
+}
+
+func (c *completionIndex) insert(field string, entry *suggestEntry) {
+	f, ok := c.fields[field]
+	if !ok {
+		f = &completionField{docs: make(map[string][]*suggestEntry), root: &suggestNode{}}
+		c.fields[field] = f
+	}
+	f.docs[entry.DocID] = append(f.docs[entry.DocID], entry)
+
+	node := f.root
+	for _, r := range completionKey(entry.Input) {
+		if entry.Weight > node.maxWeight {
+			node.maxWeight = entry.Weight
+		}
+		child, ok := node.children[r]
+		if !ok {
+			if node.children == nil {
+				node.children = make(map[rune]*suggestNode)
+			}
+			child = &suggestNode{}
+			node.children[r] = child
+		}
+		node = child
+	}
+	if entry.Weight > node.maxWeight {
+		node.maxWeight = entry.Weight
+	}
+	node.entries = append(node.entries, entry)
+	c.dirty = true
+}
+
+func (c *completionIndex) removeDocument(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.remove(id)
+}
+
+func (c *completionIndex) remove(id string) {
+	for name, f := range c.fields {
+		entries, ok := f.docs[id]
+		if !ok {
+			continue
+		}
+		delete(f.docs, id)
+		for _, entry := range entries {
+			f.root.remove(entry, []rune(completionKey(entry.Input)))
+		}
+		if len(f.docs) == 0 {
+			delete(c.fields, name)
+		}
+		c.dirty = true
+	}
+}
+
+// remove deletes entry from the subtree at key and recomputes the weights
+// along the way. It reports whether the node is left empty.
+func (n *suggestNode) remove(entry *suggestEntry, key []rune) bool {
+	if len(key) == 0 {
+		kept := n.entries[:0]
+		for _, e := range n.entries {
+			if e != entry {
+				kept = append(kept, e)
+			}
+		}
+		n.entries = kept
+	} else if child, ok := n.children[key[0]]; ok && child.remove(entry, key[1:]) {
+		delete(n.children, key[0])
+	}
+
+	n.maxWeight = 0
+	for _, e := range n.entries {
+		if e.Weight > n.maxWeight {
+			n.maxWeight = e.Weight
+		}
+	}
+	for _, child := range n.children {
+		if child.maxWeight > n.maxWeight {
+			n.maxWeight = child.maxWeight
+		}
+	}
+	return len(n.entries) == 0 && len(n.children) == 0
+}
+
+// metadataValue returns the raw value at a dotted path of meta
+func metadataValue(meta map[string]interface{}, path string) interface{} {
+	var value interface{} = meta
+	for _, key := range strings.Split(path, ".") {
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = obj[key]
+	}
+	return value
+}
+
+// suggestStart is a subtree whose completions all match the prefix
+type suggestStart struct {
+	node  *suggestNode
+	key   string
+	edits int
+}
+
+// exactStart finds the subtree below prefix
+func (f *completionField) exactStart(prefix string) []suggestStart {
+	node := f.root
+	for _, r := range prefix {
+		if node = node.children[r]; node == nil {
+			return nil
+		}
+	}
+	return []suggestStart{{node: node, key: prefix}}
+}
+
+// fuzzyStarts finds the subtrees whose key is within maxEdits of prefix,
+// counting insertions, deletions, substitutions and transpositions.
+func (f *completionField) fuzzyStarts(prefix string, maxEdits, prefixLength int) []suggestStart {
+	query := []rune(prefix)
+	row := make([]int, len(query)+1)
+	for j := range row {
+		row[j] = j
+	}
+
+	var starts []suggestStart
+	var walk func(node *suggestNode, key []rune, prev, prevPrev []int)
+	walk = func(node *suggestNode, key []rune, prev, prevPrev []int) {
+		for r, child := range node.children {
+			depth := len(key) + 1
+			if depth <= prefixLength && (depth > len(query) || query[depth-1] != r) {
+				continue
+			}
+
+			cur := make([]int, len(query)+1)
+			cur[0] = depth
+			best := cur[0]
+			for j := 1; j <= len(query); j++ {
+				cost := 1
+				if query[j-1] == r {
+					cost = 0
+				}
+				cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+				if depth > 1 && j > 1 && r == query[j-2] && key[len(key)-1] == query[j-1] {
+					cur[j] = min(cur[j], prevPrev[j-2]+1)
+				}
+				best = min(best, cur[j])
+			}
+
+			childKey := append(append([]rune(nil), key...), r)
+			if cur[len(query)] <= maxEdits {
+				starts = append(starts, suggestStart{node: child, key: string(childKey), edits: cur[len(query)]})
+			}
+			if best <= maxEdits {
+				walk(child, childKey, cur, prev)
+			}
+		}
+	}
+	if len(query) <= maxEdits {
+		starts = append(starts, suggestStart{node: f.root, edits: len(query)})
+	}
+	walk(f.root, nil, row, nil)
+	return starts
+}
+
+// suggestItem is a queued subtree or entry, ordered by weight
+type suggestItem struct {
+	node   *suggestNode
+	entry  *suggestEntry
+	key    string
+	weight int
+	edits  int
+}
+
+type suggestQueue []suggestItem
+
+func (q suggestQueue) Len() int { return len(q) }
+func (q suggestQueue) Less(i, j int) bool {
+	if q[i].weight != q[j].weight {
+		return q[i].weight > q[j].weight
+	}
+	if q[i].edits != q[j].edits {
+		return q[i].edits < q[j].edits
+	}
+	if q[i].key != q[j].key {
+		return q[i].key < q[j].key
+	}
+	// Entries before the subtrees that share their key
+	return q[i].entry != nil && q[j].entry == nil
+}
+func (q suggestQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *suggestQueue) Push(x interface{}) { *q = append(*q, x.(suggestItem)) }
+func (q *suggestQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
+
+// collect returns the size best entries below starts, best first
+func collect(starts []suggestStart, size int, keep func(*suggestEntry) bool, skipDuplicates bool) []Suggestion {
+	q := &suggestQueue{}
+	for _, start := range starts {
+		heap.Push(q, suggestItem{node: start.node, key: start.key, weight: start.node.maxWeight, edits: start.edits})
+	}
+
+	seen := make(map[*suggestEntry]bool)
+	texts := make(map[string]bool)
+	var results []Suggestion
+	for q.Len() > 0 && len(results) < size {
+		item := heap.Pop(q).(suggestItem)
+		if item.entry != nil {
+			if seen[item.entry] || !keep(item.entry) {
+				continue
+			}
+			seen[item.entry] = true
+			if skipDuplicates {
+				if texts[item.entry.Input] {
+					continue
+				}
+				texts[item.entry.Input] = true
+			}
+			results = append(results, Suggestion{Text: item.entry.Input, ID: item.entry.DocID, Weight: item.entry.Weight})
+			continue
+		}
+
+		for _, entry := range item.node.entries {
+			heap.Push(q, suggestItem{entry: entry, key: item.key, weight: entry.Weight, edits: item.edits})
+		}
+		for r, child := range item.node.children {
+			heap.Push(q, suggestItem{node: child, key: item.key + string(r), weight: child.maxWeight, edits: item.edits})
+		}
+	}
+	return results
+}
+
+// autoFuzziness picks the number of allowed edits from the prefix length
+func autoFuzziness(prefix string) int {
+	switch n := utf8.RuneCountInString(prefix); {
+	case n < 3:
+		return 0
+	case n <= 5:
+		return 1
+	}
+	return 2
+}
+
+// Suggest returns the highest weighted completions of a completion field
+func (idx *Index) Suggest(req SuggestRequest) ([]Suggestion, error) {
+	v := idx.acquire()
+	defer v.release()
+
+	fm, ok := v.metadata.Mapping.Fields[req.Field]
+	if !ok || fm.Type != FieldCompletion {
+		return nil, queryError("field %q is not a completion field", req.Field)
+	}
+	declared := make(map[string]bool, len(fm.Contexts))
+	for _, ctx := range fm.Contexts {
+		declared[ctx.Name] = true
+	}
+	for name := range req.Contexts {
+		if !declared[name] {
+			return nil, queryError("field %q has no context %q", req.Field, name)
+		}
+	}
+
+	size := req.Size
+	if size <= 0 {
+		size = defaultSuggestSize
+	}
+	v.suggest.mu.RLock()
+	defer v.suggest.mu.RUnlock()
+	f, ok := v.suggest.fields[req.Field]
+	if !ok {
+		return []Suggestion{}, nil
+	}
+
+	prefix := completionKey(req.Prefix)
+	var starts []suggestStart
+	if req.Fuzzy != nil {
+		edits := req.Fuzzy.Fuzziness
+		if edits == 0 {
+			edits = autoFuzziness(prefix)
+		}
+		starts = f.fuzzyStarts(prefix, edits, req.Fuzzy.PrefixLength)
+	} else {
+		starts = f.exactStart(prefix)
+	}
+
+	keep := func(entry *suggestEntry) bool {
+		for name, want := range req.Contexts {
+			if !hasAny(entry.Contexts[name], want) {
+				return false
+			}
+		}
+		return true
+	}
+	results := collect(starts, size, keep, req.SkipDuplicates)
+	if results == nil {
+		results = []Suggestion{}
+	}
+	return results, nil
+}
+
+func hasAny(values, want []string) bool {
+	for _, v := range values {
+		for _, w := range want {
+			if v == w {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package hamfts
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestCompletionSuggester(t *testing.T) {
+	testDir, err := os.MkdirTemp("", "hamfts_test_suggest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+
+	idx, err := NewIndex(testDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := idx.PutMapping(Mapping{Fields: map[string]*FieldMapping{
+		"suggest": {Type: FieldCompletion, Contexts: []ContextMapping{
+			{Name: "category", Type: "category", Path: "category"},
+		}},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+
+	docs := []struct {
+		id       string
+		suggest  interface{}
+		category string
+	}{
+		{"1", map[string]interface{}{"input": []interface{}{"Nevermind", "Nirvana"}, "weight": float64(34)}, "music"},
+		{"2", map[string]interface{}{"input": "Never Let Me Go", "weight": float64(10)}, "books"},
+		{"3", "Neverwhere", "books"},
+		{"4", map[string]interface{}{"input": "Nightfall", "weight": float64(50)}, "books"},
+	}
+	for _, d := range docs {
+		doc := NewDocument(d.id, "")
+		doc.Metadata["suggest"] = d.suggest
+		doc.Metadata["category"] = d.category
+		if err := idx.AddDocument(doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Completion objects are not mapped field by field
+	if _, ok := idx.GetMapping().Fields["suggest.input"]; ok {
+		t.Error("suggest.input should not be mapped")
+	}
+
+	texts := func(req SuggestRequest) []string {
+		t.Helper()
+		results, err := idx.Suggest(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, s := range results {
+			got = append(got, s.Text)
+		}
+		return got
+	}
+
+	tests := []struct {
+		name string
+		req  SuggestRequest
+		want []string
+	}{
+		{"prefix by weight", SuggestRequest{Field: "suggest", Prefix: "nev"}, []string{"Nevermind", "Never Let Me Go", "Neverwhere"}},
+		{"size", SuggestRequest{Field: "suggest", Prefix: "n", Size: 2}, []string{"Nightfall", "Nevermind"}},
+		{"multi word", SuggestRequest{Field: "suggest", Prefix: "never  LET"}, []string{"Never Let Me Go"}},
+		{"context", SuggestRequest{Field: "suggest", Prefix: "nev", Contexts: map[string][]string{"category": {"books"}}}, []string{"Never Let Me Go", "Neverwhere"}},
+		{"no match", SuggestRequest{Field: "suggest", Prefix: "nevx"}, nil},
+		{"fuzzy", SuggestRequest{Field: "suggest", Prefix: "nevx", Fuzzy: &FuzzyOptions{Fuzziness: 1}}, []string{"Nevermind", "Never Let Me Go", "Neverwhere"}},
+		{"fuzzy transposition", SuggestRequest{Field: "suggest", Prefix: "nrivana", Fuzzy: &FuzzyOptions{PrefixLength: 1}}, []string{"Nirvana"}},
+	}
+	for _, tt := range tests {
+		if got := texts(tt.req); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	if _, err := idx.Suggest(SuggestRequest{Field: "category", Prefix: "b"}); err == nil {
+		t.Error("expected an error for a non-completion field")
+	}
+
+	// Suggestions are persisted and follow deletes
+	if err := idx.DeleteDocument("4"); err != nil {
+		t.Fatal(err)
+	}
+	idx.Close()
+
+	idx, err = NewIndex(testDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+	if got := texts(SuggestRequest{Field: "suggest", Prefix: "n", Size: 2}); !reflect.DeepEqual(got, []string{"Nevermind", "Nirvana"}) {
+		t.Errorf("after reopen: got %q", got)
+	}
+}
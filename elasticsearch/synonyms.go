@@ -0,0 +1,270 @@
+package hamfts
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// TokenSynonym is the type of tokens emitted by a synonym filter
+const TokenSynonym = "synonym"
+
+// synonymRule is a parsed rule before its phrases are analyzed. Every input
+// phrase is replaced by all output phrases.
+type synonymRule struct {
+	inputs  []string
+	outputs []string
+}
+
+type synonymEntry struct {
+	input   []string
+	outputs [][]string
+}
+
+// synonymMap holds analyzed rules keyed by the first term of their input
+type synonymMap struct {
+	entries map[string][]*synonymEntry
+}
+
+// loadSynonyms reads the inline and file rules of a synonym filter and
+// analyzes them with the part of the analyzer chain before the filter.
+func loadSynonyms(fc FilterConfig, dir string, partial *Analyzer) (*synonymMap, error) {
+	lines := append([]string(nil), fc.Synonyms...)
+	if fc.SynonymsPath != "" {
+		if !filepath.IsLocal(fc.SynonymsPath) {
+			return nil, fmt.Errorf("synonyms_path %q must be relative to the analysis directory", fc.SynonymsPath)
+		}
+		data, err := os.ReadFile(filepath.Join(dir, fc.SynonymsPath))
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, strings.Split(string(data), "\n")...)
+	}
+
+	expand := fc.Expand == nil || *fc.Expand
+	var rules []synonymRule
+	var err error
+	switch fc.Format {
+	case "", "solr":
+		rules, err = parseSolrSynonyms(lines, expand)
+	case "wordnet":
+		rules, err = parseWordnetSynonyms(lines, expand)
+	default:
+		err = fmt.Errorf("unknown synonyms format %q", fc.Format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	m := &synonymMap{entries: make(map[string][]*synonymEntry)}
+	byInput := make(map[string]*synonymEntry)
+	for _, rule := range rules {
+		var outputs [][]string
+		for _, phrase := range rule.outputs {
+			if terms := partial.Analyze(phrase); len(terms) > 0 {
+				outputs = append(outputs, terms)
+			}
+		}
+
+		for _, phrase := range rule.inputs {
+			input := partial.Analyze(phrase)
+			if len(input) == 0 {
+				continue
+			}
+			key := strings.Join(input, "\x00")
+			entry, ok := byInput[key]
+			if !ok {
+				entry = &synonymEntry{input: input}
+				byInput[key] = entry
+				m.entries[input[0]] = append(m.entries[input[0]], entry)
+			}
+			for _, output := range outputs {
+				entry.addOutput(output)
+			}
+		}
+	}
+
+	// Longest inputs first so that multi-word rules win
+	for _, entries := range m.entries {
+		sort.SliceStable(entries, func(i, j int) bool {
+			return len(entries[i].input) > len(entries[j].input)
+		})
+	}
+	return m, nil
+}
+
+func (e *synonymEntry) addOutput(output []string) {
+	for _, existing := range e.outputs {
+		if equalTerms(existing, output) {
+			return
+		}
+	}
+	e.outputs = append(e.outputs, output)
+}
+
+func equalTerms(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// parseSolrSynonyms parses rules such as "car, automobile" (equivalent) and
+// "ny, nyc => new york" (explicit mapping). Lines starting with # are comments.
+func parseSolrSynonyms(lines []string, expand bool) ([]synonymRule, error) {
+	var rules []synonymRule
+	for n, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.Contains(line, "=>") {
+			sides := strings.Split(line, "=>")
+			if len(sides) != 2 {
+				return nil, fmt.Errorf("line %d: more than one => in %q", n+1, line)
+			}
+			inputs, outputs := splitPhrases(sides[0]), splitPhrases(sides[1])
+			if len(inputs) == 0 || len(outputs) == 0 {
+				return nil, fmt.Errorf("line %d: empty side in %q", n+1, line)
+			}
+			rules = append(rules, synonymRule{inputs: inputs, outputs: outputs})
+			continue
+		}
+
+		phrases := splitPhrases(line)
+		if expand {
+			rules = append(rules, synonymRule{inputs: phrases, outputs: phrases})
+		} else {
+			rules = append(rules, synonymRule{inputs: phrases, outputs: phrases[:1]})
+		}
+	}
+	return rules, nil
+}
+
+func splitPhrases(s string) []string {
+	var phrases []string
+	for _, phrase := range strings.Split(s, ",") {
+		if phrase = strings.TrimSpace(phrase); phrase != "" {
+			phrases = append(phrases, phrase)
+		}
+	}
+	return phrases
+}
+
+// parseWordnetSynonyms reads the s(...) facts of the WordNet prolog format,
+// e.g. s(102958343,1,'car',n,1,0). Words of the same synset are equivalent.
+func parseWordnetSynonyms(lines []string, expand bool) ([]synonymRule, error) {
+	synsets := make(map[string][]string)
+	var order []string
+	for n, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "%") {
+			continue
+		}
+		if !strings.HasPrefix(line, "s(") {
+			return nil, fmt.Errorf("line %d: expected s(...) fact, got %q", n+1, line)
+		}
+
+		comma := strings.Index(line, ",")
+		open := strings.Index(line, "'")
+		if comma < 0 || open < 0 {
+			return nil, fmt.Errorf("line %d: malformed fact %q", n+1, line)
+		}
+		id := line[2:comma]
+
+		// Words are quoted, with '' standing for an apostrophe
+		var word strings.Builder
+		i := open + 1
+		for ; i < len(line); i++ {
+			if line[i] == '\'' {
+				if i+1 < len(line) && line[i+1] == '\'' {
+					word.WriteByte('\'')
+					i++
+					continue
+				}
+				break
+			}
+			word.WriteByte(line[i])
+		}
+		if i == len(line) {
+			return nil, fmt.Errorf("line %d: unterminated word in %q", n+1, line)
+		}
+
+		if _, ok := synsets[id]; !ok {
+			order = append(order, id)
+		}
+		synsets[id] = append(synsets[id], word.String())
+	}
+
+	rules := make([]synonymRule, 0, len(order))
+	for _, id := range order {
+		words := synsets[id]
+		if expand {
+			rules = append(rules, synonymRule{inputs: words, outputs: words})
+		} else {
+			rules = append(rules, synonymRule{inputs: words, outputs: words[:1]})
+		}
+	}
+	return rules, nil
+}
+
+// filter replaces every matched input with its outputs. All outputs of a
+// match share a slot, so a query is satisfied by any one of them.
+func (m *synonymMap) filter(tokens []Token) []Token {
+	slot := 0
+	for _, token := range tokens {
+		if token.Slot > slot {
+			slot = token.Slot
+		}
+	}
+
+	out := make([]Token, 0, len(tokens))
+	for i := 0; i < len(tokens); {
+		entry := m.match(tokens[i:])
+		if entry == nil {
+			out = append(out, tokens[i])
+			i++
+			continue
+		}
+
+		slot++
+		n := len(entry.input)
+		start, end := tokens[i].Start, tokens[i+n-1].End
+		for alt, output := range entry.outputs {
+			for _, term := range output {
+				out = append(out, Token{Term: term, Start: start, End: end, Type: TokenSynonym, Slot: slot, Alt: alt})
+			}
+		}
+		i += n
+	}
+	return out
+}
+
+// match returns the longest entry whose input starts the token stream
+func (m *synonymMap) match(tokens []Token) *synonymEntry {
+	for _, entry := range m.entries[tokens[0].Term] {
+		if len(entry.input) > len(tokens) {
+			continue
+		}
+		matched := true
+		for k, term := range entry.input {
+			// Tokens already grouped by another synonym filter are left alone
+			if tokens[k].Term != term || tokens[k].Slot != 0 {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return entry
+		}
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package hamfts
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSynonymRules(t *testing.T) {
+	a, err := newAnalysis(AnalysisSettings{
+		Analyzers: map[string]AnalyzerConfig{
+			"syn":     {Filters: []string{"lowercase", "my_synonyms"}},
+			"wordnet": {Filters: []string{"lowercase", "my_wordnet"}},
+		},
+		Filters: map[string]FilterConfig{
+			"my_synonyms": {Type: "synonym", Synonyms: []string{
+				"# comment",
+				"car, automobile",
+				"ny, new york",
+				"tv => television",
+			}},
+			"my_wordnet": {Type: "synonym", Format: "wordnet", Synonyms: []string{
+				"s(100000001,1,'couch',n,1,0).",
+				"s(100000001,2,'sofa',n,1,0).",
+				"s(100000002,1,'o''clock',n,1,0).",
+			}},
+		},
+	}, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		analyzer string
+		text     string
+		want     [][][]string
+	}{
+		{"syn", "red car", [][][]string{{{"red"}}, {{"car"}, {"automobile"}}}},
+		{"syn", "New York pizza", [][][]string{{{"ny"}, {"new", "york"}}, {{"pizza"}}}},
+		{"syn", "TV show", [][][]string{{{"television"}}, {{"show"}}}},
+		{"syn", "television", [][][]string{{{"television"}}}},
+		{"wordnet", "sofa", [][][]string{{{"couch"}, {"sofa"}}}},
+	}
+	for _, tt := range tests {
+		got := querySlots(a.analyzers[tt.analyzer].AnalyzeTokens(tt.text))
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s(%q) = %v, want %v", tt.analyzer, tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestSynonymSearch(t *testing.T) {
+	testDir, err := os.MkdirTemp("", "hamfts_test_synonyms")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+
+	rulesPath := filepath.Join(testDir, "analysis", "synonyms.txt")
+	if err := os.MkdirAll(filepath.Dir(rulesPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(rulesPath, []byte("quick, fast\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	idx, err := NewIndexWithOptions(testDir, IndexOptions{
+		Analysis: &AnalysisSettings{
+			SearchAnalyzer: "search_synonyms",
+			Analyzers: map[string]AnalyzerConfig{
+				"search_synonyms": {Filters: []string{"lowercase", "file_synonyms"}},
+			},
+			Filters: map[string]FilterConfig{
+				"file_synonyms": {Type: "synonym", SynonymsPath: "synonyms.txt", Updateable: true},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+
+	if err := idx.AddDocument(NewDocument("1", "a fast car")); err != nil {
+		t.Fatal(err)
+	}
+	if err := idx.AddDocument(NewDocument("2", "a speedy boat")); err != nil {
+		t.Fatal(err)
+	}
+
+	search := func(query string) []string {
+		t.Helper()
+		docs, err := idx.Search(query, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []string
+		for _, doc := range docs {
+			ids = append(ids, doc.ID)
+		}
+		return uniqueTerms(ids)
+	}
+
+	if got := search("quick car"); !reflect.DeepEqual(got, []string{"1"}) {
+		t.Errorf("search(quick car) = %v, want [1]", got)
+	}
+	if got := search("quick"); len(got) != 1 {
+		t.Errorf("search(quick) = %v, want [1]", got)
+	}
+
+	// Editing the file only takes effect after a reload, without reindexing
+	if err := os.WriteFile(rulesPath, []byte("quick, fast, speedy\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := search("quick"); len(got) != 1 {
+		t.Errorf("before reload: search(quick) = %v, want [1]", got)
+	}
+	reloaded, err := idx.ReloadSearchAnalyzers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(reloaded, []string{"search_synonyms"}) {
+		t.Errorf("reloaded = %v, want [search_synonyms]", reloaded)
+	}
+	if got := search("quick"); !reflect.DeepEqual(got, []string{"1", "2"}) {
+		t.Errorf("after reload: search(quick) = %v, want [1 2]", got)
+	}
+
+	// Updateable analyzers cannot be used at index time
+	if err := idx.PutMapping(Mapping{Fields: map[string]*FieldMapping{
+		"title": {Type: FieldText, Analyzer: "search_synonyms"},
+	}}); err == nil {
+		t.Error("expected an error mapping an updateable analyzer for indexing")
+	}
+	if err := idx.UpdateAnalysis(AnalysisSettings{Analyzer: "english"}); err == nil {
+		t.Error("expected an error changing the default analyzer of a non-empty index")
+	}
+}
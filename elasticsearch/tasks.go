@@ -0,0 +1,384 @@
+package hamfts
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TaskStatus is the state of a background task
+type TaskStatus string
+
+const (
+	TaskRunning   TaskStatus = "running"
+	TaskCompleted TaskStatus = "completed"
+	TaskCancelled TaskStatus = "cancelled"
+	TaskFailed    TaskStatus = "failed"
+)
+
+// TaskProgress counts the documents a task went through
+type TaskProgress struct {
+	Total   int `json:"total"`
+	Done    int `json:"done"`
+	Deleted int `json:"deleted,omitempty"`
+	Updated int `json:"updated,omitempty"`
+	Noops   int `json:"noops,omitempty"`
+	Indexed int `json:"indexed,omitempty"`
+	// VersionConflicts counts documents left alone by a by-query task
+	// because they changed and no longer matched
+	VersionConflicts int `json:"version_conflicts,omitempty"`
+}
+
+// TaskInfo is a snapshot of a background task, as returned by GET
+// /_tasks/{id} and persisted in the tasks directory.
+type TaskInfo struct {
+	ID        string       `json:"id"`
+	Action    string       `json:"action"` // e.g. delete_by_query
+	Status    TaskStatus   `json:"status"`
+	Progress  TaskProgress `json:"progress"`
+	Error     string       `json:"error,omitempty"`
+	StartTime time.Time    `json:"start_time"`
+	EndTime   *time.Time   `json:"end_time,omitempty"`
+}
+
+// Task is a job running in the background. Its progress can be watched with
+// Info and it stops early when cancelled.
+type Task struct {
+	manager *taskManager
+	mu      sync.Mutex
+	info    TaskInfo
+	ctx     context.Context
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// Info returns the current state of the task
+func (t *Task) Info() TaskInfo {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.info
+}
+
+// Cancel asks the task to stop. Work already done is kept.
+func (t *Task) Cancel() {
+	t.cancel()
+}
+
+// Wait blocks until the task has finished and returns its final state
+func (t *Task) Wait() TaskInfo {
+	<-t.done
+	return t.Info()
+}
+
+// add adds the counts of o to p
+func (p *TaskProgress) add(o TaskProgress) {
+	p.Total += o.Total
+	p.Done += o.Done
+	p.Deleted += o.Deleted
+	p.Updated += o.Updated
+	p.Noops += o.Noops
+	p.Indexed += o.Indexed
+	p.VersionConflicts += o.VersionConflicts
+}
+
+func (t *Task) update(fn func(p *TaskProgress)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	fn(&t.info.Progress)
+}
+
+// finish records the outcome of the task and persists it
+func (t *Task) finish(err error) {
+	t.mu.Lock()
+	now := time.Now()
+	t.info.EndTime = &now
+	switch {
+	case err != nil:
+		t.info.Status = TaskFailed
+		t.info.Error = err.Error()
+	case t.ctx.Err() != nil:
+		t.info.Status = TaskCancelled
+	default:
+		t.info.Status = TaskCompleted
+	}
+	info := t.info
+	t.mu.Unlock()
+
+	// A task whose result cannot be saved is still finished in memory;
+	// otherwise the file answers for it from now on
+	if err := t.manager.save(info); err == nil {
+		t.manager.forget(info.ID)
+		t.manager.prune()
+	}
+	close(t.done)
+}
+
+// maxFinishedTasks is how many finished tasks are kept in the task
+// directory before the oldest are removed
+const maxFinishedTasks = 1000
+
+// taskManager runs background tasks. Running tasks live in memory; every
+// task is also written to dir as <id>.json when it starts and when it
+// finishes, so results survive a restart. Only the newest keep finished
+// tasks are kept.
+type taskManager struct {
+	dir   string
+	keep  int
+	mu    sync.Mutex
+	tasks map[string]*Task
+	wg    sync.WaitGroup
+}
+
+// newTaskManager opens the task directory. Tasks persisted as running were
+// interrupted by a shutdown and are marked as failed.
+func newTaskManager(dir string) (*taskManager, error) {
+	m := &taskManager{dir: dir, keep: maxFinishedTasks, tasks: make(map[string]*Task)}
+	infos, err := m.load()
+	if err != nil {
+		return nil, err
+	}
+	for _, info := range infos {
+		if info.Status != TaskRunning {
+			continue
+		}
+		info.Status = TaskFailed
+		info.Error = "interrupted: the index was closed before the task finished"
+		if err := m.save(info); err != nil {
+			return nil, err
+		}
+	}
+	m.prune()
+	return m, nil
+}
+
+func (m *taskManager) path(id string) string {
+	return filepath.Join(m.dir, id+".json")
+}
+
+// save writes info through a temporary file, so readers never see a partly
+// written task
+func (m *taskManager) save(info TaskInfo) error {
+	data, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+	tmp := m.path(info.ID) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, m.path(info.ID))
+}
+
+// load reads every persisted task
+func (m *taskManager) load() ([]TaskInfo, error) {
+	entries, err := os.ReadDir(m.dir)
+	if err != nil {
+		return nil, err
+	}
+	var infos []TaskInfo
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok {
+			continue
+		}
+		info, err := m.read(id)
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+func (m *taskManager) read(id string) (TaskInfo, error) {
+	var info TaskInfo
+	if !validTaskID(id) {
+		return info, fmt.Errorf("task %q: %w", id, ErrNotFound)
+	}
+	data, err := os.ReadFile(m.path(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return info, fmt.Errorf("task %q: %w", id, ErrNotFound)
+		}
+		return info, err
+	}
+	if err := json.Unmarshal(data, &info); err != nil {
+		return info, fmt.Errorf("%w: task %q: %v", ErrCorrupt, id, err)
+	}
+	return info, nil
+}
+
+// start runs fn in the background as a new task. fn returns nil when it
+// stops early because the task was cancelled.
+func (m *taskManager) start(action string, fn func(t *Task) error) (*Task, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t := &Task{
+		manager: m,
+		info: TaskInfo{
+			ID:        newTaskID(),
+			Action:    action,
+			Status:    TaskRunning,
+			StartTime: time.Now(),
+		},
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	// The task is known before its file exists, so prune never takes it
+	// for a finished one
+	m.mu.Lock()
+	m.tasks[t.info.ID] = t
+	m.mu.Unlock()
+	if err := m.save(t.info); err != nil {
+		m.forget(t.info.ID)
+		cancel()
+		return nil, err
+	}
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		defer cancel()
+		t.finish(fn(t))
+	}()
+	return t, nil
+}
+
+// forget drops a finished task from memory
+func (m *taskManager) forget(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.tasks, id)
+}
+
+// prune removes the files of the oldest finished tasks beyond keep, by
+// when they finished. Errors are ignored; the next prune tries again.
+func (m *taskManager) prune() {
+	entries, err := os.ReadDir(m.dir)
+	if err != nil {
+		return
+	}
+	var finished []TaskInfo
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok {
+			continue
+		}
+		if _, running := m.started(id); running {
+			continue
+		}
+		info, err := m.read(id)
+		if err != nil || info.Status == TaskRunning {
+			continue
+		}
+		finished = append(finished, info)
+	}
+	if len(finished) <= m.keep {
+		return
+	}
+	sort.Slice(finished, func(i, j int) bool {
+		return endTime(finished[i]).Before(endTime(finished[j]))
+	})
+	for _, info := range finished[:len(finished)-m.keep] {
+		os.Remove(m.path(info.ID))
+	}
+}
+
+// endTime is when a finished task ended. Tasks marked as failed after a
+// shutdown have no end time and count as ending when they started.
+func endTime(info TaskInfo) time.Time {
+	if info.EndTime != nil {
+		return *info.EndTime
+	}
+	return info.StartTime
+}
+
+// started returns a task that is still running, or whose result could not
+// be saved
+func (m *taskManager) started(id string) (*Task, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, ok := m.tasks[id]
+	return t, ok
+}
+
+func (m *taskManager) info(id string) (TaskInfo, error) {
+	if t, ok := m.started(id); ok {
+		return t.Info(), nil
+	}
+	return m.read(id)
+}
+
+// cancel cancels a running task; a finished one only has to exist
+func (m *taskManager) cancel(id string) error {
+	if t, ok := m.started(id); ok {
+		t.Cancel()
+		return nil
+	}
+	_, err := m.read(id)
+	return err
+}
+
+// list returns every known task, newest first
+func (m *taskManager) list() ([]TaskInfo, error) {
+	infos, err := m.load()
+	if err != nil {
+		return nil, err
+	}
+	for i, info := range infos {
+		if t, ok := m.started(info.ID); ok {
+			infos[i] = t.Info()
+		}
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].StartTime.After(infos[j].StartTime)
+	})
+	return infos, nil
+}
+
+// stop cancels every running task and waits for them to return
+func (m *taskManager) stop() {
+	m.mu.Lock()
+	for _, t := range m.tasks {
+		t.cancel()
+	}
+	m.mu.Unlock()
+	m.wg.Wait()
+}
+
+func newTaskID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// validTaskID keeps IDs from the API from naming files outside the task
+// directory
+func validTaskID(id string) bool {
+	_, err := hex.DecodeString(id)
+	return err == nil && id != ""
+}
+
+// Task returns the state of a running or finished task. Only the newest
+// finished tasks are kept.
+func (idx *Index) Task(id string) (TaskInfo, error) {
+	return idx.tasks.info(id)
+}
+
+// Tasks returns every running and finished task, newest first
+func (idx *Index) Tasks() ([]TaskInfo, error) {
+	return idx.tasks.list()
+}
+
+// CancelTask cancels a running task. Cancelling a finished task does nothing.
+func (idx *Index) CancelTask(id string) error {
+	return idx.tasks.cancel(id)
+}
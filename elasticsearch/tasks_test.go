@@ -0,0 +1,131 @@
+package hamfts
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTasksPersist(t *testing.T) {
+	testDir, err := os.MkdirTemp("", "hamfts_test_tasks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+
+	idx, err := NewIndex(testDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		if err := idx.AddDocument(NewDocument(fmt.Sprintf("doc%d", i), fmt.Sprintf("text %d", i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	task, err := idx.DeleteByQuery(Query{Match: map[string]string{"content": "3"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	deleted := task.Wait()
+
+	task, err = idx.StartCompact()
+	if err != nil {
+		t.Fatal(err)
+	}
+	compacted := task.Wait()
+	if compacted.Status != TaskCompleted || compacted.Progress.Done != 9 {
+		t.Errorf("compact: got %+v", compacted)
+	}
+	if doc, err := idx.GetDocument("doc5"); err != nil || doc.ID != "doc5" {
+		t.Errorf("after compact: got %v, %v", doc, err)
+	}
+	idx.Close()
+
+	// A task that was running when the index went away
+	interrupted := `{"id":"00ff","action":"compact","status":"running","start_time":"2024-01-01T00:00:00Z"}`
+	if err := os.WriteFile(filepath.Join(testDir, "tasks", "00ff.json"), []byte(interrupted), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	idx, err = NewIndex(testDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+
+	info, err := idx.Task(deleted.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Status != TaskCompleted || info.Progress != deleted.Progress || info.EndTime == nil {
+		t.Errorf("persisted task: got %+v, want %+v", info, deleted)
+	}
+	if info, err := idx.Task("00ff"); err != nil || info.Status != TaskFailed {
+		t.Errorf("interrupted task: got %+v, %v", info, err)
+	}
+
+	tasks, err := idx.Tasks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 3 || tasks[0].ID != compacted.ID || tasks[2].ID != "00ff" {
+		t.Errorf("expected 3 tasks newest first, got %+v", tasks)
+	}
+
+	// Cancelling a finished task is fine, unknown or malformed IDs are not
+	if err := idx.CancelTask(deleted.ID); err != nil {
+		t.Error(err)
+	}
+	for _, id := range []string{"abcd", "../metadata"} {
+		if err := idx.CancelTask(id); !errors.Is(err, ErrNotFound) {
+			t.Errorf("cancel %q: got %v, want ErrNotFound", id, err)
+		}
+	}
+}
+
+func TestTaskRetention(t *testing.T) {
+	testDir, err := os.MkdirTemp("", "hamfts_test_task_retention")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+
+	idx, err := NewIndex(testDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+	idx.tasks.keep = 2
+
+	var ids []string
+	for i := 0; i < 4; i++ {
+		task, err := idx.StartCompact()
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, task.Wait().ID)
+	}
+
+	// Finished tasks are answered from their files, not kept in memory
+	if n := len(idx.tasks.tasks); n != 0 {
+		t.Errorf("expected no tasks in memory, got %d", n)
+	}
+	tasks, err := idx.Tasks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 2 || tasks[0].ID != ids[3] || tasks[1].ID != ids[2] {
+		t.Errorf("expected the 2 newest tasks, got %+v", tasks)
+	}
+	for _, id := range ids[:2] {
+		if _, err := idx.Task(id); !errors.Is(err, ErrNotFound) {
+			t.Errorf("task %s: got %v, want ErrNotFound", id, err)
+		}
+	}
+	if info, err := idx.Task(ids[3]); err != nil || info.Status != TaskCompleted {
+		t.Errorf("newest task: got %+v, %v", info, err)
+	}
+}
@@ -0,0 +1,309 @@
+package hamfts
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Word break classes from Unicode Standard Annex #29
+type wordBreak int
+
+const (
+	wbOther wordBreak = iota
+	wbALetter
+	wbHebrew
+	wbNumeric
+	wbKatakana
+	wbIdeographic // Han and Hiragana, every character is a word of its own
+	wbExtendNumLet
+	wbMidLetter
+	wbMidNum
+	wbMidNumLet
+	wbSingleQuote
+	wbExtend
+)
+
+func wordBreakClass(r rune) wordBreak {
+	switch r {
+	case '\'':
+		return wbSingleQuote
+	case '.', '\u2018', '\u2019', '\u2024', '\uFE52', '\uFF07', '\uFF0E':
+		return wbMidNumLet
+	case '\u00B7', '\u0387', '\u055F', '\u05F4', '\u2027', '\uFE13', '\uFE55':
+		// The colon is left out on purpose so that "field:value" splits
+		return wbMidLetter
+	case ',', ';', '\u037E', '\u0589', '\u060C', '\u060D', '\u066C', '\u07F8', '\u2044',
+		'\uFE10', '\uFE14', '\uFE50', '\uFE54', '\uFF0C', '\uFF1B':
+		return wbMidNum
+	case '\u202F':
+		return wbExtendNumLet
+	case '\uFF9E', '\uFF9F':
+		return wbExtend
+	case '\u30FC', '\u309B', '\u309C', '\uFF70', '\u3031', '\u3032', '\u3033', '\u3034', '\u3035':
+		return wbKatakana
+	}
+
+	switch {
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc, unicode.Cf):
+		return wbExtend
+	case unicode.Is(unicode.Han, r), unicode.Is(unicode.Hiragana, r):
+		return wbIdeographic
+	case unicode.Is(unicode.Katakana, r):
+		return wbKatakana
+	case unicode.Is(unicode.Hebrew, r) && unicode.IsLetter(r):
+		return wbHebrew
+	case unicode.IsLetter(r):
+		return wbALetter
+	case unicode.Is(unicode.Nd, r):
+		return wbNumeric
+	case unicode.Is(unicode.Pc, r):
+		return wbExtendNumLet
+	}
+	return wbOther
+}
+
+type wbChar struct {
+	class wordBreak
+	start int
+	end   int // end of the character including any attached Extend marks
+}
+
+// unicodeTokenizer splits text into words following the word boundary
+// rules of UAX #29. Han and Hiragana characters become one token each, and
+// runs of punctuation, symbols and whitespace are dropped.
+func unicodeTokenizer(text string) []Token {
+	// WB4: combining marks and format characters stick to what precedes them
+	chars := make([]wbChar, 0, len(text))
+	for i, r := range text {
+		class := wordBreakClass(r)
+		end := i + utf8.RuneLen(r)
+		if class == wbExtend && len(chars) > 0 {
+			chars[len(chars)-1].end = end
+			continue
+		}
+		chars = append(chars, wbChar{class: class, start: i, end: end})
+	}
+
+	var tokens []Token
+	for i := 0; i < len(chars); {
+		j := i + 1
+		for j < len(chars) && joinsWord(chars, j) {
+			j++
+		}
+
+		if class := chars[i].class; wordLike(class) {
+			term := text[chars[i].start:chars[j-1].end]
+			tokens = append(tokens, Token{
+				Term:  term,
+				Start: chars[i].start,
+				End:   chars[j-1].end,
+				Type:  tokenType(term, chars[i:j]),
+			})
+		}
+		i = j
+	}
+	return tokens
+}
+
+// joinsWord reports whether there is no word boundary before chars[i]
+func joinsWord(chars []wbChar, i int) bool {
+	prev, cur := chars[i-1].class, chars[i].class
+	if chars[i-1].end != chars[i].start {
+		return false
+	}
+	letter := func(c wordBreak) bool { return c == wbALetter || c == wbHebrew }
+	next := wbOther
+	if i+1 < len(chars) && chars[i].end == chars[i+1].start {
+		next = chars[i+1].class
+	}
+	before := wbOther
+	if i >= 2 && chars[i-2].end == chars[i-1].start {
+		before = chars[i-2].class
+	}
+
+	switch {
+	case letter(prev) && letter(cur): // WB5
+		return true
+	case letter(prev) && (cur == wbMidLetter || cur == wbMidNumLet || cur == wbSingleQuote) && letter(next): // WB6
+		return true
+	case letter(before) && (prev == wbMidLetter || prev == wbMidNumLet || prev == wbSingleQuote) && letter(cur): // WB7
+		return true
+	case prev == wbHebrew && cur == wbSingleQuote: // WB7a
+		return true
+	case prev == wbNumeric && cur == wbNumeric, letter(prev) && cur == wbNumeric, prev == wbNumeric && letter(cur): // WB8-10
+		return true
+	case prev == wbNumeric && (cur == wbMidNum || cur == wbMidNumLet || cur == wbSingleQuote) && next == wbNumeric: // WB12
+		return true
+	case before == wbNumeric && (prev == wbMidNum || prev == wbMidNumLet || prev == wbSingleQuote) && cur == wbNumeric: // WB11
+		return true
+	case prev == wbKatakana && cur == wbKatakana: // WB13
+		return true
+	case (letter(prev) || prev == wbNumeric || prev == wbKatakana || prev == wbExtendNumLet) && cur == wbExtendNumLet: // WB13a
+		return true
+	case prev == wbExtendNumLet && (letter(cur) || cur == wbNumeric || cur == wbKatakana): // WB13b
+		return true
+	}
+	return false
+}
+
+func wordLike(class wordBreak) bool {
+	switch class {
+	case wbALetter, wbHebrew, wbNumeric, wbKatakana, wbIdeographic:
+		return true
+	}
+	return false
+}
+
+func tokenType(term string, chars []wbChar) string {
+	switch chars[0].class {
+	case wbIdeographic:
+		r, _ := utf8.DecodeRuneInString(term)
+		if unicode.Is(unicode.Hiragana, r) {
+			return TokenHiragana
+		}
+		return TokenIdeographic
+	case wbKatakana:
+		return TokenKatakana
+	}
+
+	hangul, digits := true, true
+	for _, r := range term {
+		if !unicode.Is(unicode.Hangul, r) {
+			hangul = false
+		}
+		if !unicode.IsDigit(r) {
+			digits = false
+		}
+	}
+	switch {
+	case hangul:
+		return TokenHangul
+	case digits:
+		return TokenNumber
+	}
+	return TokenWord
+}
+
+// nfkc applies Unicode compatibility normalization followed by canonical
+// composition, using the tables in unicode_tables.go.
+func nfkc(s string) string {
+	ascii := true
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			ascii = false
+			break
+		}
+	}
+	if ascii {
+		return s
+	}
+
+	out := make([]rune, 0, len(s))
+	for _, r := range s {
+		if mapped, ok := nfkcTable[r]; ok {
+			out = append(out, []rune(mapped)...)
+		} else {
+			out = append(out, r)
+		}
+	}
+
+	composed := out[:0]
+	for _, r := range out {
+		if n := len(composed); n > 0 {
+			if c, ok := composeTable[[2]rune{composed[n-1], r}]; ok {
+				composed[n-1] = c
+				continue
+			}
+		}
+		composed = append(composed, r)
+	}
+	return string(composed)
+}
+
+// asciiFold replaces Latin letters with diacritics by their ASCII spelling
+// and drops combining marks left over from decomposed input.
+func asciiFold(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if r < utf8.RuneSelf {
+			continue
+		}
+		// Only build a new string once something needs folding
+		b.Grow(len(s))
+		b.WriteString(s[:i])
+		for _, r := range s[i:] {
+			if folded, ok := asciiFoldTable[r]; ok {
+				b.WriteString(folded)
+			} else if !unicode.Is(unicode.Mn, r) {
+				b.WriteRune(r)
+			}
+		}
+		return b.String()
+	}
+	return s
+}
+
+var (
+	nfkcFilter         = termFilter(nfkc)
+	asciiFoldingFilter = termFilter(asciiFold)
+)
+
+func isCJKToken(t Token) bool {
+	switch t.Type {
+	case TokenIdeographic, TokenHiragana, TokenKatakana, TokenHangul:
+		return true
+	}
+	return false
+}
+
+// cjkBigramFilter turns runs of adjacent CJK tokens into overlapping
+// character bigrams, so "東京都" is indexed as "東京" and "京都". A CJK
+// character with no CJK neighbour is kept as a single-character token.
+func cjkBigramFilter(tokens []Token) []Token {
+	out := make([]Token, 0, len(tokens))
+	for i := 0; i < len(tokens); {
+		if !isCJKToken(tokens[i]) {
+			out = append(out, tokens[i])
+			i++
+			continue
+		}
+
+		// Gather the characters of a run of touching CJK tokens
+		type cjkRune struct {
+			r          rune
+			start, end int
+		}
+		var run []cjkRune
+		j := i
+		for ; j < len(tokens) && isCJKToken(tokens[j]); j++ {
+			if j > i && tokens[j-1].End != tokens[j].Start {
+				break
+			}
+			offset := tokens[j].Start
+			for _, r := range tokens[j].Term {
+				size := utf8.RuneLen(r)
+				end := offset + size
+				if end > tokens[j].End {
+					end = tokens[j].End
+				}
+				run = append(run, cjkRune{r: r, start: offset, end: end})
+				offset = end
+			}
+		}
+
+		if len(run) == 1 {
+			out = append(out, Token{Term: string(run[0].r), Start: run[0].start, End: run[0].end, Type: tokens[i].Type})
+		}
+		for k := 0; k+1 < len(run); k++ {
+			out = append(out, Token{
+				Term:  string([]rune{run[k].r, run[k+1].r}),
+				Start: run[k].start,
+				End:   run[k+1].end,
+				Type:  tokens[i].Type,
+			})
+		}
+		i = j
+	}
+	return out
+}
@@ -0,0 +1,856 @@
+package hamfts
+
+// Tables derived from the Unicode 14.0.0 character database. They cover the
+// scripts and compatibility blocks that matter for search rather than the
+// whole of Unicode.
+
+// nfkcTable maps a code point to its NFKC form: full- and half-width
+// forms, ligatures, circled and parenthesized characters, CJK compatibility
+// characters, super- and subscripts and compatibility spaces.
+var nfkcTable = map[rune]string{
+	0x00A0: " ", 0x00A8: " \u0308", 0x00AA: "a", 0x00AF: " \u0304",
+	0x00B2: "2", 0x00B3: "3", 0x00B4: " \u0301", 0x00B5: "\u03BC",
+	0x00B8: " \u0327", 0x00B9: "1", 0x00BA: "o", 0x00BC: "1\u20444",
+	0x00BD: "1\u20442", 0x00BE: "3\u20444", 0x0132: "IJ", 0x0133: "ij",
+	0x013F: "L\u00B7", 0x0140: "l\u00B7", 0x0149: "\u02BCn", 0x017F: "s",
+	0x01C4: "D\u017D", 0x01C5: "D\u017E", 0x01C6: "d\u017E", 0x01C7: "LJ",
+	0x01C8: "Lj", 0x01C9: "lj", 0x01CA: "NJ", 0x01CB: "Nj",
+	0x01CC: "nj", 0x01F1: "DZ", 0x01F2: "Dz", 0x01F3: "dz",
+	0x02B0: "h", 0x02B1: "\u0266", 0x02B2: "j", 0x02B3: "r",
+	0x02B4: "\u0279", 0x02B5: "\u027B", 0x02B6: "\u0281", 0x02B7: "w",
+	0x02B8: "y", 0x02D8: " \u0306", 0x02D9: " \u0307", 0x02DA: " \u030A",
+	0x02DB: " \u0328", 0x02DC: " \u0303", 0x02DD: " \u030B", 0x02E0: "\u0263",
+	0x02E1: "l", 0x02E2: "s", 0x02E3: "x", 0x02E4: "\u0295",
+	0x0374: "\u02B9", 0x037A: " \u0345", 0x037E: ";", 0x0384: " \u0301",
+	0x0385: " \u0308\u0301", 0x0387: "\u00B7", 0x03D0: "\u03B2", 0x03D1: "\u03B8",
+	0x03D2: "\u03A5", 0x03D3: "\u038E", 0x03D4: "\u03AB", 0x03D5: "\u03C6",
+	0x03D6: "\u03C0", 0x03F0: "\u03BA", 0x03F1: "\u03C1", 0x03F2: "\u03C2",
+	0x03F4: "\u0398", 0x03F5: "\u03B5", 0x03F9: "\u03A3", 0x1D2C: "A",
+	0x1D2D: "\u00C6", 0x1D2E: "B", 0x1D30: "D", 0x1D31: "E",
+	0x1D32: "\u018E", 0x1D33: "G", 0x1D34: "H", 0x1D35: "I",
+	0x1D36: "J", 0x1D37: "K", 0x1D38: "L", 0x1D39: "M",
+	0x1D3A: "N", 0x1D3C: "O", 0x1D3D: "\u0222", 0x1D3E: "P",
+	0x1D3F: "R", 0x1D40: "T", 0x1D41: "U", 0x1D42: "W",
+	0x1D43: "a", 0x1D44: "\u0250", 0x1D45: "\u0251", 0x1D46: "\u1D02",
+	0x1D47: "b", 0x1D48: "d", 0x1D49: "e", 0x1D4A: "\u0259",
+	0x1D4B: "\u025B", 0x1D4C: "\u025C", 0x1D4D: "g", 0x1D4F: "k",
+	0x1D50: "m", 0x1D51: "\u014B", 0x1D52: "o", 0x1D53: "\u0254",
+	0x1D54: "\u1D16", 0x1D55: "\u1D17", 0x1D56: "p", 0x1D57: "t",
+	0x1D58: "u", 0x1D59: "\u1D1D", 0x1D5A: "\u026F", 0x1D5B: "v",
+	0x1D5C: "\u1D25", 0x1D5D: "\u03B2", 0x1D5E: "\u03B3", 0x1D5F: "\u03B4",
+	0x1D60: "\u03C6", 0x1D61: "\u03C7", 0x1D62: "i", 0x1D63: "r",
+	0x1D64: "u", 0x1D65: "v", 0x1D66: "\u03B2", 0x1D67: "\u03B3",
+	0x1D68: "\u03C1", 0x1D69: "\u03C6", 0x1D6A: "\u03C7", 0x1D78: "\u043D",
+	0x1D9B: "\u0252", 0x1D9C: "c", 0x1D9D: "\u0255", 0x1D9E: "\u00F0",
+	0x1D9F: "\u025C", 0x1DA0: "f", 0x1DA1: "\u025F", 0x1DA2: "\u0261",
+	0x1DA3: "\u0265", 0x1DA4: "\u0268", 0x1DA5: "\u0269", 0x1DA6: "\u026A",
+	0x1DA7: "\u1D7B", 0x1DA8: "\u029D", 0x1DA9: "\u026D", 0x1DAA: "\u1D85",
+	0x1DAB: "\u029F", 0x1DAC: "\u0271", 0x1DAD: "\u0270", 0x1DAE: "\u0272",
+	0x1DAF: "\u0273", 0x1DB0: "\u0274", 0x1DB1: "\u0275", 0x1DB2: "\u0278",
+	0x1DB3: "\u0282", 0x1DB4: "\u0283", 0x1DB5: "\u01AB", 0x1DB6: "\u0289",
+	0x1DB7: "\u028A", 0x1DB8: "\u1D1C", 0x1DB9: "\u028B", 0x1DBA: "\u028C",
+	0x1DBB: "z", 0x1DBC: "\u0290", 0x1DBD: "\u0291", 0x1DBE: "\u0292",
+	0x1DBF: "\u03B8", 0x2000: " ", 0x2001: " ", 0x2002: " ",
+	0x2003: " ", 0x2004: " ", 0x2005: " ", 0x2006: " ",
+	0x2007: " ", 0x2008: " ", 0x2009: " ", 0x200A: " ",
+	0x2011: "\u2010", 0x2017: " \u0333", 0x2024: ".", 0x2025: "..",
+	0x2026: "...", 0x202F: " ", 0x2033: "\u2032\u2032", 0x2034: "\u2032\u2032\u2032",
+	0x2036: "\u2035\u2035", 0x2037: "\u2035\u2035\u2035", 0x203C: "!!", 0x203E: " \u0305",
+	0x2047: "??", 0x2048: "?!", 0x2049: "!?", 0x2057: "\u2032\u2032\u2032\u2032",
+	0x205F: " ", 0x2070: "0", 0x2071: "i", 0x2074: "4",
+	0x2075: "5", 0x2076: "6", 0x2077: "7", 0x2078: "8",
+	0x2079: "9", 0x207A: "+", 0x207B: "\u2212", 0x207C: "=",
+	0x207D: "(", 0x207E: ")", 0x207F: "n", 0x2080: "0",
+	0x2081: "1", 0x2082: "2", 0x2083: "3", 0x2084: "4",
+	0x2085: "5", 0x2086: "6", 0x2087: "7", 0x2088: "8",
+	0x2089: "9", 0x208A: "+", 0x208B: "\u2212", 0x208C: "=",
+	0x208D: "(", 0x208E: ")", 0x2090: "a", 0x2091: "e",
+	0x2092: "o", 0x2093: "x", 0x2094: "\u0259", 0x2095: "h",
+	0x2096: "k", 0x2097: "l", 0x2098: "m", 0x2099: "n",
+	0x209A: "p", 0x209B: "s", 0x209C: "t", 0x2100: "a/c",
+	0x2101: "a/s", 0x2102: "C", 0x2103: "\u00B0C", 0x2105: "c/o",
+	0x2106: "c/u", 0x2107: "\u0190", 0x2109: "\u00B0F", 0x210A: "g",
+	0x210B: "H", 0x210C: "H", 0x210D: "H", 0x210E: "h",
+	0x210F: "\u0127", 0x2110: "I", 0x2111: "I", 0x2112: "L",
+	0x2113: "l", 0x2115: "N", 0x2116: "No", 0x2119: "P",
+	0x211A: "Q", 0x211B: "R", 0x211C: "R", 0x211D: "R",
+	0x2120: "SM", 0x2121: "TEL", 0x2122: "TM", 0x2124: "Z",
+	0x2126: "\u03A9", 0x2128: "Z", 0x212A: "K", 0x212B: "\u00C5",
+	0x212C: "B", 0x212D: "C", 0x212F: "e", 0x2130: "E",
+	0x2131: "F", 0x2133: "M", 0x2134: "o", 0x2135: "\u05D0",
+	0x2136: "\u05D1", 0x2137: "\u05D2", 0x2138: "\u05D3", 0x2139: "i",
+	0x213B: "FAX", 0x213C: "\u03C0", 0x213D: "\u03B3", 0x213E: "\u0393",
+	0x213F: "\u03A0", 0x2140: "\u2211", 0x2145: "D", 0x2146: "d",
+	0x2147: "e", 0x2148: "i", 0x2149: "j", 0x2150: "1\u20447",
+	0x2151: "1\u20449", 0x2152: "1\u204410", 0x2153: "1\u20443", 0x2154: "2\u20443",
+	0x2155: "1\u20445", 0x2156: "2\u20445", 0x2157: "3\u20445", 0x2158: "4\u20445",
+	0x2159: "1\u20446", 0x215A: "5\u20446", 0x215B: "1\u20448", 0x215C: "3\u20448",
+	0x215D: "5\u20448", 0x215E: "7\u20448", 0x215F: "1\u2044", 0x2160: "I",
+	0x2161: "II", 0x2162: "III", 0x2163: "IV", 0x2164: "V",
+	0x2165: "VI", 0x2166: "VII", 0x2167: "VIII", 0x2168: "IX",
+	0x2169: "X", 0x216A: "XI", 0x216B: "XII", 0x216C: "L",
+	0x216D: "C", 0x216E: "D", 0x216F: "M", 0x2170: "i",
+	0x2171: "ii", 0x2172: "iii", 0x2173: "iv", 0x2174: "v",
+	0x2175: "vi", 0x2176: "vii", 0x2177: "viii", 0x2178: "ix",
+	0x2179: "x", 0x217A: "xi", 0x217B: "xii", 0x217C: "l",
+	0x217D: "c", 0x217E: "d", 0x217F: "m", 0x2189: "0\u20443",
+	0x2460: "1", 0x2461: "2", 0x2462: "3", 0x2463: "4",
+	0x2464: "5", 0x2465: "6", 0x2466: "7", 0x2467: "8",
+	0x2468: "9", 0x2469: "10", 0x246A: "11", 0x246B: "12",
+	0x246C: "13", 0x246D: "14", 0x246E: "15", 0x246F: "16",
+	0x2470: "17", 0x2471: "18", 0x2472: "19", 0x2473: "20",
+	0x2474: "(1)", 0x2475: "(2)", 0x2476: "(3)", 0x2477: "(4)",
+	0x2478: "(5)", 0x2479: "(6)", 0x247A: "(7)", 0x247B: "(8)",
+	0x247C: "(9)", 0x247D: "(10)", 0x247E: "(11)", 0x247F: "(12)",
+	0x2480: "(13)", 0x2481: "(14)", 0x2482: "(15)", 0x2483: "(16)",
+	0x2484: "(17)", 0x2485: "(18)", 0x2486: "(19)", 0x2487: "(20)",
+	0x2488: "1.", 0x2489: "2.", 0x248A: "3.", 0x248B: "4.",
+	0x248C: "5.", 0x248D: "6.", 0x248E: "7.", 0x248F: "8.",
+	0x2490: "9.", 0x2491: "10.", 0x2492: "11.", 0x2493: "12.",
+	0x2494: "13.", 0x2495: "14.", 0x2496: "15.", 0x2497: "16.",
+	0x2498: "17.", 0x2499: "18.", 0x249A: "19.", 0x249B: "20.",
+	0x249C: "(a)", 0x249D: "(b)", 0x249E: "(c)", 0x249F: "(d)",
+	0x24A0: "(e)", 0x24A1: "(f)", 0x24A2: "(g)", 0x24A3: "(h)",
+	0x24A4: "(i)", 0x24A5: "(j)", 0x24A6: "(k)", 0x24A7: "(l)",
+	0x24A8: "(m)", 0x24A9: "(n)", 0x24AA: "(o)", 0x24AB: "(p)",
+	0x24AC: "(q)", 0x24AD: "(r)", 0x24AE: "(s)", 0x24AF: "(t)",
+	0x24B0: "(u)", 0x24B1: "(v)", 0x24B2: "(w)", 0x24B3: "(x)",
+	0x24B4: "(y)", 0x24B5: "(z)", 0x24B6: "A", 0x24B7: "B",
+	0x24B8: "C", 0x24B9: "D", 0x24BA: "E", 0x24BB: "F",
+	0x24BC: "G", 0x24BD: "H", 0x24BE: "I", 0x24BF: "J",
+	0x24C0: "K", 0x24C1: "L", 0x24C2: "M", 0x24C3: "N",
+	0x24C4: "O", 0x24C5: "P", 0x24C6: "Q", 0x24C7: "R",
+	0x24C8: "S", 0x24C9: "T", 0x24CA: "U", 0x24CB: "V",
+	0x24CC: "W", 0x24CD: "X", 0x24CE: "Y", 0x24CF: "Z",
+	0x24D0: "a", 0x24D1: "b", 0x24D2: "c", 0x24D3: "d",
+	0x24D4: "e", 0x24D5: "f", 0x24D6: "g", 0x24D7: "h",
+	0x24D8: "i", 0x24D9: "j", 0x24DA: "k", 0x24DB: "l",
+	0x24DC: "m", 0x24DD: "n", 0x24DE: "o", 0x24DF: "p",
+	0x24E0: "q", 0x24E1: "r", 0x24E2: "s", 0x24E3: "t",
+	0x24E4: "u", 0x24E5: "v", 0x24E6: "w", 0x24E7: "x",
+	0x24E8: "y", 0x24E9: "z", 0x24EA: "0", 0x2F00: "\u4E00",
+	0x2F01: "\u4E28", 0x2F02: "\u4E36", 0x2F03: "\u4E3F", 0x2F04: "\u4E59",
+	0x2F05: "\u4E85", 0x2F06: "\u4E8C", 0x2F07: "\u4EA0", 0x2F08: "\u4EBA",
+	0x2F09: "\u513F", 0x2F0A: "\u5165", 0x2F0B: "\u516B", 0x2F0C: "\u5182",
+	0x2F0D: "\u5196", 0x2F0E: "\u51AB", 0x2F0F: "\u51E0", 0x2F10: "\u51F5",
+	0x2F11: "\u5200", 0x2F12: "\u529B", 0x2F13: "\u52F9", 0x2F14: "\u5315",
+	0x2F15: "\u531A", 0x2F16: "\u5338", 0x2F17: "\u5341", 0x2F18: "\u535C",
+	0x2F19: "\u5369", 0x2F1A: "\u5382", 0x2F1B: "\u53B6", 0x2F1C: "\u53C8",
+	0x2F1D: "\u53E3", 0x2F1E: "\u56D7", 0x2F1F: "\u571F", 0x2F20: "\u58EB",
+	0x2F21: "\u5902", 0x2F22: "\u590A", 0x2F23: "\u5915", 0x2F24: "\u5927",
+	0x2F25: "\u5973", 0x2F26: "\u5B50", 0x2F27: "\u5B80", 0x2F28: "\u5BF8",
+	0x2F29: "\u5C0F", 0x2F2A: "\u5C22", 0x2F2B: "\u5C38", 0x2F2C: "\u5C6E",
+	0x2F2D: "\u5C71", 0x2F2E: "\u5DDB", 0x2F2F: "\u5DE5", 0x2F30: "\u5DF1",
+	0x2F31: "\u5DFE", 0x2F32: "\u5E72", 0x2F33: "\u5E7A", 0x2F34: "\u5E7F",
+	0x2F35: "\u5EF4", 0x2F36: "\u5EFE", 0x2F37: "\u5F0B", 0x2F38: "\u5F13",
+	0x2F39: "\u5F50", 0x2F3A: "\u5F61", 0x2F3B: "\u5F73", 0x2F3C: "\u5FC3",
+	0x2F3D: "\u6208", 0x2F3E: "\u6236", 0x2F3F: "\u624B", 0x2F40: "\u652F",
+	0x2F41: "\u6534", 0x2F42: "\u6587", 0x2F43: "\u6597", 0x2F44: "\u65A4",
+	0x2F45: "\u65B9", 0x2F46: "\u65E0", 0x2F47: "\u65E5", 0x2F48: "\u66F0",
+	0x2F49: "\u6708", 0x2F4A: "\u6728", 0x2F4B: "\u6B20", 0x2F4C: "\u6B62",
+	0x2F4D: "\u6B79", 0x2F4E: "\u6BB3", 0x2F4F: "\u6BCB", 0x2F50: "\u6BD4",
+	0x2F51: "\u6BDB", 0x2F52: "\u6C0F", 0x2F53: "\u6C14", 0x2F54: "\u6C34",
+	0x2F55: "\u706B", 0x2F56: "\u722A", 0x2F57: "\u7236", 0x2F58: "\u723B",
+	0x2F59: "\u723F", 0x2F5A: "\u7247", 0x2F5B: "\u7259", 0x2F5C: "\u725B",
+	0x2F5D: "\u72AC", 0x2F5E: "\u7384", 0x2F5F: "\u7389", 0x2F60: "\u74DC",
+	0x2F61: "\u74E6", 0x2F62: "\u7518", 0x2F63: "\u751F", 0x2F64: "\u7528",
+	0x2F65: "\u7530", 0x2F66: "\u758B", 0x2F67: "\u7592", 0x2F68: "\u7676",
+	0x2F69: "\u767D", 0x2F6A: "\u76AE", 0x2F6B: "\u76BF", 0x2F6C: "\u76EE",
+	0x2F6D: "\u77DB", 0x2F6E: "\u77E2", 0x2F6F: "\u77F3", 0x2F70: "\u793A",
+	0x2F71: "\u79B8", 0x2F72: "\u79BE", 0x2F73: "\u7A74", 0x2F74: "\u7ACB",
+	0x2F75: "\u7AF9", 0x2F76: "\u7C73", 0x2F77: "\u7CF8", 0x2F78: "\u7F36",
+	0x2F79: "\u7F51", 0x2F7A: "\u7F8A", 0x2F7B: "\u7FBD", 0x2F7C: "\u8001",
+	0x2F7D: "\u800C", 0x2F7E: "\u8012", 0x2F7F: "\u8033", 0x2F80: "\u807F",
+	0x2F81: "\u8089", 0x2F82: "\u81E3", 0x2F83: "\u81EA", 0x2F84: "\u81F3",
+	0x2F85: "\u81FC", 0x2F86: "\u820C", 0x2F87: "\u821B", 0x2F88: "\u821F",
+	0x2F89: "\u826E", 0x2F8A: "\u8272", 0x2F8B: "\u8278", 0x2F8C: "\u864D",
+	0x2F8D: "\u866B", 0x2F8E: "\u8840", 0x2F8F: "\u884C", 0x2F90: "\u8863",
+	0x2F91: "\u897E", 0x2F92: "\u898B", 0x2F93: "\u89D2", 0x2F94: "\u8A00",
+	0x2F95: "\u8C37", 0x2F96: "\u8C46", 0x2F97: "\u8C55", 0x2F98: "\u8C78",
+	0x2F99: "\u8C9D", 0x2F9A: "\u8D64", 0x2F9B: "\u8D70", 0x2F9C: "\u8DB3",
+	0x2F9D: "\u8EAB", 0x2F9E: "\u8ECA", 0x2F9F: "\u8F9B", 0x2FA0: "\u8FB0",
+	0x2FA1: "\u8FB5", 0x2FA2: "\u9091", 0x2FA3: "\u9149", 0x2FA4: "\u91C6",
+	0x2FA5: "\u91CC", 0x2FA6: "\u91D1", 0x2FA7: "\u9577", 0x2FA8: "\u9580",
+	0x2FA9: "\u961C", 0x2FAA: "\u96B6", 0x2FAB: "\u96B9", 0x2FAC: "\u96E8",
+	0x2FAD: "\u9751", 0x2FAE: "\u975E", 0x2FAF: "\u9762", 0x2FB0: "\u9769",
+	0x2FB1: "\u97CB", 0x2FB2: "\u97ED", 0x2FB3: "\u97F3", 0x2FB4: "\u9801",
+	0x2FB5: "\u98A8", 0x2FB6: "\u98DB", 0x2FB7: "\u98DF", 0x2FB8: "\u9996",
+	0x2FB9: "\u9999", 0x2FBA: "\u99AC", 0x2FBB: "\u9AA8", 0x2FBC: "\u9AD8",
+	0x2FBD: "\u9ADF", 0x2FBE: "\u9B25", 0x2FBF: "\u9B2F", 0x2FC0: "\u9B32",
+	0x2FC1: "\u9B3C", 0x2FC2: "\u9B5A", 0x2FC3: "\u9CE5", 0x2FC4: "\u9E75",
+	0x2FC5: "\u9E7F", 0x2FC6: "\u9EA5", 0x2FC7: "\u9EBB", 0x2FC8: "\u9EC3",
+	0x2FC9: "\u9ECD", 0x2FCA: "\u9ED1", 0x2FCB: "\u9EF9", 0x2FCC: "\u9EFD",
+	0x2FCD: "\u9F0E", 0x2FCE: "\u9F13", 0x2FCF: "\u9F20", 0x2FD0: "\u9F3B",
+	0x2FD1: "\u9F4A", 0x2FD2: "\u9F52", 0x2FD3: "\u9F8D", 0x2FD4: "\u9F9C",
+	0x2FD5: "\u9FA0", 0x3000: " ", 0x3036: "\u3012", 0x3038: "\u5341",
+	0x3039: "\u5344", 0x303A: "\u5345", 0x309B: " \u3099", 0x309C: " \u309A",
+	0x309F: "\u3088\u308A", 0x30FF: "\u30B3\u30C8", 0x3131: "\u1100", 0x3132: "\u1101",
+	0x3133: "\u11AA", 0x3134: "\u1102", 0x3135: "\u11AC", 0x3136: "\u11AD",
+	0x3137: "\u1103", 0x3138: "\u1104", 0x3139: "\u1105", 0x313A: "\u11B0",
+	0x313B: "\u11B1", 0x313C: "\u11B2", 0x313D: "\u11B3", 0x313E: "\u11B4",
+	0x313F: "\u11B5", 0x3140: "\u111A", 0x3141: "\u1106", 0x3142: "\u1107",
+	0x3143: "\u1108", 0x3144: "\u1121", 0x3145: "\u1109", 0x3146: "\u110A",
+	0x3147: "\u110B", 0x3148: "\u110C", 0x3149: "\u110D", 0x314A: "\u110E",
+	0x314B: "\u110F", 0x314C: "\u1110", 0x314D: "\u1111", 0x314E: "\u1112",
+	0x314F: "\u1161", 0x3150: "\u1162", 0x3151: "\u1163", 0x3152: "\u1164",
+	0x3153: "\u1165", 0x3154: "\u1166", 0x3155: "\u1167", 0x3156: "\u1168",
+	0x3157: "\u1169", 0x3158: "\u116A", 0x3159: "\u116B", 0x315A: "\u116C",
+	0x315B: "\u116D", 0x315C: "\u116E", 0x315D: "\u116F", 0x315E: "\u1170",
+	0x315F: "\u1171", 0x3160: "\u1172", 0x3161: "\u1173", 0x3162: "\u1174",
+	0x3163: "\u1175", 0x3164: "\u1160", 0x3165: "\u1114", 0x3166: "\u1115",
+	0x3167: "\u11C7", 0x3168: "\u11C8", 0x3169: "\u11CC", 0x316A: "\u11CE",
+	0x316B: "\u11D3", 0x316C: "\u11D7", 0x316D: "\u11D9", 0x316E: "\u111C",
+	0x316F: "\u11DD", 0x3170: "\u11DF", 0x3171: "\u111D", 0x3172: "\u111E",
+	0x3173: "\u1120", 0x3174: "\u1122", 0x3175: "\u1123", 0x3176: "\u1127",
+	0x3177: "\u1129", 0x3178: "\u112B", 0x3179: "\u112C", 0x317A: "\u112D",
+	0x317B: "\u112E", 0x317C: "\u112F", 0x317D: "\u1132", 0x317E: "\u1136",
+	0x317F: "\u1140", 0x3180: "\u1147", 0x3181: "\u114C", 0x3182: "\u11F1",
+	0x3183: "\u11F2", 0x3184: "\u1157", 0x3185: "\u1158", 0x3186: "\u1159",
+	0x3187: "\u1184", 0x3188: "\u1185", 0x3189: "\u1188", 0x318A: "\u1191",
+	0x318B: "\u1192", 0x318C: "\u1194", 0x318D: "\u119E", 0x318E: "\u11A1",
+	0x3200: "(\u1100)", 0x3201: "(\u1102)", 0x3202: "(\u1103)", 0x3203: "(\u1105)",
+	0x3204: "(\u1106)", 0x3205: "(\u1107)", 0x3206: "(\u1109)", 0x3207: "(\u110B)",
+	0x3208: "(\u110C)", 0x3209: "(\u110E)", 0x320A: "(\u110F)", 0x320B: "(\u1110)",
+	0x320C: "(\u1111)", 0x320D: "(\u1112)", 0x320E: "(\uAC00)", 0x320F: "(\uB098)",
+	0x3210: "(\uB2E4)", 0x3211: "(\uB77C)", 0x3212: "(\uB9C8)", 0x3213: "(\uBC14)",
+	0x3214: "(\uC0AC)", 0x3215: "(\uC544)", 0x3216: "(\uC790)", 0x3217: "(\uCC28)",
+	0x3218: "(\uCE74)", 0x3219: "(\uD0C0)", 0x321A: "(\uD30C)", 0x321B: "(\uD558)",
+	0x321C: "(\uC8FC)", 0x321D: "(\uC624\uC804)", 0x321E: "(\uC624\uD6C4)", 0x3220: "(\u4E00)",
+	0x3221: "(\u4E8C)", 0x3222: "(\u4E09)", 0x3223: "(\u56DB)", 0x3224: "(\u4E94)",
+	0x3225: "(\u516D)", 0x3226: "(\u4E03)", 0x3227: "(\u516B)", 0x3228: "(\u4E5D)",
+	0x3229: "(\u5341)", 0x322A: "(\u6708)", 0x322B: "(\u706B)", 0x322C: "(\u6C34)",
+	0x322D: "(\u6728)", 0x322E: "(\u91D1)", 0x322F: "(\u571F)", 0x3230: "(\u65E5)",
+	0x3231: "(\u682A)", 0x3232: "(\u6709)", 0x3233: "(\u793E)", 0x3234: "(\u540D)",
+	0x3235: "(\u7279)", 0x3236: "(\u8CA1)", 0x3237: "(\u795D)", 0x3238: "(\u52B4)",
+	0x3239: "(\u4EE3)", 0x323A: "(\u547C)", 0x323B: "(\u5B66)", 0x323C: "(\u76E3)",
+	0x323D: "(\u4F01)", 0x323E: "(\u8CC7)", 0x323F: "(\u5354)", 0x3240: "(\u796D)",
+	0x3241: "(\u4F11)", 0x3242: "(\u81EA)", 0x3243: "(\u81F3)", 0x3244: "\u554F",
+	0x3245: "\u5E7C", 0x3246: "\u6587", 0x3247: "\u7B8F", 0x3250: "PTE",
+	0x3251: "21", 0x3252: "22", 0x3253: "23", 0x3254: "24",
+	0x3255: "25", 0x3256: "26", 0x3257: "27", 0x3258: "28",
+	0x3259: "29", 0x325A: "30", 0x325B: "31", 0x325C: "32",
+	0x325D: "33", 0x325E: "34", 0x325F: "35", 0x3260: "\u1100",
+	0x3261: "\u1102", 0x3262: "\u1103", 0x3263: "\u1105", 0x3264: "\u1106",
+	0x3265: "\u1107", 0x3266: "\u1109", 0x3267: "\u110B", 0x3268: "\u110C",
+	0x3269: "\u110E", 0x326A: "\u110F", 0x326B: "\u1110", 0x326C: "\u1111",
+	0x326D: "\u1112", 0x326E: "\uAC00", 0x326F: "\uB098", 0x3270: "\uB2E4",
+	0x3271: "\uB77C", 0x3272: "\uB9C8", 0x3273: "\uBC14", 0x3274: "\uC0AC",
+	0x3275: "\uC544", 0x3276: "\uC790", 0x3277: "\uCC28", 0x3278: "\uCE74",
+	0x3279: "\uD0C0", 0x327A: "\uD30C", 0x327B: "\uD558", 0x327C: "\uCC38\uACE0",
+	0x327D: "\uC8FC\uC758", 0x327E: "\uC6B0", 0x3280: "\u4E00", 0x3281: "\u4E8C",
+	0x3282: "\u4E09", 0x3283: "\u56DB", 0x3284: "\u4E94", 0x3285: "\u516D",
+	0x3286: "\u4E03", 0x3287: "\u516B", 0x3288: "\u4E5D", 0x3289: "\u5341",
+	0x328A: "\u6708", 0x328B: "\u706B", 0x328C: "\u6C34", 0x328D: "\u6728",
+	0x328E: "\u91D1", 0x328F: "\u571F", 0x3290: "\u65E5", 0x3291: "\u682A",
+	0x3292: "\u6709", 0x3293: "\u793E", 0x3294: "\u540D", 0x3295: "\u7279",
+	0x3296: "\u8CA1", 0x3297: "\u795D", 0x3298: "\u52B4", 0x3299: "\u79D8",
+	0x329A: "\u7537", 0x329B: "\u5973", 0x329C: "\u9069", 0x329D: "\u512A",
+	0x329E: "\u5370", 0x329F: "\u6CE8", 0x32A0: "\u9805", 0x32A1: "\u4F11",
+	0x32A2: "\u5199", 0x32A3: "\u6B63", 0x32A4: "\u4E0A", 0x32A5: "\u4E2D",
+	0x32A6: "\u4E0B", 0x32A7: "\u5DE6", 0x32A8: "\u53F3", 0x32A9: "\u533B",
+	0x32AA: "\u5B97", 0x32AB: "\u5B66", 0x32AC: "\u76E3", 0x32AD: "\u4F01",
+	0x32AE: "\u8CC7", 0x32AF: "\u5354", 0x32B0: "\u591C", 0x32B1: "36",
+	0x32B2: "37", 0x32B3: "38", 0x32B4: "39", 0x32B5: "40",
+	0x32B6: "41", 0x32B7: "42", 0x32B8: "43", 0x32B9: "44",
+	0x32BA: "45", 0x32BB: "46", 0x32BC: "47", 0x32BD: "48",
+	0x32BE: "49", 0x32BF: "50", 0x32C0: "1\u6708", 0x32C1: "2\u6708",
+	0x32C2: "3\u6708", 0x32C3: "4\u6708", 0x32C4: "5\u6708", 0x32C5: "6\u6708",
+	0x32C6: "7\u6708", 0x32C7: "8\u6708", 0x32C8: "9\u6708", 0x32C9: "10\u6708",
+	0x32CA: "11\u6708", 0x32CB: "12\u6708", 0x32CC: "Hg", 0x32CD: "erg",
+	0x32CE: "eV", 0x32CF: "LTD", 0x32D0: "\u30A2", 0x32D1: "\u30A4",
+	0x32D2: "\u30A6", 0x32D3: "\u30A8", 0x32D4: "\u30AA", 0x32D5: "\u30AB",
+	0x32D6: "\u30AD", 0x32D7: "\u30AF", 0x32D8: "\u30B1", 0x32D9: "\u30B3",
+	0x32DA: "\u30B5", 0x32DB: "\u30B7", 0x32DC: "\u30B9", 0x32DD: "\u30BB",
+	0x32DE: "\u30BD", 0x32DF: "\u30BF", 0x32E0: "\u30C1", 0x32E1: "\u30C4",
+	0x32E2: "\u30C6", 0x32E3: "\u30C8", 0x32E4: "\u30CA", 0x32E5: "\u30CB",
+	0x32E6: "\u30CC", 0x32E7: "\u30CD", 0x32E8: "\u30CE", 0x32E9: "\u30CF",
+	0x32EA: "\u30D2", 0x32EB: "\u30D5", 0x32EC: "\u30D8", 0x32ED: "\u30DB",
+	0x32EE: "\u30DE", 0x32EF: "\u30DF", 0x32F0: "\u30E0", 0x32F1: "\u30E1",
+	0x32F2: "\u30E2", 0x32F3: "\u30E4", 0x32F4: "\u30E6", 0x32F5: "\u30E8",
+	0x32F6: "\u30E9", 0x32F7: "\u30EA", 0x32F8: "\u30EB", 0x32F9: "\u30EC",
+	0x32FA: "\u30ED", 0x32FB: "\u30EF", 0x32FC: "\u30F0", 0x32FD: "\u30F1",
+	0x32FE: "\u30F2", 0x32FF: "\u4EE4\u548C", 0x3300: "\u30A2\u30D1\u30FC\u30C8", 0x3301: "\u30A2\u30EB\u30D5\u30A1",
+	0x3302: "\u30A2\u30F3\u30DA\u30A2", 0x3303: "\u30A2\u30FC\u30EB", 0x3304: "\u30A4\u30CB\u30F3\u30B0", 0x3305: "\u30A4\u30F3\u30C1",
+	0x3306: "\u30A6\u30A9\u30F3", 0x3307: "\u30A8\u30B9\u30AF\u30FC\u30C9", 0x3308: "\u30A8\u30FC\u30AB\u30FC", 0x3309: "\u30AA\u30F3\u30B9",
+	0x330A: "\u30AA\u30FC\u30E0", 0x330B: "\u30AB\u30A4\u30EA", 0x330C: "\u30AB\u30E9\u30C3\u30C8", 0x330D: "\u30AB\u30ED\u30EA\u30FC",
+	0x330E: "\u30AC\u30ED\u30F3", 0x330F: "\u30AC\u30F3\u30DE", 0x3310: "\u30AE\u30AC", 0x3311: "\u30AE\u30CB\u30FC",
+	0x3312: "\u30AD\u30E5\u30EA\u30FC", 0x3313: "\u30AE\u30EB\u30C0\u30FC", 0x3314: "\u30AD\u30ED", 0x3315: "\u30AD\u30ED\u30B0\u30E9\u30E0",
+	0x3316: "\u30AD\u30ED\u30E1\u30FC\u30C8\u30EB", 0x3317: "\u30AD\u30ED\u30EF\u30C3\u30C8", 0x3318: "\u30B0\u30E9\u30E0", 0x3319: "\u30B0\u30E9\u30E0\u30C8\u30F3",
+	0x331A: "\u30AF\u30EB\u30BC\u30A4\u30ED", 0x331B: "\u30AF\u30ED\u30FC\u30CD", 0x331C: "\u30B1\u30FC\u30B9", 0x331D: "\u30B3\u30EB\u30CA",
+	0x331E: "\u30B3\u30FC\u30DD", 0x331F: "\u30B5\u30A4\u30AF\u30EB", 0x3320: "\u30B5\u30F3\u30C1\u30FC\u30E0", 0x3321: "\u30B7\u30EA\u30F3\u30B0",
+	0x3322: "\u30BB\u30F3\u30C1", 0x3323: "\u30BB\u30F3\u30C8", 0x3324: "\u30C0\u30FC\u30B9", 0x3325: "\u30C7\u30B7",
+	0x3326: "\u30C9\u30EB", 0x3327: "\u30C8\u30F3", 0x3328: "\u30CA\u30CE", 0x3329: "\u30CE\u30C3\u30C8",
+	0x332A: "\u30CF\u30A4\u30C4", 0x332B: "\u30D1\u30FC\u30BB\u30F3\u30C8", 0x332C: "\u30D1\u30FC\u30C4", 0x332D: "\u30D0\u30FC\u30EC\u30EB",
+	0x332E: "\u30D4\u30A2\u30B9\u30C8\u30EB", 0x332F: "\u30D4\u30AF\u30EB", 0x3330: "\u30D4\u30B3", 0x3331: "\u30D3\u30EB",
+	0x3332: "\u30D5\u30A1\u30E9\u30C3\u30C9", 0x3333: "\u30D5\u30A3\u30FC\u30C8", 0x3334: "\u30D6\u30C3\u30B7\u30A7\u30EB", 0x3335: "\u30D5\u30E9\u30F3",
+	0x3336: "\u30D8\u30AF\u30BF\u30FC\u30EB", 0x3337: "\u30DA\u30BD", 0x3338: "\u30DA\u30CB\u30D2", 0x3339: "\u30D8\u30EB\u30C4",
+	0x333A: "\u30DA\u30F3\u30B9", 0x333B: "\u30DA\u30FC\u30B8", 0x333C: "\u30D9\u30FC\u30BF", 0x333D: "\u30DD\u30A4\u30F3\u30C8",
+	0x333E: "\u30DC\u30EB\u30C8", 0x333F: "\u30DB\u30F3", 0x3340: "\u30DD\u30F3\u30C9", 0x3341: "\u30DB\u30FC\u30EB",
+	0x3342: "\u30DB\u30FC\u30F3", 0x3343: "\u30DE\u30A4\u30AF\u30ED", 0x3344: "\u30DE\u30A4\u30EB", 0x3345: "\u30DE\u30C3\u30CF",
+	0x3346: "\u30DE\u30EB\u30AF", 0x3347: "\u30DE\u30F3\u30B7\u30E7\u30F3", 0x3348: "\u30DF\u30AF\u30ED\u30F3", 0x3349: "\u30DF\u30EA",
+	0x334A: "\u30DF\u30EA\u30D0\u30FC\u30EB", 0x334B: "\u30E1\u30AC", 0x334C: "\u30E1\u30AC\u30C8\u30F3", 0x334D: "\u30E1\u30FC\u30C8\u30EB",
+	0x334E: "\u30E4\u30FC\u30C9", 0x334F: "\u30E4\u30FC\u30EB", 0x3350: "\u30E6\u30A2\u30F3", 0x3351: "\u30EA\u30C3\u30C8\u30EB",
+	0x3352: "\u30EA\u30E9", 0x3353: "\u30EB\u30D4\u30FC", 0x3354: "\u30EB\u30FC\u30D6\u30EB", 0x3355: "\u30EC\u30E0",
+	0x3356: "\u30EC\u30F3\u30C8\u30B2\u30F3", 0x3357: "\u30EF\u30C3\u30C8", 0x3358: "0\u70B9", 0x3359: "1\u70B9",
+	0x335A: "2\u70B9", 0x335B: "3\u70B9", 0x335C: "4\u70B9", 0x335D: "5\u70B9",
+	0x335E: "6\u70B9", 0x335F: "7\u70B9", 0x3360: "8\u70B9", 0x3361: "9\u70B9",
+	0x3362: "10\u70B9", 0x3363: "11\u70B9", 0x3364: "12\u70B9", 0x3365: "13\u70B9",
+	0x3366: "14\u70B9", 0x3367: "15\u70B9", 0x3368: "16\u70B9", 0x3369: "17\u70B9",
+	0x336A: "18\u70B9", 0x336B: "19\u70B9", 0x336C: "20\u70B9", 0x336D: "21\u70B9",
+	0x336E: "22\u70B9", 0x336F: "23\u70B9", 0x3370: "24\u70B9", 0x3371: "hPa",
+	0x3372: "da", 0x3373: "AU", 0x3374: "bar", 0x3375: "oV",
+	0x3376: "pc", 0x3377: "dm", 0x3378: "dm2", 0x3379: "dm3",
+	0x337A: "IU", 0x337B: "\u5E73\u6210", 0x337C: "\u662D\u548C", 0x337D: "\u5927\u6B63",
+	0x337E: "\u660E\u6CBB", 0x337F: "\u682A\u5F0F\u4F1A\u793E", 0x3380: "pA", 0x3381: "nA",
+	0x3382: "\u03BCA", 0x3383: "mA", 0x3384: "kA", 0x3385: "KB",
+	0x3386: "MB", 0x3387: "GB", 0x3388: "cal", 0x3389: "kcal",
+	0x338A: "pF", 0x338B: "nF", 0x338C: "\u03BCF", 0x338D: "\u03BCg",
+	0x338E: "mg", 0x338F: "kg", 0x3390: "Hz", 0x3391: "kHz",
+	0x3392: "MHz", 0x3393: "GHz", 0x3394: "THz", 0x3395: "\u03BCl",
+	0x3396: "ml", 0x3397: "dl", 0x3398: "kl", 0x3399: "fm",
+	0x339A: "nm", 0x339B: "\u03BCm", 0x339C: "mm", 0x339D: "cm",
+	0x339E: "km", 0x339F: "mm2", 0x33A0: "cm2", 0x33A1: "m2",
+	0x33A2: "km2", 0x33A3: "mm3", 0x33A4: "cm3", 0x33A5: "m3",
+	0x33A6: "km3", 0x33A7: "m\u2215s", 0x33A8: "m\u2215s2", 0x33A9: "Pa",
+	0x33AA: "kPa", 0x33AB: "MPa", 0x33AC: "GPa", 0x33AD: "rad",
+	0x33AE: "rad\u2215s", 0x33AF: "rad\u2215s2", 0x33B0: "ps", 0x33B1: "ns",
+	0x33B2: "\u03BCs", 0x33B3: "ms", 0x33B4: "pV", 0x33B5: "nV",
+	0x33B6: "\u03BCV", 0x33B7: "mV", 0x33B8: "kV", 0x33B9: "MV",
+	0x33BA: "pW", 0x33BB: "nW", 0x33BC: "\u03BCW", 0x33BD: "mW",
+	0x33BE: "kW", 0x33BF: "MW", 0x33C0: "k\u03A9", 0x33C1: "M\u03A9",
+	0x33C2: "a.m.", 0x33C3: "Bq", 0x33C4: "cc", 0x33C5: "cd",
+	0x33C6: "C\u2215kg", 0x33C7: "Co.", 0x33C8: "dB", 0x33C9: "Gy",
+	0x33CA: "ha", 0x33CB: "HP", 0x33CC: "in", 0x33CD: "KK",
+	0x33CE: "KM", 0x33CF: "kt", 0x33D0: "lm", 0x33D1: "ln",
+	0x33D2: "log", 0x33D3: "lx", 0x33D4: "mb", 0x33D5: "mil",
+	0x33D6: "mol", 0x33D7: "PH", 0x33D8: "p.m.", 0x33D9: "PPM",
+	0x33DA: "PR", 0x33DB: "sr", 0x33DC: "Sv", 0x33DD: "Wb",
+	0x33DE: "V\u2215m", 0x33DF: "A\u2215m", 0x33E0: "1\u65E5", 0x33E1: "2\u65E5",
+	0x33E2: "3\u65E5", 0x33E3: "4\u65E5", 0x33E4: "5\u65E5", 0x33E5: "6\u65E5",
+	0x33E6: "7\u65E5", 0x33E7: "8\u65E5", 0x33E8: "9\u65E5", 0x33E9: "10\u65E5",
+	0x33EA: "11\u65E5", 0x33EB: "12\u65E5", 0x33EC: "13\u65E5", 0x33ED: "14\u65E5",
+	0x33EE: "15\u65E5", 0x33EF: "16\u65E5", 0x33F0: "17\u65E5", 0x33F1: "18\u65E5",
+	0x33F2: "19\u65E5", 0x33F3: "20\u65E5", 0x33F4: "21\u65E5", 0x33F5: "22\u65E5",
+	0x33F6: "23\u65E5", 0x33F7: "24\u65E5", 0x33F8: "25\u65E5", 0x33F9: "26\u65E5",
+	0x33FA: "27\u65E5", 0x33FB: "28\u65E5", 0x33FC: "29\u65E5", 0x33FD: "30\u65E5",
+	0x33FE: "31\u65E5", 0x33FF: "gal", 0xF900: "\u8C48", 0xF901: "\u66F4",
+	0xF902: "\u8ECA", 0xF903: "\u8CC8", 0xF904: "\u6ED1", 0xF905: "\u4E32",
+	0xF906: "\u53E5", 0xF907: "\u9F9C", 0xF908: "\u9F9C", 0xF909: "\u5951",
+	0xF90A: "\u91D1", 0xF90B: "\u5587", 0xF90C: "\u5948", 0xF90D: "\u61F6",
+	0xF90E: "\u7669", 0xF90F: "\u7F85", 0xF910: "\u863F", 0xF911: "\u87BA",
+	0xF912: "\u88F8", 0xF913: "\u908F", 0xF914: "\u6A02", 0xF915: "\u6D1B",
+	0xF916: "\u70D9", 0xF917: "\u73DE", 0xF918: "\u843D", 0xF919: "\u916A",
+	0xF91A: "\u99F1", 0xF91B: "\u4E82", 0xF91C: "\u5375", 0xF91D: "\u6B04",
+	0xF91E: "\u721B", 0xF91F: "\u862D", 0xF920: "\u9E1E", 0xF921: "\u5D50",
+	0xF922: "\u6FEB", 0xF923: "\u85CD", 0xF924: "\u8964", 0xF925: "\u62C9",
+	0xF926: "\u81D8", 0xF927: "\u881F", 0xF928: "\u5ECA", 0xF929: "\u6717",
+	0xF92A: "\u6D6A", 0xF92B: "\u72FC", 0xF92C: "\u90CE", 0xF92D: "\u4F86",
+	0xF92E: "\u51B7", 0xF92F: "\u52DE", 0xF930: "\u64C4", 0xF931: "\u6AD3",
+	0xF932: "\u7210", 0xF933: "\u76E7", 0xF934: "\u8001", 0xF935: "\u8606",
+	0xF936: "\u865C", 0xF937: "\u8DEF", 0xF938: "\u9732", 0xF939: "\u9B6F",
+	0xF93A: "\u9DFA", 0xF93B: "\u788C", 0xF93C: "\u797F", 0xF93D: "\u7DA0",
+	0xF93E: "\u83C9", 0xF93F: "\u9304", 0xF940: "\u9E7F", 0xF941: "\u8AD6",
+	0xF942: "\u58DF", 0xF943: "\u5F04", 0xF944: "\u7C60", 0xF945: "\u807E",
+	0xF946: "\u7262", 0xF947: "\u78CA", 0xF948: "\u8CC2", 0xF949: "\u96F7",
+	0xF94A: "\u58D8", 0xF94B: "\u5C62", 0xF94C: "\u6A13", 0xF94D: "\u6DDA",
+	0xF94E: "\u6F0F", 0xF94F: "\u7D2F", 0xF950: "\u7E37", 0xF951: "\u964B",
+	0xF952: "\u52D2", 0xF953: "\u808B", 0xF954: "\u51DC", 0xF955: "\u51CC",
+	0xF956: "\u7A1C", 0xF957: "\u7DBE", 0xF958: "\u83F1", 0xF959: "\u9675",
+	0xF95A: "\u8B80", 0xF95B: "\u62CF", 0xF95C: "\u6A02", 0xF95D: "\u8AFE",
+	0xF95E: "\u4E39", 0xF95F: "\u5BE7", 0xF960: "\u6012", 0xF961: "\u7387",
+	0xF962: "\u7570", 0xF963: "\u5317", 0xF964: "\u78FB", 0xF965: "\u4FBF",
+	0xF966: "\u5FA9", 0xF967: "\u4E0D", 0xF968: "\u6CCC", 0xF969: "\u6578",
+	0xF96A: "\u7D22", 0xF96B: "\u53C3", 0xF96C: "\u585E", 0xF96D: "\u7701",
+	0xF96E: "\u8449", 0xF96F: "\u8AAA", 0xF970: "\u6BBA", 0xF971: "\u8FB0",
+	0xF972: "\u6C88", 0xF973: "\u62FE", 0xF974: "\u82E5", 0xF975: "\u63A0",
+	0xF976: "\u7565", 0xF977: "\u4EAE", 0xF978: "\u5169", 0xF979: "\u51C9",
+	0xF97A: "\u6881", 0xF97B: "\u7CE7", 0xF97C: "\u826F", 0xF97D: "\u8AD2",
+	0xF97E: "\u91CF", 0xF97F: "\u52F5", 0xF980: "\u5442", 0xF981: "\u5973",
+	0xF982: "\u5EEC", 0xF983: "\u65C5", 0xF984: "\u6FFE", 0xF985: "\u792A",
+	0xF986: "\u95AD", 0xF987: "\u9A6A", 0xF988: "\u9E97", 0xF989: "\u9ECE",
+	0xF98A: "\u529B", 0xF98B: "\u66C6", 0xF98C: "\u6B77", 0xF98D: "\u8F62",
+	0xF98E: "\u5E74", 0xF98F: "\u6190", 0xF990: "\u6200", 0xF991: "\u649A",
+	0xF992: "\u6F23", 0xF993: "\u7149", 0xF994: "\u7489", 0xF995: "\u79CA",
+	0xF996: "\u7DF4", 0xF997: "\u806F", 0xF998: "\u8F26", 0xF999: "\u84EE",
+	0xF99A: "\u9023", 0xF99B: "\u934A", 0xF99C: "\u5217", 0xF99D: "\u52A3",
+	0xF99E: "\u54BD", 0xF99F: "\u70C8", 0xF9A0: "\u88C2", 0xF9A1: "\u8AAA",
+	0xF9A2: "\u5EC9", 0xF9A3: "\u5FF5", 0xF9A4: "\u637B", 0xF9A5: "\u6BAE",
+	0xF9A6: "\u7C3E", 0xF9A7: "\u7375", 0xF9A8: "\u4EE4", 0xF9A9: "\u56F9",
+	0xF9AA: "\u5BE7", 0xF9AB: "\u5DBA", 0xF9AC: "\u601C", 0xF9AD: "\u73B2",
+	0xF9AE: "\u7469", 0xF9AF: "\u7F9A", 0xF9B0: "\u8046", 0xF9B1: "\u9234",
+	0xF9B2: "\u96F6", 0xF9B3: "\u9748", 0xF9B4: "\u9818", 0xF9B5: "\u4F8B",
+	0xF9B6: "\u79AE", 0xF9B7: "\u91B4", 0xF9B8: "\u96B8", 0xF9B9: "\u60E1",
+	0xF9BA: "\u4E86", 0xF9BB: "\u50DA", 0xF9BC: "\u5BEE", 0xF9BD: "\u5C3F",
+	0xF9BE: "\u6599", 0xF9BF: "\u6A02", 0xF9C0: "\u71CE", 0xF9C1: "\u7642",
+	0xF9C2: "\u84FC", 0xF9C3: "\u907C", 0xF9C4: "\u9F8D", 0xF9C5: "\u6688",
+	0xF9C6: "\u962E", 0xF9C7: "\u5289", 0xF9C8: "\u677B", 0xF9C9: "\u67F3",
+	0xF9CA: "\u6D41", 0xF9CB: "\u6E9C", 0xF9CC: "\u7409", 0xF9CD: "\u7559",
+	0xF9CE: "\u786B", 0xF9CF: "\u7D10", 0xF9D0: "\u985E", 0xF9D1: "\u516D",
+	0xF9D2: "\u622E", 0xF9D3: "\u9678", 0xF9D4: "\u502B", 0xF9D5: "\u5D19",
+	0xF9D6: "\u6DEA", 0xF9D7: "\u8F2A", 0xF9D8: "\u5F8B", 0xF9D9: "\u6144",
+	0xF9DA: "\u6817", 0xF9DB: "\u7387", 0xF9DC: "\u9686", 0xF9DD: "\u5229",
+	0xF9DE: "\u540F", 0xF9DF: "\u5C65", 0xF9E0: "\u6613", 0xF9E1: "\u674E",
+	0xF9E2: "\u68A8", 0xF9E3: "\u6CE5", 0xF9E4: "\u7406", 0xF9E5: "\u75E2",
+	0xF9E6: "\u7F79", 0xF9E7: "\u88CF", 0xF9E8: "\u88E1", 0xF9E9: "\u91CC",
+	0xF9EA: "\u96E2", 0xF9EB: "\u533F", 0xF9EC: "\u6EBA", 0xF9ED: "\u541D",
+	0xF9EE: "\u71D0", 0xF9EF: "\u7498", 0xF9F0: "\u85FA", 0xF9F1: "\u96A3",
+	0xF9F2: "\u9C57", 0xF9F3: "\u9E9F", 0xF9F4: "\u6797", 0xF9F5: "\u6DCB",
+	0xF9F6: "\u81E8", 0xF9F7: "\u7ACB", 0xF9F8: "\u7B20", 0xF9F9: "\u7C92",
+	0xF9FA: "\u72C0", 0xF9FB: "\u7099", 0xF9FC: "\u8B58", 0xF9FD: "\u4EC0",
+	0xF9FE: "\u8336", 0xF9FF: "\u523A", 0xFA00: "\u5207", 0xFA01: "\u5EA6",
+	0xFA02: "\u62D3", 0xFA03: "\u7CD6", 0xFA04: "\u5B85", 0xFA05: "\u6D1E",
+	0xFA06: "\u66B4", 0xFA07: "\u8F3B", 0xFA08: "\u884C", 0xFA09: "\u964D",
+	0xFA0A: "\u898B", 0xFA0B: "\u5ED3", 0xFA0C: "\u5140", 0xFA0D: "\u55C0",
+	0xFA10: "\u585A", 0xFA12: "\u6674", 0xFA15: "\u51DE", 0xFA16: "\u732A",
+	0xFA17: "\u76CA", 0xFA18: "\u793C", 0xFA19: "\u795E", 0xFA1A: "\u7965",
+	0xFA1B: "\u798F", 0xFA1C: "\u9756", 0xFA1D: "\u7CBE", 0xFA1E: "\u7FBD",
+	0xFA20: "\u8612", 0xFA22: "\u8AF8", 0xFA25: "\u9038", 0xFA26: "\u90FD",
+	0xFA2A: "\u98EF", 0xFA2B: "\u98FC", 0xFA2C: "\u9928", 0xFA2D: "\u9DB4",
+	0xFA2E: "\u90DE", 0xFA2F: "\u96B7", 0xFA30: "\u4FAE", 0xFA31: "\u50E7",
+	0xFA32: "\u514D", 0xFA33: "\u52C9", 0xFA34: "\u52E4", 0xFA35: "\u5351",
+	0xFA36: "\u559D", 0xFA37: "\u5606", 0xFA38: "\u5668", 0xFA39: "\u5840",
+	0xFA3A: "\u58A8", 0xFA3B: "\u5C64", 0xFA3C: "\u5C6E", 0xFA3D: "\u6094",
+	0xFA3E: "\u6168", 0xFA3F: "\u618E", 0xFA40: "\u61F2", 0xFA41: "\u654F",
+	0xFA42: "\u65E2", 0xFA43: "\u6691", 0xFA44: "\u6885", 0xFA45: "\u6D77",
+	0xFA46: "\u6E1A", 0xFA47: "\u6F22", 0xFA48: "\u716E", 0xFA49: "\u722B",
+	0xFA4A: "\u7422", 0xFA4B: "\u7891", 0xFA4C: "\u793E", 0xFA4D: "\u7949",
+	0xFA4E: "\u7948", 0xFA4F: "\u7950", 0xFA50: "\u7956", 0xFA51: "\u795D",
+	0xFA52: "\u798D", 0xFA53: "\u798E", 0xFA54: "\u7A40", 0xFA55: "\u7A81",
+	0xFA56: "\u7BC0", 0xFA57: "\u7DF4", 0xFA58: "\u7E09", 0xFA59: "\u7E41",
+	0xFA5A: "\u7F72", 0xFA5B: "\u8005", 0xFA5C: "\u81ED", 0xFA5D: "\u8279",
+	0xFA5E: "\u8279", 0xFA5F: "\u8457", 0xFA60: "\u8910", 0xFA61: "\u8996",
+	0xFA62: "\u8B01", 0xFA63: "\u8B39", 0xFA64: "\u8CD3", 0xFA65: "\u8D08",
+	0xFA66: "\u8FB6", 0xFA67: "\u9038", 0xFA68: "\u96E3", 0xFA69: "\u97FF",
+	0xFA6A: "\u983B", 0xFA6B: "\u6075", 0xFA6C: "\U000242EE", 0xFA6D: "\u8218",
+	0xFA70: "\u4E26", 0xFA71: "\u51B5", 0xFA72: "\u5168", 0xFA73: "\u4F80",
+	0xFA74: "\u5145", 0xFA75: "\u5180", 0xFA76: "\u52C7", 0xFA77: "\u52FA",
+	0xFA78: "\u559D", 0xFA79: "\u5555", 0xFA7A: "\u5599", 0xFA7B: "\u55E2",
+	0xFA7C: "\u585A", 0xFA7D: "\u58B3", 0xFA7E: "\u5944", 0xFA7F: "\u5954",
+	0xFA80: "\u5A62", 0xFA81: "\u5B28", 0xFA82: "\u5ED2", 0xFA83: "\u5ED9",
+	0xFA84: "\u5F69", 0xFA85: "\u5FAD", 0xFA86: "\u60D8", 0xFA87: "\u614E",
+	0xFA88: "\u6108", 0xFA89: "\u618E", 0xFA8A: "\u6160", 0xFA8B: "\u61F2",
+	0xFA8C: "\u6234", 0xFA8D: "\u63C4", 0xFA8E: "\u641C", 0xFA8F: "\u6452",
+	0xFA90: "\u6556", 0xFA91: "\u6674", 0xFA92: "\u6717", 0xFA93: "\u671B",
+	0xFA94: "\u6756", 0xFA95: "\u6B79", 0xFA96: "\u6BBA", 0xFA97: "\u6D41",
+	0xFA98: "\u6EDB", 0xFA99: "\u6ECB", 0xFA9A: "\u6F22", 0xFA9B: "\u701E",
+	0xFA9C: "\u716E", 0xFA9D: "\u77A7", 0xFA9E: "\u7235", 0xFA9F: "\u72AF",
+	0xFAA0: "\u732A", 0xFAA1: "\u7471", 0xFAA2: "\u7506", 0xFAA3: "\u753B",
+	0xFAA4: "\u761D", 0xFAA5: "\u761F", 0xFAA6: "\u76CA", 0xFAA7: "\u76DB",
+	0xFAA8: "\u76F4", 0xFAA9: "\u774A", 0xFAAA: "\u7740", 0xFAAB: "\u78CC",
+	0xFAAC: "\u7AB1", 0xFAAD: "\u7BC0", 0xFAAE: "\u7C7B", 0xFAAF: "\u7D5B",
+	0xFAB0: "\u7DF4", 0xFAB1: "\u7F3E", 0xFAB2: "\u8005", 0xFAB3: "\u8352",
+	0xFAB4: "\u83EF", 0xFAB5: "\u8779", 0xFAB6: "\u8941", 0xFAB7: "\u8986",
+	0xFAB8: "\u8996", 0xFAB9: "\u8ABF", 0xFABA: "\u8AF8", 0xFABB: "\u8ACB",
+	0xFABC: "\u8B01", 0xFABD: "\u8AFE", 0xFABE: "\u8AED", 0xFABF: "\u8B39",
+	0xFAC0: "\u8B8A", 0xFAC1: "\u8D08", 0xFAC2: "\u8F38", 0xFAC3: "\u9072",
+	0xFAC4: "\u9199", 0xFAC5: "\u9276", 0xFAC6: "\u967C", 0xFAC7: "\u96E3",
+	0xFAC8: "\u9756", 0xFAC9: "\u97DB", 0xFACA: "\u97FF", 0xFACB: "\u980B",
+	0xFACC: "\u983B", 0xFACD: "\u9B12", 0xFACE: "\u9F9C", 0xFACF: "\U0002284A",
+	0xFAD0: "\U00022844", 0xFAD1: "\U000233D5", 0xFAD2: "\u3B9D", 0xFAD3: "\u4018",
+	0xFAD4: "\u4039", 0xFAD5: "\U00025249", 0xFAD6: "\U00025CD0", 0xFAD7: "\U00027ED3",
+	0xFAD8: "\u9F43", 0xFAD9: "\u9F8E", 0xFB00: "ff", 0xFB01: "fi",
+	0xFB02: "fl", 0xFB03: "ffi", 0xFB04: "ffl", 0xFB05: "st",
+	0xFB06: "st", 0xFB13: "\u0574\u0576", 0xFB14: "\u0574\u0565", 0xFB15: "\u0574\u056B",
+	0xFB16: "\u057E\u0576", 0xFB17: "\u0574\u056D", 0xFB1D: "\u05D9\u05B4", 0xFB1F: "\u05F2\u05B7",
+	0xFB20: "\u05E2", 0xFB21: "\u05D0", 0xFB22: "\u05D3", 0xFB23: "\u05D4",
+	0xFB24: "\u05DB", 0xFB25: "\u05DC", 0xFB26: "\u05DD", 0xFB27: "\u05E8",
+	0xFB28: "\u05EA", 0xFB29: "+", 0xFB2A: "\u05E9\u05C1", 0xFB2B: "\u05E9\u05C2",
+	0xFB2C: "\u05E9\u05BC\u05C1", 0xFB2D: "\u05E9\u05BC\u05C2", 0xFB2E: "\u05D0\u05B7", 0xFB2F: "\u05D0\u05B8",
+	0xFB30: "\u05D0\u05BC", 0xFB31: "\u05D1\u05BC", 0xFB32: "\u05D2\u05BC", 0xFB33: "\u05D3\u05BC",
+	0xFB34: "\u05D4\u05BC", 0xFB35: "\u05D5\u05BC", 0xFB36: "\u05D6\u05BC", 0xFB38: "\u05D8\u05BC",
+	0xFB39: "\u05D9\u05BC", 0xFB3A: "\u05DA\u05BC", 0xFB3B: "\u05DB\u05BC", 0xFB3C: "\u05DC\u05BC",
+	0xFB3E: "\u05DE\u05BC", 0xFB40: "\u05E0\u05BC", 0xFB41: "\u05E1\u05BC", 0xFB43: "\u05E3\u05BC",
+	0xFB44: "\u05E4\u05BC", 0xFB46: "\u05E6\u05BC", 0xFB47: "\u05E7\u05BC", 0xFB48: "\u05E8\u05BC",
+	0xFB49: "\u05E9\u05BC", 0xFB4A: "\u05EA\u05BC", 0xFB4B: "\u05D5\u05B9", 0xFB4C: "\u05D1\u05BF",
+	0xFB4D: "\u05DB\u05BF", 0xFB4E: "\u05E4\u05BF", 0xFB4F: "\u05D0\u05DC", 0xFE10: ",",
+	0xFE11: "\u3001", 0xFE12: "\u3002", 0xFE13: ":", 0xFE14: ";",
+	0xFE15: "!", 0xFE16: "?", 0xFE17: "\u3016", 0xFE18: "\u3017",
+	0xFE19: "...", 0xFE30: "..", 0xFE31: "\u2014", 0xFE32: "\u2013",
+	0xFE33: "_", 0xFE34: "_", 0xFE35: "(", 0xFE36: ")",
+	0xFE37: "{", 0xFE38: "}", 0xFE39: "\u3014", 0xFE3A: "\u3015",
+	0xFE3B: "\u3010", 0xFE3C: "\u3011", 0xFE3D: "\u300A", 0xFE3E: "\u300B",
+	0xFE3F: "\u3008", 0xFE40: "\u3009", 0xFE41: "\u300C", 0xFE42: "\u300D",
+	0xFE43: "\u300E", 0xFE44: "\u300F", 0xFE47: "[", 0xFE48: "]",
+	0xFE49: " \u0305", 0xFE4A: " \u0305", 0xFE4B: " \u0305", 0xFE4C: " \u0305",
+	0xFE4D: "_", 0xFE4E: "_", 0xFE4F: "_", 0xFE50: ",",
+	0xFE51: "\u3001", 0xFE52: ".", 0xFE54: ";", 0xFE55: ":",
+	0xFE56: "?", 0xFE57: "!", 0xFE58: "\u2014", 0xFE59: "(",
+	0xFE5A: ")", 0xFE5B: "{", 0xFE5C: "}", 0xFE5D: "\u3014",
+	0xFE5E: "\u3015", 0xFE5F: "#", 0xFE60: "&", 0xFE61: "*",
+	0xFE62: "+", 0xFE63: "-", 0xFE64: "<", 0xFE65: ">",
+	0xFE66: "=", 0xFE68: "\\", 0xFE69: "$", 0xFE6A: "%",
+	0xFE6B: "@", 0xFF01: "!", 0xFF02: "\"", 0xFF03: "#",
+	0xFF04: "$", 0xFF05: "%", 0xFF06: "&", 0xFF07: "'",
+	0xFF08: "(", 0xFF09: ")", 0xFF0A: "*", 0xFF0B: "+",
+	0xFF0C: ",", 0xFF0D: "-", 0xFF0E: ".", 0xFF0F: "/",
+	0xFF10: "0", 0xFF11: "1", 0xFF12: "2", 0xFF13: "3",
+	0xFF14: "4", 0xFF15: "5", 0xFF16: "6", 0xFF17: "7",
+	0xFF18: "8", 0xFF19: "9", 0xFF1A: ":", 0xFF1B: ";",
+	0xFF1C: "<", 0xFF1D: "=", 0xFF1E: ">", 0xFF1F: "?",
+	0xFF20: "@", 0xFF21: "A", 0xFF22: "B", 0xFF23: "C",
+	0xFF24: "D", 0xFF25: "E", 0xFF26: "F", 0xFF27: "G",
+	0xFF28: "H", 0xFF29: "I", 0xFF2A: "J", 0xFF2B: "K",
+	0xFF2C: "L", 0xFF2D: "M", 0xFF2E: "N", 0xFF2F: "O",
+	0xFF30: "P", 0xFF31: "Q", 0xFF32: "R", 0xFF33: "S",
+	0xFF34: "T", 0xFF35: "U", 0xFF36: "V", 0xFF37: "W",
+	0xFF38: "X", 0xFF39: "Y", 0xFF3A: "Z", 0xFF3B: "[",
+	0xFF3C: "\\", 0xFF3D: "]", 0xFF3E: "^", 0xFF3F: "_",
+	0xFF40: "`", 0xFF41: "a", 0xFF42: "b", 0xFF43: "c",
+	0xFF44: "d", 0xFF45: "e", 0xFF46: "f", 0xFF47: "g",
+	0xFF48: "h", 0xFF49: "i", 0xFF4A: "j", 0xFF4B: "k",
+	0xFF4C: "l", 0xFF4D: "m", 0xFF4E: "n", 0xFF4F: "o",
+	0xFF50: "p", 0xFF51: "q", 0xFF52: "r", 0xFF53: "s",
+	0xFF54: "t", 0xFF55: "u", 0xFF56: "v", 0xFF57: "w",
+	0xFF58: "x", 0xFF59: "y", 0xFF5A: "z", 0xFF5B: "{",
+	0xFF5C: "|", 0xFF5D: "}", 0xFF5E: "~", 0xFF5F: "\u2985",
+	0xFF60: "\u2986", 0xFF61: "\u3002", 0xFF62: "\u300C", 0xFF63: "\u300D",
+	0xFF64: "\u3001", 0xFF65: "\u30FB", 0xFF66: "\u30F2", 0xFF67: "\u30A1",
+	0xFF68: "\u30A3", 0xFF69: "\u30A5", 0xFF6A: "\u30A7", 0xFF6B: "\u30A9",
+	0xFF6C: "\u30E3", 0xFF6D: "\u30E5", 0xFF6E: "\u30E7", 0xFF6F: "\u30C3",
+	0xFF70: "\u30FC", 0xFF71: "\u30A2", 0xFF72: "\u30A4", 0xFF73: "\u30A6",
+	0xFF74: "\u30A8", 0xFF75: "\u30AA", 0xFF76: "\u30AB", 0xFF77: "\u30AD",
+	0xFF78: "\u30AF", 0xFF79: "\u30B1", 0xFF7A: "\u30B3", 0xFF7B: "\u30B5",
+	0xFF7C: "\u30B7", 0xFF7D: "\u30B9", 0xFF7E: "\u30BB", 0xFF7F: "\u30BD",
+	0xFF80: "\u30BF", 0xFF81: "\u30C1", 0xFF82: "\u30C4", 0xFF83: "\u30C6",
+	0xFF84: "\u30C8", 0xFF85: "\u30CA", 0xFF86: "\u30CB", 0xFF87: "\u30CC",
+	0xFF88: "\u30CD", 0xFF89: "\u30CE", 0xFF8A: "\u30CF", 0xFF8B: "\u30D2",
+	0xFF8C: "\u30D5", 0xFF8D: "\u30D8", 0xFF8E: "\u30DB", 0xFF8F: "\u30DE",
+	0xFF90: "\u30DF", 0xFF91: "\u30E0", 0xFF92: "\u30E1", 0xFF93: "\u30E2",
+	0xFF94: "\u30E4", 0xFF95: "\u30E6", 0xFF96: "\u30E8", 0xFF97: "\u30E9",
+	0xFF98: "\u30EA", 0xFF99: "\u30EB", 0xFF9A: "\u30EC", 0xFF9B: "\u30ED",
+	0xFF9C: "\u30EF", 0xFF9D: "\u30F3", 0xFF9E: "\u3099", 0xFF9F: "\u309A",
+	0xFFA0: "\u1160", 0xFFA1: "\u1100", 0xFFA2: "\u1101", 0xFFA3: "\u11AA",
+	0xFFA4: "\u1102", 0xFFA5: "\u11AC", 0xFFA6: "\u11AD", 0xFFA7: "\u1103",
+	0xFFA8: "\u1104", 0xFFA9: "\u1105", 0xFFAA: "\u11B0", 0xFFAB: "\u11B1",
+	0xFFAC: "\u11B2", 0xFFAD: "\u11B3", 0xFFAE: "\u11B4", 0xFFAF: "\u11B5",
+	0xFFB0: "\u111A", 0xFFB1: "\u1106", 0xFFB2: "\u1107", 0xFFB3: "\u1108",
+	0xFFB4: "\u1121", 0xFFB5: "\u1109", 0xFFB6: "\u110A", 0xFFB7: "\u110B",
+	0xFFB8: "\u110C", 0xFFB9: "\u110D", 0xFFBA: "\u110E", 0xFFBB: "\u110F",
+	0xFFBC: "\u1110", 0xFFBD: "\u1111", 0xFFBE: "\u1112", 0xFFC2: "\u1161",
+	0xFFC3: "\u1162", 0xFFC4: "\u1163", 0xFFC5: "\u1164", 0xFFC6: "\u1165",
+	0xFFC7: "\u1166", 0xFFCA: "\u1167", 0xFFCB: "\u1168", 0xFFCC: "\u1169",
+	0xFFCD: "\u116A", 0xFFCE: "\u116B", 0xFFCF: "\u116C", 0xFFD2: "\u116D",
+	0xFFD3: "\u116E", 0xFFD4: "\u116F", 0xFFD5: "\u1170", 0xFFD6: "\u1171",
+	0xFFD7: "\u1172", 0xFFDA: "\u1173", 0xFFDB: "\u1174", 0xFFDC: "\u1175",
+	0xFFE0: "\u00A2", 0xFFE1: "\u00A3", 0xFFE2: "\u00AC", 0xFFE3: " \u0304",
+	0xFFE4: "\u00A6", 0xFFE5: "\u00A5", 0xFFE6: "\u20A9", 0xFFE8: "\u2502",
+	0xFFE9: "\u2190", 0xFFEA: "\u2191", 0xFFEB: "\u2192", 0xFFEC: "\u2193",
+	0xFFED: "\u25A0", 0xFFEE: "\u25CB",
+}
+
+// composeTable maps a base character and a combining mark to the
+// precomposed character for Latin, Greek, Cyrillic and kana.
+var composeTable = map[[2]rune]rune{
+	{0x0041, 0x0300}: 0x00C0, {0x0041, 0x0301}: 0x00C1, {0x0041, 0x0302}: 0x00C2, {0x0041, 0x0303}: 0x00C3,
+	{0x0041, 0x0308}: 0x00C4, {0x0041, 0x030A}: 0x00C5, {0x0043, 0x0327}: 0x00C7, {0x0045, 0x0300}: 0x00C8,
+	{0x0045, 0x0301}: 0x00C9, {0x0045, 0x0302}: 0x00CA, {0x0045, 0x0308}: 0x00CB, {0x0049, 0x0300}: 0x00CC,
+	{0x0049, 0x0301}: 0x00CD, {0x0049, 0x0302}: 0x00CE, {0x0049, 0x0308}: 0x00CF, {0x004E, 0x0303}: 0x00D1,
+	{0x004F, 0x0300}: 0x00D2, {0x004F, 0x0301}: 0x00D3, {0x004F, 0x0302}: 0x00D4, {0x004F, 0x0303}: 0x00D5,
+	{0x004F, 0x0308}: 0x00D6, {0x0055, 0x0300}: 0x00D9, {0x0055, 0x0301}: 0x00DA, {0x0055, 0x0302}: 0x00DB,
+	{0x0055, 0x0308}: 0x00DC, {0x0059, 0x0301}: 0x00DD, {0x0061, 0x0300}: 0x00E0, {0x0061, 0x0301}: 0x00E1,
+	{0x0061, 0x0302}: 0x00E2, {0x0061, 0x0303}: 0x00E3, {0x0061, 0x0308}: 0x00E4, {0x0061, 0x030A}: 0x00E5,
+	{0x0063, 0x0327}: 0x00E7, {0x0065, 0x0300}: 0x00E8, {0x0065, 0x0301}: 0x00E9, {0x0065, 0x0302}: 0x00EA,
+	{0x0065, 0x0308}: 0x00EB, {0x0069, 0x0300}: 0x00EC, {0x0069, 0x0301}: 0x00ED, {0x0069, 0x0302}: 0x00EE,
+	{0x0069, 0x0308}: 0x00EF, {0x006E, 0x0303}: 0x00F1, {0x006F, 0x0300}: 0x00F2, {0x006F, 0x0301}: 0x00F3,
+	{0x006F, 0x0302}: 0x00F4, {0x006F, 0x0303}: 0x00F5, {0x006F, 0x0308}: 0x00F6, {0x0075, 0x0300}: 0x00F9,
+	{0x0075, 0x0301}: 0x00FA, {0x0075, 0x0302}: 0x00FB, {0x0075, 0x0308}: 0x00FC, {0x0079, 0x0301}: 0x00FD,
+	{0x0079, 0x0308}: 0x00FF, {0x0041, 0x0304}: 0x0100, {0x0061, 0x0304}: 0x0101, {0x0041, 0x0306}: 0x0102,
+	{0x0061, 0x0306}: 0x0103, {0x0041, 0x0328}: 0x0104, {0x0061, 0x0328}: 0x0105, {0x0043, 0x0301}: 0x0106,
+	{0x0063, 0x0301}: 0x0107, {0x0043, 0x0302}: 0x0108, {0x0063, 0x0302}: 0x0109, {0x0043, 0x0307}: 0x010A,
+	{0x0063, 0x0307}: 0x010B, {0x0043, 0x030C}: 0x010C, {0x0063, 0x030C}: 0x010D, {0x0044, 0x030C}: 0x010E,
+	{0x0064, 0x030C}: 0x010F, {0x0045, 0x0304}: 0x0112, {0x0065, 0x0304}: 0x0113, {0x0045, 0x0306}: 0x0114,
+	{0x0065, 0x0306}: 0x0115, {0x0045, 0x0307}: 0x0116, {0x0065, 0x0307}: 0x0117, {0x0045, 0x0328}: 0x0118,
+	{0x0065, 0x0328}: 0x0119, {0x0045, 0x030C}: 0x011A, {0x0065, 0x030C}: 0x011B, {0x0047, 0x0302}: 0x011C,
+	{0x0067, 0x0302}: 0x011D, {0x0047, 0x0306}: 0x011E, {0x0067, 0x0306}: 0x011F, {0x0047, 0x0307}: 0x0120,
+	{0x0067, 0x0307}: 0x0121, {0x0047, 0x0327}: 0x0122, {0x0067, 0x0327}: 0x0123, {0x0048, 0x0302}: 0x0124,
+	{0x0068, 0x0302}: 0x0125, {0x0049, 0x0303}: 0x0128, {0x0069, 0x0303}: 0x0129, {0x0049, 0x0304}: 0x012A,
+	{0x0069, 0x0304}: 0x012B, {0x0049, 0x0306}: 0x012C, {0x0069, 0x0306}: 0x012D, {0x0049, 0x0328}: 0x012E,
+	{0x0069, 0x0328}: 0x012F, {0x0049, 0x0307}: 0x0130, {0x004A, 0x0302}: 0x0134, {0x006A, 0x0302}: 0x0135,
+	{0x004B, 0x0327}: 0x0136, {0x006B, 0x0327}: 0x0137, {0x004C, 0x0301}: 0x0139, {0x006C, 0x0301}: 0x013A,
+	{0x004C, 0x0327}: 0x013B, {0x006C, 0x0327}: 0x013C, {0x004C, 0x030C}: 0x013D, {0x006C, 0x030C}: 0x013E,
+	{0x004E, 0x0301}: 0x0143, {0x006E, 0x0301}: 0x0144, {0x004E, 0x0327}: 0x0145, {0x006E, 0x0327}: 0x0146,
+	{0x004E, 0x030C}: 0x0147, {0x006E, 0x030C}: 0x0148, {0x004F, 0x0304}: 0x014C, {0x006F, 0x0304}: 0x014D,
+	{0x004F, 0x0306}: 0x014E, {0x006F, 0x0306}: 0x014F, {0x004F, 0x030B}: 0x0150, {0x006F, 0x030B}: 0x0151,
+	{0x0052, 0x0301}: 0x0154, {0x0072, 0x0301}: 0x0155, {0x0052, 0x0327}: 0x0156, {0x0072, 0x0327}: 0x0157,
+	{0x0052, 0x030C}: 0x0158, {0x0072, 0x030C}: 0x0159, {0x0053, 0x0301}: 0x015A, {0x0073, 0x0301}: 0x015B,
+	{0x0053, 0x0302}: 0x015C, {0x0073, 0x0302}: 0x015D, {0x0053, 0x0327}: 0x015E, {0x0073, 0x0327}: 0x015F,
+	{0x0053, 0x030C}: 0x0160, {0x0073, 0x030C}: 0x0161, {0x0054, 0x0327}: 0x0162, {0x0074, 0x0327}: 0x0163,
+	{0x0054, 0x030C}: 0x0164, {0x0074, 0x030C}: 0x0165, {0x0055, 0x0303}: 0x0168, {0x0075, 0x0303}: 0x0169,
+	{0x0055, 0x0304}: 0x016A, {0x0075, 0x0304}: 0x016B, {0x0055, 0x0306}: 0x016C, {0x0075, 0x0306}: 0x016D,
+	{0x0055, 0x030A}: 0x016E, {0x0075, 0x030A}: 0x016F, {0x0055, 0x030B}: 0x0170, {0x0075, 0x030B}: 0x0171,
+	{0x0055, 0x0328}: 0x0172, {0x0075, 0x0328}: 0x0173, {0x0057, 0x0302}: 0x0174, {0x0077, 0x0302}: 0x0175,
+	{0x0059, 0x0302}: 0x0176, {0x0079, 0x0302}: 0x0177, {0x0059, 0x0308}: 0x0178, {0x005A, 0x0301}: 0x0179,
+	{0x007A, 0x0301}: 0x017A, {0x005A, 0x0307}: 0x017B, {0x007A, 0x0307}: 0x017C, {0x005A, 0x030C}: 0x017D,
+	{0x007A, 0x030C}: 0x017E, {0x004F, 0x031B}: 0x01A0, {0x006F, 0x031B}: 0x01A1, {0x0055, 0x031B}: 0x01AF,
+	{0x0075, 0x031B}: 0x01B0, {0x0041, 0x030C}: 0x01CD, {0x0061, 0x030C}: 0x01CE, {0x0049, 0x030C}: 0x01CF,
+	{0x0069, 0x030C}: 0x01D0, {0x004F, 0x030C}: 0x01D1, {0x006F, 0x030C}: 0x01D2, {0x0055, 0x030C}: 0x01D3,
+	{0x0075, 0x030C}: 0x01D4, {0x00DC, 0x0304}: 0x01D5, {0x00FC, 0x0304}: 0x01D6, {0x00DC, 0x0301}: 0x01D7,
+	{0x00FC, 0x0301}: 0x01D8, {0x00DC, 0x030C}: 0x01D9, {0x00FC, 0x030C}: 0x01DA, {0x00DC, 0x0300}: 0x01DB,
+	{0x00FC, 0x0300}: 0x01DC, {0x00C4, 0x0304}: 0x01DE, {0x00E4, 0x0304}: 0x01DF, {0x0226, 0x0304}: 0x01E0,
+	{0x0227, 0x0304}: 0x01E1, {0x00C6, 0x0304}: 0x01E2, {0x00E6, 0x0304}: 0x01E3, {0x0047, 0x030C}: 0x01E6,
+	{0x0067, 0x030C}: 0x01E7, {0x004B, 0x030C}: 0x01E8, {0x006B, 0x030C}: 0x01E9, {0x004F, 0x0328}: 0x01EA,
+	{0x006F, 0x0328}: 0x01EB, {0x01EA, 0x0304}: 0x01EC, {0x01EB, 0x0304}: 0x01ED, {0x01B7, 0x030C}: 0x01EE,
+	{0x0292, 0x030C}: 0x01EF, {0x006A, 0x030C}: 0x01F0, {0x0047, 0x0301}: 0x01F4, {0x0067, 0x0301}: 0x01F5,
+	{0x004E, 0x0300}: 0x01F8, {0x006E, 0x0300}: 0x01F9, {0x00C5, 0x0301}: 0x01FA, {0x00E5, 0x0301}: 0x01FB,
+	{0x00C6, 0x0301}: 0x01FC, {0x00E6, 0x0301}: 0x01FD, {0x00D8, 0x0301}: 0x01FE, {0x00F8, 0x0301}: 0x01FF,
+	{0x0041, 0x030F}: 0x0200, {0x0061, 0x030F}: 0x0201, {0x0041, 0x0311}: 0x0202, {0x0061, 0x0311}: 0x0203,
+	{0x0045, 0x030F}: 0x0204, {0x0065, 0x030F}: 0x0205, {0x0045, 0x0311}: 0x0206, {0x0065, 0x0311}: 0x0207,
+	{0x0049, 0x030F}: 0x0208, {0x0069, 0x030F}: 0x0209, {0x0049, 0x0311}: 0x020A, {0x0069, 0x0311}: 0x020B,
+	{0x004F, 0x030F}: 0x020C, {0x006F, 0x030F}: 0x020D, {0x004F, 0x0311}: 0x020E, {0x006F, 0x0311}: 0x020F,
+	{0x0052, 0x030F}: 0x0210, {0x0072, 0x030F}: 0x0211, {0x0052, 0x0311}: 0x0212, {0x0072, 0x0311}: 0x0213,
+	{0x0055, 0x030F}: 0x0214, {0x0075, 0x030F}: 0x0215, {0x0055, 0x0311}: 0x0216, {0x0075, 0x0311}: 0x0217,
+	{0x0053, 0x0326}: 0x0218, {0x0073, 0x0326}: 0x0219, {0x0054, 0x0326}: 0x021A, {0x0074, 0x0326}: 0x021B,
+	{0x0048, 0x030C}: 0x021E, {0x0068, 0x030C}: 0x021F, {0x0041, 0x0307}: 0x0226, {0x0061, 0x0307}: 0x0227,
+	{0x0045, 0x0327}: 0x0228, {0x0065, 0x0327}: 0x0229, {0x00D6, 0x0304}: 0x022A, {0x00F6, 0x0304}: 0x022B,
+	{0x00D5, 0x0304}: 0x022C, {0x00F5, 0x0304}: 0x022D, {0x004F, 0x0307}: 0x022E, {0x006F, 0x0307}: 0x022F,
+	{0x022E, 0x0304}: 0x0230, {0x022F, 0x0304}: 0x0231, {0x0059, 0x0304}: 0x0232, {0x0079, 0x0304}: 0x0233,
+	{0x00A8, 0x0301}: 0x0385, {0x0391, 0x0301}: 0x0386, {0x0395, 0x0301}: 0x0388, {0x0397, 0x0301}: 0x0389,
+	{0x0399, 0x0301}: 0x038A, {0x039F, 0x0301}: 0x038C, {0x03A5, 0x0301}: 0x038E, {0x03A9, 0x0301}: 0x038F,
+	{0x03CA, 0x0301}: 0x0390, {0x0399, 0x0308}: 0x03AA, {0x03A5, 0x0308}: 0x03AB, {0x03B1, 0x0301}: 0x03AC,
+	{0x03B5, 0x0301}: 0x03AD, {0x03B7, 0x0301}: 0x03AE, {0x03B9, 0x0301}: 0x03AF, {0x03CB, 0x0301}: 0x03B0,
+	{0x03B9, 0x0308}: 0x03CA, {0x03C5, 0x0308}: 0x03CB, {0x03BF, 0x0301}: 0x03CC, {0x03C5, 0x0301}: 0x03CD,
+	{0x03C9, 0x0301}: 0x03CE, {0x03D2, 0x0301}: 0x03D3, {0x03D2, 0x0308}: 0x03D4, {0x0415, 0x0300}: 0x0400,
+	{0x0415, 0x0308}: 0x0401, {0x0413, 0x0301}: 0x0403, {0x0406, 0x0308}: 0x0407, {0x041A, 0x0301}: 0x040C,
+	{0x0418, 0x0300}: 0x040D, {0x0423, 0x0306}: 0x040E, {0x0418, 0x0306}: 0x0419, {0x0438, 0x0306}: 0x0439,
+	{0x0435, 0x0300}: 0x0450, {0x0435, 0x0308}: 0x0451, {0x0433, 0x0301}: 0x0453, {0x0456, 0x0308}: 0x0457,
+	{0x043A, 0x0301}: 0x045C, {0x0438, 0x0300}: 0x045D, {0x0443, 0x0306}: 0x045E, {0x0474, 0x030F}: 0x0476,
+	{0x0475, 0x030F}: 0x0477, {0x0416, 0x0306}: 0x04C1, {0x0436, 0x0306}: 0x04C2, {0x0410, 0x0306}: 0x04D0,
+	{0x0430, 0x0306}: 0x04D1, {0x0410, 0x0308}: 0x04D2, {0x0430, 0x0308}: 0x04D3, {0x0415, 0x0306}: 0x04D6,
+	{0x0435, 0x0306}: 0x04D7, {0x04D8, 0x0308}: 0x04DA, {0x04D9, 0x0308}: 0x04DB, {0x0416, 0x0308}: 0x04DC,
+	{0x0436, 0x0308}: 0x04DD, {0x0417, 0x0308}: 0x04DE, {0x0437, 0x0308}: 0x04DF, {0x0418, 0x0304}: 0x04E2,
+	{0x0438, 0x0304}: 0x04E3, {0x0418, 0x0308}: 0x04E4, {0x0438, 0x0308}: 0x04E5, {0x041E, 0x0308}: 0x04E6,
+	{0x043E, 0x0308}: 0x04E7, {0x04E8, 0x0308}: 0x04EA, {0x04E9, 0x0308}: 0x04EB, {0x042D, 0x0308}: 0x04EC,
+	{0x044D, 0x0308}: 0x04ED, {0x0423, 0x0304}: 0x04EE, {0x0443, 0x0304}: 0x04EF, {0x0423, 0x0308}: 0x04F0,
+	{0x0443, 0x0308}: 0x04F1, {0x0423, 0x030B}: 0x04F2, {0x0443, 0x030B}: 0x04F3, {0x0427, 0x0308}: 0x04F4,
+	{0x0447, 0x0308}: 0x04F5, {0x042B, 0x0308}: 0x04F8, {0x044B, 0x0308}: 0x04F9, {0x0041, 0x0325}: 0x1E00,
+	{0x0061, 0x0325}: 0x1E01, {0x0042, 0x0307}: 0x1E02, {0x0062, 0x0307}: 0x1E03, {0x0042, 0x0323}: 0x1E04,
+	{0x0062, 0x0323}: 0x1E05, {0x0042, 0x0331}: 0x1E06, {0x0062, 0x0331}: 0x1E07, {0x00C7, 0x0301}: 0x1E08,
+	{0x00E7, 0x0301}: 0x1E09, {0x0044, 0x0307}: 0x1E0A, {0x0064, 0x0307}: 0x1E0B, {0x0044, 0x0323}: 0x1E0C,
+	{0x0064, 0x0323}: 0x1E0D, {0x0044, 0x0331}: 0x1E0E, {0x0064, 0x0331}: 0x1E0F, {0x0044, 0x0327}: 0x1E10,
+	{0x0064, 0x0327}: 0x1E11, {0x0044, 0x032D}: 0x1E12, {0x0064, 0x032D}: 0x1E13, {0x0112, 0x0300}: 0x1E14,
+	{0x0113, 0x0300}: 0x1E15, {0x0112, 0x0301}: 0x1E16, {0x0113, 0x0301}: 0x1E17, {0x0045, 0x032D}: 0x1E18,
+	{0x0065, 0x032D}: 0x1E19, {0x0045, 0x0330}: 0x1E1A, {0x0065, 0x0330}: 0x1E1B, {0x0228, 0x0306}: 0x1E1C,
+	{0x0229, 0x0306}: 0x1E1D, {0x0046, 0x0307}: 0x1E1E, {0x0066, 0x0307}: 0x1E1F, {0x0047, 0x0304}: 0x1E20,
+	{0x0067, 0x0304}: 0x1E21, {0x0048, 0x0307}: 0x1E22, {0x0068, 0x0307}: 0x1E23, {0x0048, 0x0323}: 0x1E24,
+	{0x0068, 0x0323}: 0x1E25, {0x0048, 0x0308}: 0x1E26, {0x0068, 0x0308}: 0x1E27, {0x0048, 0x0327}: 0x1E28,
+	{0x0068, 0x0327}: 0x1E29, {0x0048, 0x032E}: 0x1E2A, {0x0068, 0x032E}: 0x1E2B, {0x0049, 0x0330}: 0x1E2C,
+	{0x0069, 0x0330}: 0x1E2D, {0x00CF, 0x0301}: 0x1E2E, {0x00EF, 0x0301}: 0x1E2F, {0x004B, 0x0301}: 0x1E30,
+	{0x006B, 0x0301}: 0x1E31, {0x004B, 0x0323}: 0x1E32, {0x006B, 0x0323}: 0x1E33, {0x004B, 0x0331}: 0x1E34,
+	{0x006B, 0x0331}: 0x1E35, {0x004C, 0x0323}: 0x1E36, {0x006C, 0x0323}: 0x1E37, {0x1E36, 0x0304}: 0x1E38,
+	{0x1E37, 0x0304}: 0x1E39, {0x004C, 0x0331}: 0x1E3A, {0x006C, 0x0331}: 0x1E3B, {0x004C, 0x032D}: 0x1E3C,
+	{0x006C, 0x032D}: 0x1E3D, {0x004D, 0x0301}: 0x1E3E, {0x006D, 0x0301}: 0x1E3F, {0x004D, 0x0307}: 0x1E40,
+	{0x006D, 0x0307}: 0x1E41, {0x004D, 0x0323}: 0x1E42, {0x006D, 0x0323}: 0x1E43, {0x004E, 0x0307}: 0x1E44,
+	{0x006E, 0x0307}: 0x1E45, {0x004E, 0x0323}: 0x1E46, {0x006E, 0x0323}: 0x1E47, {0x004E, 0x0331}: 0x1E48,
+	{0x006E, 0x0331}: 0x1E49, {0x004E, 0x032D}: 0x1E4A, {0x006E, 0x032D}: 0x1E4B, {0x00D5, 0x0301}: 0x1E4C,
+	{0x00F5, 0x0301}: 0x1E4D, {0x00D5, 0x0308}: 0x1E4E, {0x00F5, 0x0308}: 0x1E4F, {0x014C, 0x0300}: 0x1E50,
+	{0x014D, 0x0300}: 0x1E51, {0x014C, 0x0301}: 0x1E52, {0x014D, 0x0301}: 0x1E53, {0x0050, 0x0301}: 0x1E54,
+	{0x0070, 0x0301}: 0x1E55, {0x0050, 0x0307}: 0x1E56, {0x0070, 0x0307}: 0x1E57, {0x0052, 0x0307}: 0x1E58,
+	{0x0072, 0x0307}: 0x1E59, {0x0052, 0x0323}: 0x1E5A, {0x0072, 0x0323}: 0x1E5B, {0x1E5A, 0x0304}: 0x1E5C,
+	{0x1E5B, 0x0304}: 0x1E5D, {0x0052, 0x0331}: 0x1E5E, {0x0072, 0x0331}: 0x1E5F, {0x0053, 0x0307}: 0x1E60,
+	{0x0073, 0x0307}: 0x1E61, {0x0053, 0x0323}: 0x1E62, {0x0073, 0x0323}: 0x1E63, {0x015A, 0x0307}: 0x1E64,
+	{0x015B, 0x0307}: 0x1E65, {0x0160, 0x0307}: 0x1E66, {0x0161, 0x0307}: 0x1E67, {0x1E62, 0x0307}: 0x1E68,
+	{0x1E63, 0x0307}: 0x1E69, {0x0054, 0x0307}: 0x1E6A, {0x0074, 0x0307}: 0x1E6B, {0x0054, 0x0323}: 0x1E6C,
+	{0x0074, 0x0323}: 0x1E6D, {0x0054, 0x0331}: 0x1E6E, {0x0074, 0x0331}: 0x1E6F, {0x0054, 0x032D}: 0x1E70,
+	{0x0074, 0x032D}: 0x1E71, {0x0055, 0x0324}: 0x1E72, {0x0075, 0x0324}: 0x1E73, {0x0055, 0x0330}: 0x1E74,
+	{0x0075, 0x0330}: 0x1E75, {0x0055, 0x032D}: 0x1E76, {0x0075, 0x032D}: 0x1E77, {0x0168, 0x0301}: 0x1E78,
+	{0x0169, 0x0301}: 0x1E79, {0x016A, 0x0308}: 0x1E7A, {0x016B, 0x0308}: 0x1E7B, {0x0056, 0x0303}: 0x1E7C,
+	{0x0076, 0x0303}: 0x1E7D, {0x0056, 0x0323}: 0x1E7E, {0x0076, 0x0323}: 0x1E7F, {0x0057, 0x0300}: 0x1E80,
+	{0x0077, 0x0300}: 0x1E81, {0x0057, 0x0301}: 0x1E82, {0x0077, 0x0301}: 0x1E83, {0x0057, 0x0308}: 0x1E84,
+	{0x0077, 0x0308}: 0x1E85, {0x0057, 0x0307}: 0x1E86, {0x0077, 0x0307}: 0x1E87, {0x0057, 0x0323}: 0x1E88,
+	{0x0077, 0x0323}: 0x1E89, {0x0058, 0x0307}: 0x1E8A, {0x0078, 0x0307}: 0x1E8B, {0x0058, 0x0308}: 0x1E8C,
+	{0x0078, 0x0308}: 0x1E8D, {0x0059, 0x0307}: 0x1E8E, {0x0079, 0x0307}: 0x1E8F, {0x005A, 0x0302}: 0x1E90,
+	{0x007A, 0x0302}: 0x1E91, {0x005A, 0x0323}: 0x1E92, {0x007A, 0x0323}: 0x1E93, {0x005A, 0x0331}: 0x1E94,
+	{0x007A, 0x0331}: 0x1E95, {0x0068, 0x0331}: 0x1E96, {0x0074, 0x0308}: 0x1E97, {0x0077, 0x030A}: 0x1E98,
+	{0x0079, 0x030A}: 0x1E99, {0x017F, 0x0307}: 0x1E9B, {0x0041, 0x0323}: 0x1EA0, {0x0061, 0x0323}: 0x1EA1,
+	{0x0041, 0x0309}: 0x1EA2, {0x0061, 0x0309}: 0x1EA3, {0x00C2, 0x0301}: 0x1EA4, {0x00E2, 0x0301}: 0x1EA5,
+	{0x00C2, 0x0300}: 0x1EA6, {0x00E2, 0x0300}: 0x1EA7, {0x00C2, 0x0309}: 0x1EA8, {0x00E2, 0x0309}: 0x1EA9,
+	{0x00C2, 0x0303}: 0x1EAA, {0x00E2, 0x0303}: 0x1EAB, {0x1EA0, 0x0302}: 0x1EAC, {0x1EA1, 0x0302}: 0x1EAD,
+	{0x0102, 0x0301}: 0x1EAE, {0x0103, 0x0301}: 0x1EAF, {0x0102, 0x0300}: 0x1EB0, {0x0103, 0x0300}: 0x1EB1,
+	{0x0102, 0x0309}: 0x1EB2, {0x0103, 0x0309}: 0x1EB3, {0x0102, 0x0303}: 0x1EB4, {0x0103, 0x0303}: 0x1EB5,
+	{0x1EA0, 0x0306}: 0x1EB6, {0x1EA1, 0x0306}: 0x1EB7, {0x0045, 0x0323}: 0x1EB8, {0x0065, 0x0323}: 0x1EB9,
+	{0x0045, 0x0309}: 0x1EBA, {0x0065, 0x0309}: 0x1EBB, {0x0045, 0x0303}: 0x1EBC, {0x0065, 0x0303}: 0x1EBD,
+	{0x00CA, 0x0301}: 0x1EBE, {0x00EA, 0x0301}: 0x1EBF, {0x00CA, 0x0300}: 0x1EC0, {0x00EA, 0x0300}: 0x1EC1,
+	{0x00CA, 0x0309}: 0x1EC2, {0x00EA, 0x0309}: 0x1EC3, {0x00CA, 0x0303}: 0x1EC4, {0x00EA, 0x0303}: 0x1EC5,
+	{0x1EB8, 0x0302}: 0x1EC6, {0x1EB9, 0x0302}: 0x1EC7, {0x0049, 0x0309}: 0x1EC8, {0x0069, 0x0309}: 0x1EC9,
+	{0x0049, 0x0323}: 0x1ECA, {0x0069, 0x0323}: 0x1ECB, {0x004F, 0x0323}: 0x1ECC, {0x006F, 0x0323}: 0x1ECD,
+	{0x004F, 0x0309}: 0x1ECE, {0x006F, 0x0309}: 0x1ECF, {0x00D4, 0x0301}: 0x1ED0, {0x00F4, 0x0301}: 0x1ED1,
+	{0x00D4, 0x0300}: 0x1ED2, {0x00F4, 0x0300}: 0x1ED3, {0x00D4, 0x0309}: 0x1ED4, {0x00F4, 0x0309}: 0x1ED5,
+	{0x00D4, 0x0303}: 0x1ED6, {0x00F4, 0x0303}: 0x1ED7, {0x1ECC, 0x0302}: 0x1ED8, {0x1ECD, 0x0302}: 0x1ED9,
+	{0x01A0, 0x0301}: 0x1EDA, {0x01A1, 0x0301}: 0x1EDB, {0x01A0, 0x0300}: 0x1EDC, {0x01A1, 0x0300}: 0x1EDD,
+	{0x01A0, 0x0309}: 0x1EDE, {0x01A1, 0x0309}: 0x1EDF, {0x01A0, 0x0303}: 0x1EE0, {0x01A1, 0x0303}: 0x1EE1,
+	{0x01A0, 0x0323}: 0x1EE2, {0x01A1, 0x0323}: 0x1EE3, {0x0055, 0x0323}: 0x1EE4, {0x0075, 0x0323}: 0x1EE5,
+	{0x0055, 0x0309}: 0x1EE6, {0x0075, 0x0309}: 0x1EE7, {0x01AF, 0x0301}: 0x1EE8, {0x01B0, 0x0301}: 0x1EE9,
+	{0x01AF, 0x0300}: 0x1EEA, {0x01B0, 0x0300}: 0x1EEB, {0x01AF, 0x0309}: 0x1EEC, {0x01B0, 0x0309}: 0x1EED,
+	{0x01AF, 0x0303}: 0x1EEE, {0x01B0, 0x0303}: 0x1EEF, {0x01AF, 0x0323}: 0x1EF0, {0x01B0, 0x0323}: 0x1EF1,
+	{0x0059, 0x0300}: 0x1EF2, {0x0079, 0x0300}: 0x1EF3, {0x0059, 0x0323}: 0x1EF4, {0x0079, 0x0323}: 0x1EF5,
+	{0x0059, 0x0309}: 0x1EF6, {0x0079, 0x0309}: 0x1EF7, {0x0059, 0x0303}: 0x1EF8, {0x0079, 0x0303}: 0x1EF9,
+	{0x03B1, 0x0313}: 0x1F00, {0x03B1, 0x0314}: 0x1F01, {0x1F00, 0x0300}: 0x1F02, {0x1F01, 0x0300}: 0x1F03,
+	{0x1F00, 0x0301}: 0x1F04, {0x1F01, 0x0301}: 0x1F05, {0x1F00, 0x0342}: 0x1F06, {0x1F01, 0x0342}: 0x1F07,
+	{0x0391, 0x0313}: 0x1F08, {0x0391, 0x0314}: 0x1F09, {0x1F08, 0x0300}: 0x1F0A, {0x1F09, 0x0300}: 0x1F0B,
+	{0x1F08, 0x0301}: 0x1F0C, {0x1F09, 0x0301}: 0x1F0D, {0x1F08, 0x0342}: 0x1F0E, {0x1F09, 0x0342}: 0x1F0F,
+	{0x03B5, 0x0313}: 0x1F10, {0x03B5, 0x0314}: 0x1F11, {0x1F10, 0x0300}: 0x1F12, {0x1F11, 0x0300}: 0x1F13,
+	{0x1F10, 0x0301}: 0x1F14, {0x1F11, 0x0301}: 0x1F15, {0x0395, 0x0313}: 0x1F18, {0x0395, 0x0314}: 0x1F19,
+	{0x1F18, 0x0300}: 0x1F1A, {0x1F19, 0x0300}: 0x1F1B, {0x1F18, 0x0301}: 0x1F1C, {0x1F19, 0x0301}: 0x1F1D,
+	{0x03B7, 0x0313}: 0x1F20, {0x03B7, 0x0314}: 0x1F21, {0x1F20, 0x0300}: 0x1F22, {0x1F21, 0x0300}: 0x1F23,
+	{0x1F20, 0x0301}: 0x1F24, {0x1F21, 0x0301}: 0x1F25, {0x1F20, 0x0342}: 0x1F26, {0x1F21, 0x0342}: 0x1F27,
+	{0x0397, 0x0313}: 0x1F28, {0x0397, 0x0314}: 0x1F29, {0x1F28, 0x0300}: 0x1F2A, {0x1F29, 0x0300}: 0x1F2B,
+	{0x1F28, 0x0301}: 0x1F2C, {0x1F29, 0x0301}: 0x1F2D, {0x1F28, 0x0342}: 0x1F2E, {0x1F29, 0x0342}: 0x1F2F,
+	{0x03B9, 0x0313}: 0x1F30, {0x03B9, 0x0314}: 0x1F31, {0x1F30, 0x0300}: 0x1F32, {0x1F31, 0x0300}: 0x1F33,
+	{0x1F30, 0x0301}: 0x1F34, {0x1F31, 0x0301}: 0x1F35, {0x1F30, 0x0342}: 0x1F36, {0x1F31, 0x0342}: 0x1F37,
+	{0x0399, 0x0313}: 0x1F38, {0x0399, 0x0314}: 0x1F39, {0x1F38, 0x0300}: 0x1F3A, {0x1F39, 0x0300}: 0x1F3B,
+	{0x1F38, 0x0301}: 0x1F3C, {0x1F39, 0x0301}: 0x1F3D, {0x1F38, 0x0342}: 0x1F3E, {0x1F39, 0x0342}: 0x1F3F,
+	{0x03BF, 0x0313}: 0x1F40, {0x03BF, 0x0314}: 0x1F41, {0x1F40, 0x0300}: 0x1F42, {0x1F41, 0x0300}: 0x1F43,
+	{0x1F40, 0x0301}: 0x1F44, {0x1F41, 0x0301}: 0x1F45, {0x039F, 0x0313}: 0x1F48, {0x039F, 0x0314}: 0x1F49,
+	{0x1F48, 0x0300}: 0x1F4A, {0x1F49, 0x0300}: 0x1F4B, {0x1F48, 0x0301}: 0x1F4C, {0x1F49, 0x0301}: 0x1F4D,
+	{0x03C5, 0x0313}: 0x1F50, {0x03C5, 0x0314}: 0x1F51, {0x1F50, 0x0300}: 0x1F52, {0x1F51, 0x0300}: 0x1F53,
+	{0x1F50, 0x0301}: 0x1F54, {0x1F51, 0x0301}: 0x1F55, {0x1F50, 0x0342}: 0x1F56, {0x1F51, 0x0342}: 0x1F57,
+	{0x03A5, 0x0314}: 0x1F59, {0x1F59, 0x0300}: 0x1F5B, {0x1F59, 0x0301}: 0x1F5D, {0x1F59, 0x0342}: 0x1F5F,
+	{0x03C9, 0x0313}: 0x1F60, {0x03C9, 0x0314}: 0x1F61, {0x1F60, 0x0300}: 0x1F62, {0x1F61, 0x0300}: 0x1F63,
+	{0x1F60, 0x0301}: 0x1F64, {0x1F61, 0x0301}: 0x1F65, {0x1F60, 0x0342}: 0x1F66, {0x1F61, 0x0342}: 0x1F67,
+	{0x03A9, 0x0313}: 0x1F68, {0x03A9, 0x0314}: 0x1F69, {0x1F68, 0x0300}: 0x1F6A, {0x1F69, 0x0300}: 0x1F6B,
+	{0x1F68, 0x0301}: 0x1F6C, {0x1F69, 0x0301}: 0x1F6D, {0x1F68, 0x0342}: 0x1F6E, {0x1F69, 0x0342}: 0x1F6F,
+	{0x03B1, 0x0300}: 0x1F70, {0x03B5, 0x0300}: 0x1F72, {0x03B7, 0x0300}: 0x1F74, {0x03B9, 0x0300}: 0x1F76,
+	{0x03BF, 0x0300}: 0x1F78, {0x03C5, 0x0300}: 0x1F7A, {0x03C9, 0x0300}: 0x1F7C, {0x1F00, 0x0345}: 0x1F80,
+	{0x1F01, 0x0345}: 0x1F81, {0x1F02, 0x0345}: 0x1F82, {0x1F03, 0x0345}: 0x1F83, {0x1F04, 0x0345}: 0x1F84,
+	{0x1F05, 0x0345}: 0x1F85, {0x1F06, 0x0345}: 0x1F86, {0x1F07, 0x0345}: 0x1F87, {0x1F08, 0x0345}: 0x1F88,
+	{0x1F09, 0x0345}: 0x1F89, {0x1F0A, 0x0345}: 0x1F8A, {0x1F0B, 0x0345}: 0x1F8B, {0x1F0C, 0x0345}: 0x1F8C,
+	{0x1F0D, 0x0345}: 0x1F8D, {0x1F0E, 0x0345}: 0x1F8E, {0x1F0F, 0x0345}: 0x1F8F, {0x1F20, 0x0345}: 0x1F90,
+	{0x1F21, 0x0345}: 0x1F91, {0x1F22, 0x0345}: 0x1F92, {0x1F23, 0x0345}: 0x1F93, {0x1F24, 0x0345}: 0x1F94,
+	{0x1F25, 0x0345}: 0x1F95, {0x1F26, 0x0345}: 0x1F96, {0x1F27, 0x0345}: 0x1F97, {0x1F28, 0x0345}: 0x1F98,
+	{0x1F29, 0x0345}: 0x1F99, {0x1F2A, 0x0345}: 0x1F9A, {0x1F2B, 0x0345}: 0x1F9B, {0x1F2C, 0x0345}: 0x1F9C,
+	{0x1F2D, 0x0345}: 0x1F9D, {0x1F2E, 0x0345}: 0x1F9E, {0x1F2F, 0x0345}: 0x1F9F, {0x1F60, 0x0345}: 0x1FA0,
+	{0x1F61, 0x0345}: 0x1FA1, {0x1F62, 0x0345}: 0x1FA2, {0x1F63, 0x0345}: 0x1FA3, {0x1F64, 0x0345}: 0x1FA4,
+	{0x1F65, 0x0345}: 0x1FA5, {0x1F66, 0x0345}: 0x1FA6, {0x1F67, 0x0345}: 0x1FA7, {0x1F68, 0x0345}: 0x1FA8,
+	{0x1F69, 0x0345}: 0x1FA9, {0x1F6A, 0x0345}: 0x1FAA, {0x1F6B, 0x0345}: 0x1FAB, {0x1F6C, 0x0345}: 0x1FAC,
+	{0x1F6D, 0x0345}: 0x1FAD, {0x1F6E, 0x0345}: 0x1FAE, {0x1F6F, 0x0345}: 0x1FAF, {0x03B1, 0x0306}: 0x1FB0,
+	{0x03B1, 0x0304}: 0x1FB1, {0x1F70, 0x0345}: 0x1FB2, {0x03B1, 0x0345}: 0x1FB3, {0x03AC, 0x0345}: 0x1FB4,
+	{0x03B1, 0x0342}: 0x1FB6, {0x1FB6, 0x0345}: 0x1FB7, {0x0391, 0x0306}: 0x1FB8, {0x0391, 0x0304}: 0x1FB9,
+	{0x0391, 0x0300}: 0x1FBA, {0x0391, 0x0345}: 0x1FBC, {0x00A8, 0x0342}: 0x1FC1, {0x1F74, 0x0345}: 0x1FC2,
+	{0x03B7, 0x0345}: 0x1FC3, {0x03AE, 0x0345}: 0x1FC4, {0x03B7, 0x0342}: 0x1FC6, {0x1FC6, 0x0345}: 0x1FC7,
+	{0x0395, 0x0300}: 0x1FC8, {0x0397, 0x0300}: 0x1FCA, {0x0397, 0x0345}: 0x1FCC, {0x1FBF, 0x0300}: 0x1FCD,
+	{0x1FBF, 0x0301}: 0x1FCE, {0x1FBF, 0x0342}: 0x1FCF, {0x03B9, 0x0306}: 0x1FD0, {0x03B9, 0x0304}: 0x1FD1,
+	{0x03CA, 0x0300}: 0x1FD2, {0x03B9, 0x0342}: 0x1FD6, {0x03CA, 0x0342}: 0x1FD7, {0x0399, 0x0306}: 0x1FD8,
+	{0x0399, 0x0304}: 0x1FD9, {0x0399, 0x0300}: 0x1FDA, {0x1FFE, 0x0300}: 0x1FDD, {0x1FFE, 0x0301}: 0x1FDE,
+	{0x1FFE, 0x0342}: 0x1FDF, {0x03C5, 0x0306}: 0x1FE0, {0x03C5, 0x0304}: 0x1FE1, {0x03CB, 0x0300}: 0x1FE2,
+	{0x03C1, 0x0313}: 0x1FE4, {0x03C1, 0x0314}: 0x1FE5, {0x03C5, 0x0342}: 0x1FE6, {0x03CB, 0x0342}: 0x1FE7,
+	{0x03A5, 0x0306}: 0x1FE8, {0x03A5, 0x0304}: 0x1FE9, {0x03A5, 0x0300}: 0x1FEA, {0x03A1, 0x0314}: 0x1FEC,
+	{0x00A8, 0x0300}: 0x1FED, {0x1F7C, 0x0345}: 0x1FF2, {0x03C9, 0x0345}: 0x1FF3, {0x03CE, 0x0345}: 0x1FF4,
+	{0x03C9, 0x0342}: 0x1FF6, {0x1FF6, 0x0345}: 0x1FF7, {0x039F, 0x0300}: 0x1FF8, {0x03A9, 0x0300}: 0x1FFA,
+	{0x03A9, 0x0345}: 0x1FFC, {0x304B, 0x3099}: 0x304C, {0x304D, 0x3099}: 0x304E, {0x304F, 0x3099}: 0x3050,
+	{0x3051, 0x3099}: 0x3052, {0x3053, 0x3099}: 0x3054, {0x3055, 0x3099}: 0x3056, {0x3057, 0x3099}: 0x3058,
+	{0x3059, 0x3099}: 0x305A, {0x305B, 0x3099}: 0x305C, {0x305D, 0x3099}: 0x305E, {0x305F, 0x3099}: 0x3060,
+	{0x3061, 0x3099}: 0x3062, {0x3064, 0x3099}: 0x3065, {0x3066, 0x3099}: 0x3067, {0x3068, 0x3099}: 0x3069,
+	{0x306F, 0x3099}: 0x3070, {0x306F, 0x309A}: 0x3071, {0x3072, 0x3099}: 0x3073, {0x3072, 0x309A}: 0x3074,
+	{0x3075, 0x3099}: 0x3076, {0x3075, 0x309A}: 0x3077, {0x3078, 0x3099}: 0x3079, {0x3078, 0x309A}: 0x307A,
+	{0x307B, 0x3099}: 0x307C, {0x307B, 0x309A}: 0x307D, {0x3046, 0x3099}: 0x3094, {0x309D, 0x3099}: 0x309E,
+	{0x30AB, 0x3099}: 0x30AC, {0x30AD, 0x3099}: 0x30AE, {0x30AF, 0x3099}: 0x30B0, {0x30B1, 0x3099}: 0x30B2,
+	{0x30B3, 0x3099}: 0x30B4, {0x30B5, 0x3099}: 0x30B6, {0x30B7, 0x3099}: 0x30B8, {0x30B9, 0x3099}: 0x30BA,
+	{0x30BB, 0x3099}: 0x30BC, {0x30BD, 0x3099}: 0x30BE, {0x30BF, 0x3099}: 0x30C0, {0x30C1, 0x3099}: 0x30C2,
+	{0x30C4, 0x3099}: 0x30C5, {0x30C6, 0x3099}: 0x30C7, {0x30C8, 0x3099}: 0x30C9, {0x30CF, 0x3099}: 0x30D0,
+	{0x30CF, 0x309A}: 0x30D1, {0x30D2, 0x3099}: 0x30D3, {0x30D2, 0x309A}: 0x30D4, {0x30D5, 0x3099}: 0x30D6,
+	{0x30D5, 0x309A}: 0x30D7, {0x30D8, 0x3099}: 0x30D9, {0x30D8, 0x309A}: 0x30DA, {0x30DB, 0x3099}: 0x30DC,
+	{0x30DB, 0x309A}: 0x30DD, {0x30A6, 0x3099}: 0x30F4, {0x30EF, 0x3099}: 0x30F7, {0x30F0, 0x3099}: 0x30F8,
+	{0x30F1, 0x3099}: 0x30F9, {0x30F2, 0x3099}: 0x30FA, {0x30FD, 0x3099}: 0x30FE,
+}
+
+// asciiFoldTable maps Latin letters with diacritics, ligatures and special
+// letters to their closest ASCII spelling.
+var asciiFoldTable = map[rune]string{
+	0x00C0: "A", 0x00C1: "A", 0x00C2: "A", 0x00C3: "A", 0x00C4: "A", 0x00C5: "A",
+	0x00C6: "AE", 0x00C7: "C", 0x00C8: "E", 0x00C9: "E", 0x00CA: "E", 0x00CB: "E",
+	0x00CC: "I", 0x00CD: "I", 0x00CE: "I", 0x00CF: "I", 0x00D0: "D", 0x00D1: "N",
+	0x00D2: "O", 0x00D3: "O", 0x00D4: "O", 0x00D5: "O", 0x00D6: "O", 0x00D8: "O",
+	0x00D9: "U", 0x00DA: "U", 0x00DB: "U", 0x00DC: "U", 0x00DD: "Y", 0x00DE: "TH",
+	0x00DF: "ss", 0x00E0: "a", 0x00E1: "a", 0x00E2: "a", 0x00E3: "a", 0x00E4: "a",
+	0x00E5: "a", 0x00E6: "ae", 0x00E7: "c", 0x00E8: "e", 0x00E9: "e", 0x00EA: "e",
+	0x00EB: "e", 0x00EC: "i", 0x00ED: "i", 0x00EE: "i", 0x00EF: "i", 0x00F0: "d",
+	0x00F1: "n", 0x00F2: "o", 0x00F3: "o", 0x00F4: "o", 0x00F5: "o", 0x00F6: "o",
+	0x00F8: "o", 0x00F9: "u", 0x00FA: "u", 0x00FB: "u", 0x00FC: "u", 0x00FD: "y",
+	0x00FE: "th", 0x00FF: "y", 0x0100: "A", 0x0101: "a", 0x0102: "A", 0x0103: "a",
+	0x0104: "A", 0x0105: "a", 0x0106: "C", 0x0107: "c", 0x0108: "C", 0x0109: "c",
+	0x010A: "C", 0x010B: "c", 0x010C: "C", 0x010D: "c", 0x010E: "D", 0x010F: "d",
+	0x0110: "D", 0x0111: "d", 0x0112: "E", 0x0113: "e", 0x0114: "E", 0x0115: "e",
+	0x0116: "E", 0x0117: "e", 0x0118: "E", 0x0119: "e", 0x011A: "E", 0x011B: "e",
+	0x011C: "G", 0x011D: "g", 0x011E: "G", 0x011F: "g", 0x0120: "G", 0x0121: "g",
+	0x0122: "G", 0x0123: "g", 0x0124: "H", 0x0125: "h", 0x0126: "H", 0x0127: "h",
+	0x0128: "I", 0x0129: "i", 0x012A: "I", 0x012B: "i", 0x012C: "I", 0x012D: "i",
+	0x012E: "I", 0x012F: "i", 0x0130: "I", 0x0131: "i", 0x0132: "IJ", 0x0133: "ij",
+	0x0134: "J", 0x0135: "j", 0x0136: "K", 0x0137: "k", 0x0138: "q", 0x0139: "L",
+	0x013A: "l", 0x013B: "L", 0x013C: "l", 0x013D: "L", 0x013E: "l", 0x013F: "L",
+	0x0140: "l", 0x0141: "L", 0x0142: "l", 0x0143: "N", 0x0144: "n", 0x0145: "N",
+	0x0146: "n", 0x0147: "N", 0x0148: "n", 0x014A: "N", 0x014B: "n", 0x014C: "O",
+	0x014D: "o", 0x014E: "O", 0x014F: "o", 0x0150: "O", 0x0151: "o", 0x0152: "OE",
+	0x0153: "oe", 0x0154: "R", 0x0155: "r", 0x0156: "R", 0x0157: "r", 0x0158: "R",
+	0x0159: "r", 0x015A: "S", 0x015B: "s", 0x015C: "S", 0x015D: "s", 0x015E: "S",
+	0x015F: "s", 0x0160: "S", 0x0161: "s", 0x0162: "T", 0x0163: "t", 0x0164: "T",
+	0x0165: "t", 0x0166: "T", 0x0167: "t", 0x0168: "U", 0x0169: "u", 0x016A: "U",
+	0x016B: "u", 0x016C: "U", 0x016D: "u", 0x016E: "U", 0x016F: "u", 0x0170: "U",
+	0x0171: "u", 0x0172: "U", 0x0173: "u", 0x0174: "W", 0x0175: "w", 0x0176: "Y",
+	0x0177: "y", 0x0178: "Y", 0x0179: "Z", 0x017A: "z", 0x017B: "Z", 0x017C: "z",
+	0x017D: "Z", 0x017E: "z", 0x017F: "s", 0x0180: "b", 0x0181: "B", 0x0187: "C",
+	0x0188: "c", 0x0189: "D", 0x018A: "D", 0x018E: "E", 0x0191: "F", 0x0192: "f",
+	0x0193: "G", 0x0197: "I", 0x0198: "K", 0x0199: "k", 0x019A: "l", 0x019D: "N",
+	0x019E: "n", 0x01A0: "O", 0x01A1: "o", 0x01A4: "P", 0x01A5: "p", 0x01AB: "t",
+	0x01AC: "T", 0x01AD: "t", 0x01AE: "T", 0x01AF: "U", 0x01B0: "u", 0x01B2: "V",
+	0x01B3: "Y", 0x01B4: "y", 0x01B5: "Z", 0x01B6: "z", 0x01C4: "DZ", 0x01C5: "Dz",
+	0x01C6: "dz", 0x01C7: "LJ", 0x01C8: "Lj", 0x01C9: "lj", 0x01CA: "NJ", 0x01CB: "Nj",
+	0x01CC: "nj", 0x01CD: "A", 0x01CE: "a", 0x01CF: "I", 0x01D0: "i", 0x01D1: "O",
+	0x01D2: "o", 0x01D3: "U", 0x01D4: "u", 0x01D5: "U", 0x01D6: "u", 0x01D7: "U",
+	0x01D8: "u", 0x01D9: "U", 0x01DA: "u", 0x01DB: "U", 0x01DC: "u", 0x01DD: "e",
+	0x01DE: "A", 0x01DF: "a", 0x01E0: "A", 0x01E1: "a", 0x01E4: "G", 0x01E5: "g",
+	0x01E6: "G", 0x01E7: "g", 0x01E8: "K", 0x01E9: "k", 0x01EA: "O", 0x01EB: "o",
+	0x01EC: "O", 0x01ED: "o", 0x01F0: "j", 0x01F1: "DZ", 0x01F2: "Dz", 0x01F3: "dz",
+	0x01F4: "G", 0x01F5: "g", 0x01F8: "N", 0x01F9: "n", 0x01FA: "A", 0x01FB: "a",
+	0x0200: "A", 0x0201: "a", 0x0202: "A", 0x0203: "a", 0x0204: "E", 0x0205: "e",
+	0x0206: "E", 0x0207: "e", 0x0208: "I", 0x0209: "i", 0x020A: "I", 0x020B: "i",
+	0x020C: "O", 0x020D: "o", 0x020E: "O", 0x020F: "o", 0x0210: "R", 0x0211: "r",
+	0x0212: "R", 0x0213: "r", 0x0214: "U", 0x0215: "u", 0x0216: "U", 0x0217: "u",
+	0x0218: "S", 0x0219: "s", 0x021A: "T", 0x021B: "t", 0x021E: "H", 0x021F: "h",
+	0x0221: "d", 0x0224: "Z", 0x0225: "z", 0x0226: "A", 0x0227: "a", 0x0228: "E",
+	0x0229: "e", 0x022A: "O", 0x022B: "o", 0x022C: "O", 0x022D: "o", 0x022E: "O",
+	0x022F: "o", 0x0230: "O", 0x0231: "o", 0x0232: "Y", 0x0233: "y", 0x0234: "l",
+	0x0235: "n", 0x0236: "t", 0x0237: "j", 0x023A: "A", 0x023B: "C", 0x023C: "c",
+	0x023D: "L", 0x0246: "E", 0x0247: "e", 0x0248: "J", 0x0249: "j", 0x024C: "R",
+	0x024D: "r", 0x024E: "Y", 0x024F: "y", 0x1E00: "A", 0x1E01: "a", 0x1E02: "B",
+	0x1E03: "b", 0x1E04: "B", 0x1E05: "b", 0x1E06: "B", 0x1E07: "b", 0x1E08: "C",
+	0x1E09: "c", 0x1E0A: "D", 0x1E0B: "d", 0x1E0C: "D", 0x1E0D: "d", 0x1E0E: "D",
+	0x1E0F: "d", 0x1E10: "D", 0x1E11: "d", 0x1E12: "D", 0x1E13: "d", 0x1E14: "E",
+	0x1E15: "e", 0x1E16: "E", 0x1E17: "e", 0x1E18: "E", 0x1E19: "e", 0x1E1A: "E",
+	0x1E1B: "e", 0x1E1C: "E", 0x1E1D: "e", 0x1E1E: "F", 0x1E1F: "f", 0x1E20: "G",
+	0x1E21: "g", 0x1E22: "H", 0x1E23: "h", 0x1E24: "H", 0x1E25: "h", 0x1E26: "H",
+	0x1E27: "h", 0x1E28: "H", 0x1E29: "h", 0x1E2A: "H", 0x1E2B: "h", 0x1E2C: "I",
+	0x1E2D: "i", 0x1E2E: "I", 0x1E2F: "i", 0x1E30: "K", 0x1E31: "k", 0x1E32: "K",
+	0x1E33: "k", 0x1E34: "K", 0x1E35: "k", 0x1E36: "L", 0x1E37: "l", 0x1E38: "L",
+	0x1E39: "l", 0x1E3A: "L", 0x1E3B: "l", 0x1E3C: "L", 0x1E3D: "l", 0x1E3E: "M",
+	0x1E3F: "m", 0x1E40: "M", 0x1E41: "m", 0x1E42: "M", 0x1E43: "m", 0x1E44: "N",
+	0x1E45: "n", 0x1E46: "N", 0x1E47: "n", 0x1E48: "N", 0x1E49: "n", 0x1E4A: "N",
+	0x1E4B: "n", 0x1E4C: "O", 0x1E4D: "o", 0x1E4E: "O", 0x1E4F: "o", 0x1E50: "O",
+	0x1E51: "o", 0x1E52: "O", 0x1E53: "o", 0x1E54: "P", 0x1E55: "p", 0x1E56: "P",
+	0x1E57: "p", 0x1E58: "R", 0x1E59: "r", 0x1E5A: "R", 0x1E5B: "r", 0x1E5C: "R",
+	0x1E5D: "r", 0x1E5E: "R", 0x1E5F: "r", 0x1E60: "S", 0x1E61: "s", 0x1E62: "S",
+	0x1E63: "s", 0x1E64: "S", 0x1E65: "s", 0x1E66: "S", 0x1E67: "s", 0x1E68: "S",
+	0x1E69: "s", 0x1E6A: "T", 0x1E6B: "t", 0x1E6C: "T", 0x1E6D: "t", 0x1E6E: "T",
+	0x1E6F: "t", 0x1E70: "T", 0x1E71: "t", 0x1E72: "U", 0x1E73: "u", 0x1E74: "U",
+	0x1E75: "u", 0x1E76: "U", 0x1E77: "u", 0x1E78: "U", 0x1E79: "u", 0x1E7A: "U",
+	0x1E7B: "u", 0x1E7C: "V", 0x1E7D: "v", 0x1E7E: "V", 0x1E7F: "v", 0x1E80: "W",
+	0x1E81: "w", 0x1E82: "W", 0x1E83: "w", 0x1E84: "W", 0x1E85: "w", 0x1E86: "W",
+	0x1E87: "w", 0x1E88: "W", 0x1E89: "w", 0x1E8A: "X", 0x1E8B: "x", 0x1E8C: "X",
+	0x1E8D: "x", 0x1E8E: "Y", 0x1E8F: "y", 0x1E90: "Z", 0x1E91: "z", 0x1E92: "Z",
+	0x1E93: "z", 0x1E94: "Z", 0x1E95: "z", 0x1E96: "h", 0x1E97: "t", 0x1E98: "w",
+	0x1E99: "y", 0x1E9B: "s", 0x1E9E: "SS", 0x1EA0: "A", 0x1EA1: "a", 0x1EA2: "A",
+	0x1EA3: "a", 0x1EA4: "A", 0x1EA5: "a", 0x1EA6: "A", 0x1EA7: "a", 0x1EA8: "A",
+	0x1EA9: "a", 0x1EAA: "A", 0x1EAB: "a", 0x1EAC: "A", 0x1EAD: "a", 0x1EAE: "A",
+	0x1EAF: "a", 0x1EB0: "A", 0x1EB1: "a", 0x1EB2: "A", 0x1EB3: "a", 0x1EB4: "A",
+	0x1EB5: "a", 0x1EB6: "A", 0x1EB7: "a", 0x1EB8: "E", 0x1EB9: "e", 0x1EBA: "E",
+	0x1EBB: "e", 0x1EBC: "E", 0x1EBD: "e", 0x1EBE: "E", 0x1EBF: "e", 0x1EC0: "E",
+	0x1EC1: "e", 0x1EC2: "E", 0x1EC3: "e", 0x1EC4: "E", 0x1EC5: "e", 0x1EC6: "E",
+	0x1EC7: "e", 0x1EC8: "I", 0x1EC9: "i", 0x1ECA: "I", 0x1ECB: "i", 0x1ECC: "O",
+	0x1ECD: "o", 0x1ECE: "O", 0x1ECF: "o", 0x1ED0: "O", 0x1ED1: "o", 0x1ED2: "O",
+	0x1ED3: "o", 0x1ED4: "O", 0x1ED5: "o", 0x1ED6: "O", 0x1ED7: "o", 0x1ED8: "O",
+	0x1ED9: "o", 0x1EDA: "O", 0x1EDB: "o", 0x1EDC: "O", 0x1EDD: "o", 0x1EDE: "O",
+	0x1EDF: "o", 0x1EE0: "O", 0x1EE1: "o", 0x1EE2: "O", 0x1EE3: "o", 0x1EE4: "U",
+	0x1EE5: "u", 0x1EE6: "U", 0x1EE7: "u", 0x1EE8: "U", 0x1EE9: "u", 0x1EEA: "U",
+	0x1EEB: "u", 0x1EEC: "U", 0x1EED: "u", 0x1EEE: "U", 0x1EEF: "u", 0x1EF0: "U",
+	0x1EF1: "u", 0x1EF2: "Y", 0x1EF3: "y", 0x1EF4: "Y", 0x1EF5: "y", 0x1EF6: "Y",
+	0x1EF7: "y", 0x1EF8: "Y", 0x1EF9: "y",
+}
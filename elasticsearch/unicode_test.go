@@ -0,0 +1,127 @@
+package hamfts
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestUnicodeTokenizer(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"The quick (\"brown\") fox can't jump 32.3 feet, right?", []string{"The", "quick", "brown", "fox", "can't", "jump", "32.3", "feet", "right"}},
+		{"e-mail user_name 1,000", []string{"e", "mail", "user_name", "1,000"}},
+		{"Crème brûlée", []string{"Crème", "brûlée"}},
+		{"東京都に行きます", []string{"東", "京", "都", "に", "行", "き", "ま", "す"}},
+		{"コンピューター", []string{"コンピューター"}},
+		{"한국어 텍스트", []string{"한국어", "텍스트"}},
+	}
+	for _, tt := range tests {
+		tokens := unicodeTokenizer(tt.text)
+		got := make([]string, len(tokens))
+		for i, token := range tokens {
+			got[i] = token.Term
+			if tt.text[token.Start:token.End] != token.Term {
+				t.Errorf("token %q has offsets [%d:%d] that do not match the text", token.Term, token.Start, token.End)
+			}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("unicodeTokenizer(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizationFilters(t *testing.T) {
+	tests := []struct {
+		fn   func(string) string
+		in   string
+		want string
+	}{
+		{nfkc, "ＡＢＣ１２３", "ABC123"},
+		{nfkc, "ｶﾞｷﾞ", "ガギ"},
+		{nfkc, "ﬁnance", "finance"},
+		{nfkc, "café", "café"},
+		{nfkc, "①", "1"},
+		{asciiFold, "crème brûlée", "creme brulee"},
+		{asciiFold, "Ærøskøbing", "AEroskobing"},
+		{asciiFold, "straße", "strasse"},
+		{asciiFold, "café", "cafe"},
+		{asciiFold, "plain", "plain"},
+	}
+	for _, tt := range tests {
+		if got := tt.fn(tt.in); got != tt.want {
+			t.Errorf("normalize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCJKBigrams(t *testing.T) {
+	a, err := newAnalysis(AnalysisSettings{}, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"東京都", []string{"東京", "京都"}},
+		{"東京 大阪", []string{"東京", "大阪"}},
+		{"日本語のテキスト", []string{"日本", "本語", "語の", "のテ", "テキ", "キス", "スト"}},
+		{"Go言語", []string{"go", "言語"}},
+		{"字", []string{"字"}},
+	}
+	for _, tt := range tests {
+		got := a.analyzers["cjk"].Analyze(tt.text)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("cjk.Analyze(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestCJKSearch(t *testing.T) {
+	testDir, err := os.MkdirTemp("", "hamfts_test_cjk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+
+	idx, err := NewIndexWithOptions(testDir, IndexOptions{
+		Analysis: &AnalysisSettings{Analyzer: "cjk"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+
+	docs := []*Document{
+		NewDocument("1", "東京都は日本の首都です"),
+		NewDocument("2", "京都は古い都市です"),
+		NewDocument("3", "서울은 한국의 수도입니다"),
+	}
+	if err := idx.AddDocuments(docs); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"東京", 1},
+		{"京都", 2},
+		{"首都", 1},
+		{"한국", 1},
+		{"大阪", 0},
+	}
+	for _, tt := range tests {
+		results, err := idx.Search(tt.query, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(results) != tt.want {
+			t.Errorf("Search(%q) got %d results, want %d", tt.query, len(results), tt.want)
+		}
+	}
+}
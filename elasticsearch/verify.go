@@ -0,0 +1,321 @@
+package hamfts
+
+import (
+	"fmt"
+	"os"
+	"sort"
+)
+
+// maxReportedProblems caps the problems described in a VerifyReport; the
+// counts still cover all of them
+const maxReportedProblems = 50
+
+// VerifyReport is the outcome of Verify or Repair
+type VerifyReport struct {
+	Documents        int      `json:"documents"`         // live documents checked
+	Records          int      `json:"records"`           // stored documents, live or dead
+	CorruptDocuments int      `json:"corrupt_documents"` // live documents that cannot be read
+	MissingPostings  int      `json:"missing_postings"`  // postings a stored document should have
+	StalePostings    int      `json:"stale_postings"`    // postings no stored document accounts for
+	Problems         []string `json:"problems,omitempty"`
+	// Set by Repair
+	Repaired         bool     `json:"repaired,omitempty"`
+	LostDocumentIDs  []string `json:"lost_document_ids,omitempty"`
+	problemsReported int
+}
+
+// OK reports whether no problem was found
+func (r *VerifyReport) OK() bool {
+	return r.problemsReported == 0
+}
+
+func (r *VerifyReport) problem(format string, args ...interface{}) {
+	r.problemsReported++
+	if len(r.Problems) < maxReportedProblems {
+		r.Problems = append(r.Problems, fmt.Sprintf(format, args...))
+	}
+}
+
+// verification is what Verify learned about the stored documents
+type verification struct {
+	report       *VerifyReport
+	valid        map[string]int64 // doc numbers of the readable documents
+	entries      map[string][]int64
+	fieldEntries map[string]map[string][]int64
+}
+
+// Verify reads every live document, checks the record checksums in
+// docs.dat and compares the postings with those the stored documents
+// produce. It changes nothing; see Repair.
+func (idx *Index) Verify() (*VerifyReport, error) {
+	idx.mutex.RLock()
+	defer idx.mutex.RUnlock()
+
+	v, err := idx.verify()
+	if err != nil {
+		return nil, err
+	}
+	return v.report, nil
+}
+
+// Repair rebuilds the postings and suggestions from the stored documents
+// and drops the documents that cannot be read, then compacts docs.dat so
+// damaged records are gone. It returns what was found before repairing.
+func (idx *Index) Repair() (*VerifyReport, error) {
+	report, err := idx.repair()
+	if err != nil {
+		return nil, err
+	}
+	return report, idx.Compact()
+}
+
+func (idx *Index) repair() (*VerifyReport, error) {
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+
+	v, err := idx.verify()
+	if err != nil {
+		return nil, err
+	}
+
+	for id := range idx.metadata.DocumentNumbers {
+		if _, ok := v.valid[id]; !ok {
+			v.report.LostDocumentIDs = append(v.report.LostDocumentIDs, id)
+		}
+	}
+	sort.Strings(v.report.LostDocumentIDs)
+
+	locations := make([]int64, len(idx.metadata.DocumentLocations))
+	for num := range locations {
+		locations[num] = deletedLocation
+	}
+	suggest := &completionIndex{path: idx.suggest.path, fields: make(map[string]*completionField), dirty: true}
+	for _, num := range v.valid {
+		doc, err := idx.readDocument(num)
+		if err != nil {
+			return nil, err
+		}
+		suggest.addDocument(doc, idx.metadata.Mapping)
+		locations[num] = idx.metadata.DocumentLocations[num]
+	}
+
+	idx.metadata.DocumentNumbers = v.valid
+	idx.metadata.DocumentLocations = locations
+	idx.metadata.DocumentCount = len(v.valid)
+	idx.metadata.IndexEntries = v.entries
+	idx.metadata.FieldEntries = v.fieldEntries
+	idx.suggest = suggest
+	if err := idx.saveMetadata(); err != nil {
+		return nil, err
+	}
+	v.report.Repaired = true
+	return v.report, nil
+}
+
+func (idx *Index) verify() (*verification, error) {
+	v := &verification{
+		report:       &VerifyReport{Documents: len(idx.metadata.DocumentNumbers)},
+		valid:        make(map[string]int64, len(idx.metadata.DocumentNumbers)),
+		entries:      make(map[string][]int64),
+		fieldEntries: make(map[string]map[string][]int64),
+	}
+	report := v.report
+
+	if idx.metadata.DocumentCount != len(idx.metadata.DocumentNumbers) {
+		report.problem("document count is %d but %d documents are stored", idx.metadata.DocumentCount, len(idx.metadata.DocumentNumbers))
+	}
+
+	scan, err := idx.scanRecords(report)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(idx.metadata.DocumentNumbers))
+	for id := range idx.metadata.DocumentNumbers {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	numOwners := make(map[int64]string, len(ids))
+	posOwners := make(map[int64]string, len(ids))
+	for _, id := range ids {
+		num := idx.metadata.DocumentNumbers[id]
+		if owner, ok := numOwners[num]; ok {
+			report.problem("documents %q and %q share doc number %d", owner, id, num)
+			report.CorruptDocuments++
+			continue
+		}
+		numOwners[num] = id
+		pos, ok := idx.metadata.location(num)
+		if !ok {
+			report.problem("document %q: doc number %d has no location", id, num)
+			report.CorruptDocuments++
+			continue
+		}
+		if owner, ok := posOwners[pos]; ok {
+			report.problem("documents %q and %q share location %d", owner, id, pos)
+			report.CorruptDocuments++
+			continue
+		}
+		posOwners[pos] = id
+		if scan.covers(pos) && !scan.starts[pos] {
+			report.problem("document %q: location %d does not hold a record", id, pos)
+		}
+
+		doc, err := idx.readDocumentAt(pos)
+		if err != nil {
+			report.problem("document %q: %v", id, err)
+			report.CorruptDocuments++
+			continue
+		}
+		if doc.ID != id {
+			report.problem("document %q: location %d holds document %q", id, pos, doc.ID)
+			report.CorruptDocuments++
+			continue
+		}
+		v.valid[id] = num
+		idx.addPostings(v.entries, v.fieldEntries, idx.analysis, doc, num)
+	}
+	for _, num := range idx.metadata.liveDocNumbers() {
+		if _, ok := numOwners[num]; !ok {
+			report.problem("doc number %d has a location but no document", num)
+		}
+	}
+
+	comparePostings(report, "content", v.entries, idx.metadata.IndexEntries)
+	fields := make(map[string]bool)
+	for field := range v.fieldEntries {
+		fields[field] = true
+	}
+	for field := range idx.metadata.FieldEntries {
+		fields[field] = true
+	}
+	for field := range fields {
+		comparePostings(report, "field "+field, v.fieldEntries[field], idx.metadata.FieldEntries[field])
+	}
+	return v, nil
+}
+
+// recordScan is what walking the stored records found
+type recordScan struct {
+	starts  map[int64]bool // locations that hold a record
+	docsEnd int64          // how far docs.dat could be walked
+	tailEnd int64          // how far docs.tail could be walked
+	format  int
+}
+
+// covers reports whether the walk got far enough to know if loc holds a
+// record
+func (s *recordScan) covers(loc int64) bool {
+	switch {
+	case s.format == recordFormatChecksummed:
+		return loc < s.docsEnd
+	case s.format == recordFormatBlocks && loc&tailLocation != 0:
+		return loc&^tailLocation < s.tailEnd
+	case s.format == recordFormatBlocks:
+		return blockOffset(loc) < s.docsEnd
+	}
+	// Bare gob records cannot be walked
+	return false
+}
+
+// scanRecords walks the checksummed records or blocks of docs.dat and the
+// records of docs.tail
+func (idx *Index) scanRecords(report *VerifyReport) (*recordScan, error) {
+	scan := &recordScan{starts: make(map[int64]bool), format: idx.metadata.RecordFormat}
+	if scan.format == recordFormatGob {
+		return scan, nil
+	}
+
+	// The length of a damaged record cannot be trusted, so the records
+	// after it are only checked through their documents
+	walk := func(name string, f *os.File, step func(pos int64) (int64, error)) (int64, error) {
+		info, err := f.Stat()
+		if err != nil {
+			return 0, err
+		}
+		pos := int64(0)
+		for pos < info.Size() {
+			size, err := step(pos)
+			if err != nil {
+				report.problem("%s is damaged from offset %d: %v", name, pos, err)
+				break
+			}
+			pos += size
+		}
+		return pos, nil
+	}
+	record := func(f *os.File, mark int64) func(pos int64) (int64, error) {
+		return func(pos int64) (int64, error) {
+			payload, err := readPayload(f, pos)
+			if err != nil {
+				return 0, err
+			}
+			scan.starts[mark|pos] = true
+			report.Records++
+			return recordHeaderSize + int64(len(payload)), nil
+		}
+	}
+
+	var err error
+	if scan.format == recordFormatChecksummed {
+		scan.docsEnd, err = walk("docs.dat", idx.docFile, record(idx.docFile, 0))
+		return scan, err
+	}
+	scan.docsEnd, err = walk("docs.dat", idx.docFile, func(pos int64) (int64, error) {
+		b, err := readBlock(idx.docFile, pos)
+		if err != nil {
+			return 0, err
+		}
+		for slot := range len(b.offsets) - 1 {
+			scan.starts[pos<<blockSlotBits|int64(slot)] = true
+		}
+		report.Records += len(b.offsets) - 1
+		return b.stored, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	scan.tailEnd, err = walk("docs.tail", idx.tailFile, record(idx.tailFile, tailLocation))
+	return scan, err
+}
+
+// comparePostings counts the postings expected but missing from actual, and
+// those in actual that nothing expects
+func comparePostings(report *VerifyReport, what string, expected, actual map[string][]int64) {
+	missing, stale := 0, 0
+	example := ""
+	count := func(postings []int64) map[int64]int {
+		counts := make(map[int64]int, len(postings))
+		for _, pos := range postings {
+			counts[pos]++
+		}
+		return counts
+	}
+	check := func(term string) {
+		want, got := count(expected[term]), count(actual[term])
+		before := missing + stale
+		for pos, n := range want {
+			missing += max(n-got[pos], 0)
+		}
+		for pos, n := range got {
+			stale += max(n-want[pos], 0)
+		}
+		if example == "" && missing+stale > before {
+			example = term
+		}
+	}
+	for term := range expected {
+		check(term)
+	}
+	for term := range actual {
+		if _, ok := expected[term]; !ok {
+			check(term)
+		}
+	}
+
+	if missing+stale > 0 {
+		report.problem("%s: %d postings missing and %d stale, e.g. for %q", what, missing, stale, example)
+		report.MissingPostings += missing
+		report.StalePostings += stale
+	}
+}
@@ -0,0 +1,136 @@
+package hamfts
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestVerifyRepair(t *testing.T) {
+	testDir, err := os.MkdirTemp("", "hamfts_test_verify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+
+	idx, err := NewIndexWithOptions(testDir, IndexOptions{AutoCompactRatio: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+
+	for i := 0; i < 5; i++ {
+		doc := NewDocument(fmt.Sprintf("doc%d", i), fmt.Sprintf("verify me %d", i))
+		doc.Metadata["tag"] = "t"
+		if err := idx.AddDocument(doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Replacing a document leaves a dead record and no stale postings
+	if err := idx.AddDocument(NewDocument("doc4", "replaced")); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := idx.Verify()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.OK() || report.Documents != 5 || report.Records != 6 {
+		t.Errorf("clean index: got %+v", report)
+	}
+
+	// Damage a posting list and a stored record
+	idx.mutex.Lock()
+	idx.metadata.IndexEntries["verify"] = idx.metadata.IndexEntries["verify"][1:]
+	idx.metadata.IndexEntries["bogus"] = []int64{12345}
+	pos := idx.metadata.DocumentLocations[idx.metadata.DocumentNumbers["doc2"]]
+	idx.mutex.Unlock()
+	if pos&tailLocation == 0 {
+		t.Fatal("expected new documents in docs.tail")
+	}
+	f, err := os.OpenFile(filepath.Join(testDir, "documents", "docs.tail"), os.O_RDWR, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteAt([]byte{0xff, 0xff}, pos&^tailLocation+recordHeaderSize+4); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	report, err = idx.Verify()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.OK() || report.CorruptDocuments != 1 || report.MissingPostings == 0 || report.StalePostings == 0 {
+		t.Errorf("damaged index: got %+v", report)
+	}
+	if err := idx.Compact(); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("compacting a damaged index: got %v, want ErrCorrupt", err)
+	}
+
+	report, err = idx.Repair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Repaired || !reflect.DeepEqual(report.LostDocumentIDs, []string{"doc2"}) {
+		t.Errorf("repair: got %+v", report)
+	}
+	if report, _ := idx.Verify(); !report.OK() || report.Records != 4 {
+		t.Errorf("after repair: got %+v", report)
+	}
+	if docs, _ := idx.Search("verify", false); len(docs) != 3 {
+		t.Errorf("expected 3 documents after repair, got %d", len(docs))
+	}
+	if idx.DocumentCount() != 4 {
+		t.Errorf("expected 4 documents after repair, got %d", idx.DocumentCount())
+	}
+}
+
+func TestLegacyRecordsUpgrade(t *testing.T) {
+	for _, format := range []int{recordFormatGob, recordFormatChecksummed} {
+		t.Run(fmt.Sprintf("format%d", format), func(t *testing.T) {
+			testDir, err := os.MkdirTemp("", "hamfts_test_legacy")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(testDir)
+
+			idx, err := NewIndex(testDir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer idx.Close()
+
+			// An index written in an older format
+			idx.metadata.RecordFormat = format
+			for i := 0; i < 3; i++ {
+				if err := idx.AddDocument(NewDocument(fmt.Sprintf("doc%d", i), "old format")); err != nil {
+					t.Fatal(err)
+				}
+			}
+			records := 0
+			if format == recordFormatChecksummed {
+				records = 3
+			}
+			if report, err := idx.Verify(); err != nil || !report.OK() || report.Records != records {
+				t.Errorf("legacy index: got %+v, %v", report, err)
+			}
+
+			if err := idx.Compact(); err != nil {
+				t.Fatal(err)
+			}
+			if idx.metadata.RecordFormat != currentRecordFormat {
+				t.Errorf("expected record format %d after compaction, got %d", currentRecordFormat, idx.metadata.RecordFormat)
+			}
+			if report, err := idx.Verify(); err != nil || !report.OK() || report.Records != 3 {
+				t.Errorf("upgraded index: got %+v, %v", report, err)
+			}
+			if doc, err := idx.GetDocument("doc1"); err != nil || doc.Content != "old format" {
+				t.Errorf("get after upgrade: got %v, %v", doc, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package hamfts
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync/atomic"
+)
+
+// Searches do not take the index lock. They read a view: a copy of the
+// metadata that is never changed once published, with the analyzers and
+// suggester in use at the time and read handles on the document files.
+// Writers serialize on idx.mutex, change idx.metadata in place and publish
+// a new view when they are done, so a search never waits for a write or
+// for the disk I/O it does.
+//
+// Publishing copies the maps of the metadata, not the posting lists.
+// Writers only append to a posting list or replace it with a new one, and a
+// view never looks past the length it was published with.
+type view struct {
+	metadata *IndexMetadata
+	analysis *analysis
+	suggest  *completionIndex
+	store    docStore
+	files    *viewFiles // nil in live views
+}
+
+// viewFiles are the read handles on docs.dat and docs.tail shared by the
+// views published between two changes of the files: packing the tail maps
+// the grown docs.dat and starts a new docs.tail, a compaction replaces
+// docs.dat. Replaced handles are closed when the last view using them is
+// released.
+type viewFiles struct {
+	docs   *os.File
+	data   *mappedFile // reads docs
+	tail   *os.File
+	blocks *blockCache
+	refs   atomic.Int64 // acquired views, plus one while current
+	closed atomic.Bool
+}
+
+func openViewFiles(baseDir string, mmap bool, blocks *blockCache) (*viewFiles, error) {
+	docs, err := os.Open(filepath.Join(baseDir, "documents", "docs.dat"))
+	if err != nil {
+		return nil, err
+	}
+	tail, err := os.Open(filepath.Join(baseDir, "documents", "docs.tail"))
+	if err != nil {
+		docs.Close()
+		return nil, err
+	}
+	f := &viewFiles{docs: docs, data: newMappedFile(docs, mmap), tail: tail, blocks: blocks}
+	f.refs.Store(1)
+	return f, nil
+}
+
+func (f *viewFiles) release() {
+	if f.refs.Add(-1) == 0 && f.closed.CompareAndSwap(false, true) {
+		f.data.unmap()
+		f.docs.Close()
+		f.tail.Close()
+	}
+}
+
+func (f *viewFiles) store(format int) docStore {
+	return docStore{docs: f.data, tail: f.tail, format: format, blocks: f.blocks}
+}
+
+// acquire returns the current view. It must be released once the caller is
+// done with the documents it reads.
+func (idx *Index) acquire() *view {
+	for {
+		v := idx.current.Load()
+		v.files.refs.Add(1)
+		// Files replaced before the reference was taken may be closing
+		// already; the next view has the new ones
+		if idx.current.Load().files == v.files {
+			return v
+		}
+		v.files.release()
+	}
+}
+
+func (v *view) release() {
+	if v.files != nil {
+		v.files.release()
+	}
+}
+
+// live returns a view of idx.metadata as it is, for writers holding the
+// index lock
+func (idx *Index) live() *view {
+	return &view{
+		metadata: &idx.metadata,
+		analysis: idx.analysis,
+		suggest:  idx.suggest,
+		store:    idx.files.store(idx.metadata.RecordFormat),
+	}
+}
+
+// publish makes the current state of the index visible to searches, and
+// lets go of the files replaced since the last view. It is called with the
+// write lock held.
+func (idx *Index) publish() {
+	m := idx.metadata
+	m.IndexEntries = maps.Clone(m.IndexEntries)
+	m.DocumentNumbers = maps.Clone(m.DocumentNumbers)
+	m.DocumentLocations = slices.Clone(m.DocumentLocations)
+	m.DocumentCreated = slices.Clone(m.DocumentCreated)
+	m.FieldEntries = make(map[string]map[string][]int64, len(idx.metadata.FieldEntries))
+	for field, terms := range idx.metadata.FieldEntries {
+		m.FieldEntries[field] = maps.Clone(terms)
+	}
+	m.Mapping = m.Mapping.clone()
+	idx.current.Store(&view{
+		metadata: &m,
+		analysis: idx.analysis,
+		suggest:  idx.suggest,
+		store:    idx.files.store(m.RecordFormat),
+		files:    idx.files,
+	})
+	for _, files := range idx.retired {
+		files.release()
+	}
+	idx.retired = nil
+}
+
+// renewFiles opens new read handles on the document files, for the views
+// published from now on
+func (idx *Index) renewFiles(blocks *blockCache) error {
+	files, err := openViewFiles(idx.baseDir, idx.mmap, blocks)
+	if err != nil {
+		return err
+	}
+	if idx.files != nil {
+		idx.retired = append(idx.retired, idx.files)
+	}
+	idx.files = files
+	return nil
+}
+
+// rotateTail replaces docs.tail with an empty file once nothing points into
+// it. Views published before keep reading the old one.
+func (idx *Index) rotateTail() error {
+	path := filepath.Join(idx.baseDir, "documents", "docs.tail")
+	tail, err := os.OpenFile(path+".tmp", os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		tail.Close()
+		return err
+	}
+	idx.tailFile.Close()
+	idx.tailFile = tail
+	return idx.renewFiles(idx.files.blocks)
+}
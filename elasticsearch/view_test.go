@@ -0,0 +1,227 @@
+package hamfts
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSearchesDoNotWaitForWriters(t *testing.T) {
+	testDir, err := os.MkdirTemp("", "hamfts_test_view")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+
+	idx, err := NewIndex(testDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+	if err := idx.AddDocument(NewDocument("1", "quick brown fox")); err != nil {
+		t.Fatal(err)
+	}
+
+	// A writer in the middle of its disk I/O holds the lock
+	idx.mutex.Lock()
+	done := make(chan error)
+	go func() {
+		if _, err := idx.GetDocument("1"); err != nil {
+			done <- err
+			return
+		}
+		docs, err := idx.SearchQuery(Query{Match: map[string]string{"content": "fox"}})
+		if err == nil && len(docs) != 1 {
+			err = fmt.Errorf("expected 1 result, got %d", len(docs))
+		}
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Error(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("search waited for the write lock")
+	}
+	idx.mutex.Unlock()
+}
+
+func TestViewOutlivesFiles(t *testing.T) {
+	testDir, err := os.MkdirTemp("", "hamfts_test_view_files")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+
+	idx, err := NewIndexWithOptions(testDir, IndexOptions{AutoCompactRatio: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+	fillIndex(t, idx, 300)
+
+	v := idx.acquire()
+	old := v.files
+
+	// Replacing every document packs the tail more than once, and the
+	// compaction replaces docs.dat
+	fillIndex(t, idx, 300)
+	if err := idx.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	if idx.files == old || old.closed.Load() {
+		t.Fatal("expected the acquired files to be replaced but still open")
+	}
+
+	for _, num := range v.metadata.liveDocNumbers() {
+		if _, err := v.readDocument(num); err != nil {
+			t.Fatalf("reading from the old view: %v", err)
+		}
+	}
+	v.release()
+	if !old.closed.Load() {
+		t.Error("expected the replaced files to be closed once released")
+	}
+}
+
+// TestConcurrentWorkload runs searches next to writers that add, replace
+// and delete documents, pack the tail and compact. Run it with -race.
+func TestConcurrentWorkload(t *testing.T) {
+	testDir, err := os.MkdirTemp("", "hamfts_test_workload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+
+	idx, err := NewIndexWithOptions(testDir, IndexOptions{AutoCompactRatio: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+	if err := idx.PutMapping(Mapping{Fields: map[string]*FieldMapping{"title": {Type: FieldCompletion}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Every version of a document names its ID in its content, so a search
+	// can tell a document read from another view than its postings
+	newDoc := func(id int, version int) *Document {
+		doc := NewDocument(fmt.Sprintf("doc%d", id),
+			fmt.Sprintf("id%d version%d shared %s", id, version, strings.Repeat("filler text ", 20)))
+		doc.Metadata["title"] = fmt.Sprintf("title %d", id)
+		doc.Metadata["version"] = version
+		return doc
+	}
+
+	const docs = 200
+	var stop atomic.Bool
+	var writers, readers sync.WaitGroup
+	errs := make(chan error, 16)
+	fail := func(err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+		stop.Store(true)
+	}
+
+	for w := 0; w < 2; w++ {
+		writers.Add(1)
+		go func(w int) {
+			defer writers.Done()
+			rng := rand.New(rand.NewSource(int64(w)))
+			for i := 0; i < 150 && !stop.Load(); i++ {
+				id := rng.Intn(docs)
+				var err error
+				switch rng.Intn(4) {
+				case 0:
+					err = idx.DeleteDocument(fmt.Sprintf("doc%d", id))
+					if errors.Is(err, ErrNotFound) {
+						err = nil
+					}
+				case 1:
+					batch := []*Document{newDoc(id, i), newDoc((id+1)%docs, i)}
+					err = idx.AddDocuments(batch)
+				default:
+					err = idx.AddDocument(newDoc(id, i))
+				}
+				if err != nil {
+					fail(err)
+					return
+				}
+			}
+		}(w)
+	}
+	writers.Add(1)
+	go func() {
+		defer writers.Done()
+		for i := 0; i < 3 && !stop.Load(); i++ {
+			time.Sleep(20 * time.Millisecond)
+			if err := idx.Compact(); err != nil && !errors.Is(err, ErrConflict) {
+				fail(err)
+			}
+		}
+	}()
+
+	var searches atomic.Int64
+	for r := 0; r < 4; r++ {
+		readers.Add(1)
+		go func(r int) {
+			defer readers.Done()
+			rng := rand.New(rand.NewSource(int64(100 + r)))
+			for !stop.Load() {
+				id := rng.Intn(docs)
+				term := fmt.Sprintf("id%d", id)
+				results, err := idx.SearchQuery(Query{Match: map[string]string{"content": term}})
+				if err != nil {
+					fail(err)
+					return
+				}
+				for _, doc := range results {
+					if doc.ID != fmt.Sprintf("doc%d", id) || !strings.Contains(doc.Content, term+" ") {
+						fail(fmt.Errorf("search for %s found %s: %q", term, doc.ID, doc.Content[:20]))
+						return
+					}
+				}
+				if doc, err := idx.GetDocument(fmt.Sprintf("doc%d", id)); err == nil && !strings.HasPrefix(doc.Content, term+" ") {
+					fail(fmt.Errorf("doc%d holds %q", id, doc.Content[:20]))
+					return
+				} else if err != nil && !errors.Is(err, ErrNotFound) {
+					fail(err)
+					return
+				}
+				if _, err := idx.Search(term+" shared", false); err != nil {
+					fail(err)
+					return
+				}
+				if _, err := idx.Suggest(SuggestRequest{Field: "title", Prefix: "title 1"}); err != nil {
+					fail(err)
+					return
+				}
+				idx.SuggestTerms("sharde", TermSuggestOptions{})
+				idx.GetStats()
+				searches.Add(1)
+			}
+		}(r)
+	}
+
+	writers.Wait()
+	stop.Store(true)
+	readers.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+	if searches.Load() == 0 {
+		t.Error("no search completed")
+	}
+	if report, err := idx.Verify(); err != nil || !report.OK() {
+		t.Errorf("verify: got %+v, %v", report, err)
+	}
+}
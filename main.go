@@ -2,9 +2,18 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	hamfts "hamfts/elasticsearch"
 )
@@ -12,6 +21,120 @@ import (
 type SearchRequest struct {
 	Query        string `json:"query"`
 	ContainsMode bool   `json:"containsMode,omitempty"`
+	// Suggest switches the response to a SearchResponse that carries
+	// "did you mean" corrections when there are fewer than SuggestBelow
+	// hits (default 1, i.e. no hits)
+	Suggest      bool `json:"suggest,omitempty"`
+	SuggestBelow int  `json:"suggestBelow,omitempty"`
+}
+
+type SearchResponse struct {
+	Total   int                `json:"total"`
+	Hits    []*hamfts.Document `json:"hits"`
+	Suggest *hamfts.DidYouMean `json:"suggest,omitempty"`
+}
+
+// QueryRequest runs Query against the current index, or against a point in
+// time opened with POST /_pit. From and Size page through the hits; a Size
+// of zero returns all of them.
+type QueryRequest struct {
+	Query hamfts.Query `json:"query"`
+	Pit   *PitRequest  `json:"pit,omitempty"`
+	From  int          `json:"from,omitempty"`
+	Size  int          `json:"size,omitempty"`
+}
+
+type PitRequest struct {
+	ID        string `json:"id"`
+	KeepAlive string `json:"keep_alive,omitempty"` // extends the point in time, e.g. "1m"
+}
+
+type QueryResponse struct {
+	Total int                `json:"total"`
+	Hits  []*hamfts.Document `json:"hits"`
+	PitID string             `json:"pit_id,omitempty"`
+}
+
+// BulkDocument is one line of a POST /_bulk body. It takes the lines
+// /_export writes as well, keeping their creation time.
+type BulkDocument struct {
+	ID        string                 `json:"id"`
+	Content   string                 `json:"content"`
+	CreatedAt time.Time              `json:"createdAt"`
+	Meta      map[string]interface{} `json:"metadata,omitempty"`
+}
+
+type BulkResponse struct {
+	Indexed int `json:"indexed"`
+}
+
+// ExportRequest selects the documents POST /_export streams; all of them
+// when Query is unset
+type ExportRequest struct {
+	Query *hamfts.Query `json:"query,omitempty"`
+}
+
+type AnalyzeRequest struct {
+	Analyzer string `json:"analyzer,omitempty"`
+	Text     string `json:"text"`
+}
+
+type SettingsRequest struct {
+	Analysis hamfts.AnalysisSettings `json:"analysis"`
+}
+
+type MultiGetRequest struct {
+	IDs    []string             `json:"ids"`
+	Source *hamfts.SourceFilter `json:"_source,omitempty"`
+}
+
+type MultiGetItem struct {
+	ID    string           `json:"_id"`
+	Found bool             `json:"found"`
+	Doc   *hamfts.Document `json:"doc,omitempty"`
+}
+
+type MultiGetResponse struct {
+	Docs []MultiGetItem `json:"docs"`
+}
+
+// ErrorResponse is the body of every error reply
+type ErrorResponse struct {
+	Error  ErrorDetail `json:"error"`
+	Status int         `json:"status"`
+}
+
+type ErrorDetail struct {
+	Type   string `json:"type"` // not_found, conflict, invalid_query, corrupt, ...
+	Reason string `json:"reason"`
+}
+
+type ByQueryRequest struct {
+	Query hamfts.Query  `json:"query"`
+	Patch *hamfts.Patch `json:"patch,omitempty"` // for _update_by_query
+}
+
+type RestoreRequest struct {
+	Target string `json:"target"` // directory for the restored index
+}
+
+// ReindexRequest rebuilds the index in place when Dest is unset, switching
+// it to Analysis when that is set, or copies the documents matching
+// Source.Query into the index directory Dest.Index
+type ReindexRequest struct {
+	Source   *ReindexSource           `json:"source,omitempty"`
+	Dest     *ReindexDest             `json:"dest,omitempty"`
+	Analysis *hamfts.AnalysisSettings `json:"analysis,omitempty"`
+}
+
+type ReindexSource struct {
+	Query hamfts.Query `json:"query"`
+}
+
+type ReindexDest struct {
+	Index    string                   `json:"index"`              // directory, created when missing
+	Analysis *hamfts.AnalysisSettings `json:"analysis,omitempty"` // for a new index
+	Mapping  *hamfts.Mapping          `json:"mapping,omitempty"`
 }
 
 type DocumentRequest struct {
@@ -20,43 +143,257 @@ type DocumentRequest struct {
 	Meta    map[string]interface{} `json:"metadata,omitempty"`
 }
 
+// searchIndex is what the server needs of an index, sharded or not.
+// Operations only a single Index has go through unsharded.
+type searchIndex interface {
+	AddDocument(doc *hamfts.Document) error
+	AddDocuments(docs []*hamfts.Document) error
+	GetDocument(id string) (*hamfts.Document, error)
+	MultiGet(ids []string) ([]*hamfts.Document, error)
+	DeleteDocument(id string) error
+	ListDocumentIDs() []string
+	Search(query string, containsMode bool) ([]*hamfts.Document, error)
+	PatternSearch(pattern string) ([]*hamfts.Document, error)
+	SearchQuery(q hamfts.Query) ([]*hamfts.Document, error)
+	Iterate(q hamfts.Query, fn func(*hamfts.Document) error) error
+	DidYouMean(text string) hamfts.DidYouMean
+	MoreLikeThis(q hamfts.MoreLikeThisQuery) ([]*hamfts.Document, error)
+	Suggest(req hamfts.SuggestRequest) ([]hamfts.Suggestion, error)
+	OpenPointInTime(keepAlive time.Duration) (string, error)
+	ClosePointInTime(id string) error
+	SearchQueryPage(q hamfts.Query, from, size int) ([]*hamfts.Document, int, error)
+	SearchQueryAtPage(id string, keepAlive time.Duration, q hamfts.Query, from, size int) ([]*hamfts.Document, int, error)
+	GetMapping() hamfts.Mapping
+	PutMapping(m hamfts.Mapping) error
+	GetAnalysis() hamfts.AnalysisSettings
+	Analyze(name, text string) ([]string, error)
+	StartCompact() (*hamfts.Task, error)
+	DeleteByQuery(q hamfts.Query) (*hamfts.Task, error)
+	UpdateByQuery(q hamfts.Query, patch hamfts.Patch) (*hamfts.Task, error)
+	Task(id string) (hamfts.TaskInfo, error)
+	Tasks() ([]hamfts.TaskInfo, error)
+	CancelTask(id string) error
+	Verify() (*hamfts.VerifyReport, error)
+	Repair() (*hamfts.VerifyReport, error)
+	ReloadSearchAnalyzers() ([]string, error)
+	GetStats() map[string]interface{}
+	Close() error
+}
+
+const dataDir = "./data"
+
 func main() {
 	// Initialize the search index
-	idx, err := hamfts.NewIndex("./data")
+	opts := hamfts.IndexOptions{
+		Dynamic: hamfts.DynamicMode(os.Getenv("HAMFTS_DYNAMIC")),
+	}
+	if analyzer := os.Getenv("HAMFTS_ANALYZER"); analyzer != "" {
+		opts.Analysis = &hamfts.AnalysisSettings{Analyzer: analyzer}
+	}
+	// HAMFTS_SHARDS splits a new index into that many shards; an index
+	// created sharded is opened sharded without it
+	shards := 0
+	if s := os.Getenv("HAMFTS_SHARDS"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 {
+			log.Fatalf("Invalid HAMFTS_SHARDS %q", s)
+		}
+		shards = n
+	}
+	_, err := os.Stat(filepath.Join(dataDir, "shards.json"))
+	var idx searchIndex
+	if shards > 0 || err == nil {
+		idx, err = hamfts.NewShardedIndex(dataDir, shards, opts)
+	} else {
+		idx, err = hamfts.NewIndexWithOptions(dataDir, opts)
+	}
 	if err != nil {
 		log.Fatalf("Failed to initialize index: %v", err)
 	}
 	defer idx.Close()
 
+	snapshotRepo := os.Getenv("HAMFTS_SNAPSHOT_REPO")
+	if snapshotRepo == "" {
+		snapshotRepo = "./snapshots"
+	}
+
+	// Reindex destinations and restore targets live under one root, and the
+	// served index counts as open so nothing else writes to it
+	indexRoot := os.Getenv("HAMFTS_INDEX_ROOT")
+	if indexRoot == "" {
+		indexRoot = "./indexes"
+	}
+	indexes, err := newIndexDirs(indexRoot)
+	if err != nil {
+		log.Fatalf("Failed to resolve index root: %v", err)
+	}
+	if err := indexes.hold(dataDir); err != nil {
+		log.Fatalf("Failed to register index: %v", err)
+	}
+
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080"
+	}
+
+	log.Printf("Server starting on port %s", port)
+	if err := http.ListenAndServe(":"+port, newHandler(idx, indexes, snapshotRepo)); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// newHandler serves the HTTP API of idx. Reindex destinations and restore
+// targets are claimed from indexes; snapshots go to snapshotRepo.
+func newHandler(idx searchIndex, indexes *indexDirs, snapshotRepo string) http.Handler {
+	mux := http.NewServeMux()
+
 	// Search endpoint
-	http.HandleFunc("/search", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/search", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed)
 			return
 		}
 
 		var req SearchRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			writeError(w, http.StatusBadRequest, err)
 			return
 		}
 
 		results, err := idx.Search(req.Query, req.ContainsMode)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeError(w, errorStatus(err, http.StatusInternalServerError), err)
+			return
+		}
+
+		if !req.Suggest {
+			json.NewEncoder(w).Encode(results)
 			return
 		}
 
-		json.NewEncoder(w).Encode(results)
+		resp := SearchResponse{Total: len(results), Hits: results}
+		below := req.SuggestBelow
+		if below <= 0 {
+			below = 1
+		}
+		if len(results) < below {
+			suggest := idx.DidYouMean(req.Query)
+			resp.Suggest = &suggest
+		}
+		json.NewEncoder(w).Encode(resp)
+	})
+
+	// Structured query endpoint
+	mux.HandleFunc("/_search", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed)
+			return
+		}
+
+		var req QueryRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if req.From < 0 || req.Size < 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("%w: from and size cannot be negative", hamfts.ErrInvalidQuery))
+			return
+		}
+
+		var resp QueryResponse
+		var err error
+		if req.Pit != nil {
+			var keepAlive time.Duration
+			if keepAlive, err = parseKeepAlive(req.Pit.KeepAlive); err != nil {
+				writeError(w, http.StatusBadRequest, err)
+				return
+			}
+			resp.Hits, resp.Total, err = idx.SearchQueryAtPage(req.Pit.ID, keepAlive, req.Query, req.From, req.Size)
+			resp.PitID = req.Pit.ID
+		} else {
+			resp.Hits, resp.Total, err = idx.SearchQueryPage(req.Query, req.From, req.Size)
+		}
+		if err != nil {
+			writeError(w, errorStatus(err, http.StatusInternalServerError), err)
+			return
+		}
+		json.NewEncoder(w).Encode(resp)
+	})
+
+	// Point in time endpoints: POST /_pit?keep_alive=1m opens one for
+	// /_search to page through, DELETE /_pit with {"id": ...} closes it
+	mux.HandleFunc("/_pit", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			keepAlive, err := parseKeepAlive(r.URL.Query().Get("keep_alive"))
+			if err == nil && keepAlive == 0 {
+				err = fmt.Errorf("%w: keep_alive is required", hamfts.ErrInvalidQuery)
+			}
+			if err != nil {
+				writeError(w, http.StatusBadRequest, err)
+				return
+			}
+			id, err := idx.OpenPointInTime(keepAlive)
+			if err != nil {
+				writeError(w, errorStatus(err, http.StatusInternalServerError), err)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]string{"id": id})
+		case http.MethodDelete:
+			var req PitRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				writeError(w, http.StatusBadRequest, err)
+				return
+			}
+			if err := idx.ClosePointInTime(req.ID); err != nil {
+				writeError(w, errorStatus(err, http.StatusInternalServerError), err)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]bool{"succeeded": true})
+		default:
+			writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed)
+		}
+	})
+
+	// Bulk endpoint: adds the NDJSON documents of the body in one batch
+	mux.HandleFunc("/_bulk", func(w http.ResponseWriter, r *http.Request) {
+		handleBulk(w, r, idx)
+	})
+
+	// Export endpoint: streams every matching document as NDJSON
+	mux.HandleFunc("/_export", func(w http.ResponseWriter, r *http.Request) {
+		handleExport(w, r, idx)
+	})
+
+	// Completion suggester endpoint
+	mux.HandleFunc("/_suggest", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed)
+			return
+		}
+
+		var req hamfts.SuggestRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		suggestions, err := idx.Suggest(req)
+		if err != nil {
+			writeError(w, errorStatus(err, http.StatusInternalServerError), err)
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string][]hamfts.Suggestion{"suggestions": suggestions})
 	})
 
 	// Add document endpoint
-	http.HandleFunc("/documents", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/documents", func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodPost:
 			var req DocumentRequest
 			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-				http.Error(w, err.Error(), http.StatusBadRequest)
+				writeError(w, http.StatusBadRequest, err)
 				return
 			}
 
@@ -64,7 +401,7 @@ func main() {
 			doc.Metadata = req.Meta
 
 			if err := idx.AddDocument(doc); err != nil {
-				http.Error(w, err.Error(), http.StatusInternalServerError)
+				writeError(w, errorStatus(err, http.StatusInternalServerError), err)
 				return
 			}
 
@@ -75,30 +412,388 @@ func main() {
 			json.NewEncoder(w).Encode(docs)
 
 		default:
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed)
 		}
 	})
 
-	// Delete document endpoint
-	http.HandleFunc("/documents/", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != http.MethodDelete {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	// Single document endpoint
+	mux.HandleFunc("/documents/", func(w http.ResponseWriter, r *http.Request) {
+		// IDs may hold escaped slashes, so the path is split before it is
+		// unescaped
+		rest, mlt := strings.CutSuffix(strings.TrimPrefix(r.URL.EscapedPath(), "/documents/"), "/_mlt")
+		id, err := url.PathUnescape(rest)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("%w: %v", hamfts.ErrInvalidQuery, err))
+			return
+		}
+		if mlt {
+			handleMoreLikeThis(w, r, idx, id)
 			return
 		}
 
-		id := r.URL.Path[len("/documents/"):]
-		if err := idx.DeleteDocument(id); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+			doc, err := idx.GetDocument(id)
+			if err != nil {
+				status := errorStatus(err, http.StatusInternalServerError)
+				if r.Method == http.MethodHead {
+					// HEAD responses have no body, only the status
+					w.WriteHeader(status)
+					return
+				}
+				writeError(w, status, err)
+				return
+			}
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusOK)
+				return
+			}
+
+			json.NewEncoder(w).Encode(sourceFilter(r).Apply(doc))
+
+		case http.MethodDelete:
+			if err := idx.DeleteDocument(id); err != nil {
+				writeError(w, errorStatus(err, http.StatusInternalServerError), err)
+				return
+			}
+
+			w.WriteHeader(http.StatusNoContent)
+
+		default:
+			writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed)
+		}
+	})
+
+	// Multi-get endpoint
+	mux.HandleFunc("/_mget", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed)
+			return
+		}
+
+		var req MultiGetRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		docs, err := idx.MultiGet(req.IDs)
+		if err != nil {
+			writeError(w, errorStatus(err, http.StatusInternalServerError), err)
 			return
 		}
 
-		w.WriteHeader(http.StatusNoContent)
+		filter := sourceFilter(r)
+		if req.Source != nil {
+			filter = *req.Source
+		}
+		resp := MultiGetResponse{Docs: make([]MultiGetItem, len(docs))}
+		for i, doc := range docs {
+			resp.Docs[i] = MultiGetItem{ID: req.IDs[i], Found: doc != nil}
+			if doc != nil {
+				resp.Docs[i].Doc = filter.Apply(doc)
+			}
+		}
+		json.NewEncoder(w).Encode(resp)
+	})
+
+	// Mapping endpoint
+	mux.HandleFunc("/_mapping", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			json.NewEncoder(w).Encode(idx.GetMapping())
+
+		case http.MethodPut:
+			var mapping hamfts.Mapping
+			if err := json.NewDecoder(r.Body).Decode(&mapping); err != nil {
+				writeError(w, http.StatusBadRequest, err)
+				return
+			}
+
+			if err := idx.PutMapping(mapping); err != nil {
+				writeError(w, errorStatus(err, http.StatusInternalServerError), err)
+				return
+			}
+
+			json.NewEncoder(w).Encode(idx.GetMapping())
+
+		default:
+			writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed)
+		}
+	})
+
+	// Analyze endpoint
+	mux.HandleFunc("/_analyze", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed)
+			return
+		}
+
+		var req AnalyzeRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		tokens, err := idx.Analyze(req.Analyzer, req.Text)
+		if err != nil {
+			writeError(w, errorStatus(err, http.StatusInternalServerError), err)
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string][]string{"tokens": tokens})
+	})
+
+	// Analysis settings endpoint
+	mux.HandleFunc("/_settings", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			json.NewEncoder(w).Encode(map[string]hamfts.AnalysisSettings{"analysis": idx.GetAnalysis()})
+
+		case http.MethodPut:
+			var req SettingsRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				writeError(w, http.StatusBadRequest, err)
+				return
+			}
+
+			single, err := unsharded(idx)
+			if err != nil {
+				writeError(w, errorStatus(err, http.StatusInternalServerError), err)
+				return
+			}
+			if err := single.UpdateAnalysis(req.Analysis); err != nil {
+				writeError(w, errorStatus(err, http.StatusInternalServerError), err)
+				return
+			}
+
+			json.NewEncoder(w).Encode(map[string]hamfts.AnalysisSettings{"analysis": idx.GetAnalysis()})
+
+		default:
+			writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed)
+		}
+	})
+
+	// Reload updateable synonym filters after their files changed
+	mux.HandleFunc("/_reload_search_analyzers", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed)
+			return
+		}
+
+		reloaded, err := idx.ReloadSearchAnalyzers()
+		if err != nil {
+			writeError(w, errorStatus(err, http.StatusInternalServerError), err)
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string][]string{"reloaded_analyzers": reloaded})
+	})
+
+	// By-query endpoints run as tasks; the response carries the task ID
+	// unless wait_for_completion=true asks for the finished task
+	mux.HandleFunc("/_delete_by_query", func(w http.ResponseWriter, r *http.Request) {
+		handleByQuery(w, r, func(req ByQueryRequest) (*hamfts.Task, error) {
+			return idx.DeleteByQuery(req.Query)
+		})
+	})
+	mux.HandleFunc("/_update_by_query", func(w http.ResponseWriter, r *http.Request) {
+		handleByQuery(w, r, func(req ByQueryRequest) (*hamfts.Task, error) {
+			if req.Patch == nil {
+				return nil, fmt.Errorf("%w: _update_by_query needs a patch", hamfts.ErrInvalidQuery)
+			}
+			return idx.UpdateByQuery(req.Query, *req.Patch)
+		})
+	})
+
+	// Compaction endpoint, runs as a task
+	mux.HandleFunc("/_compact", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed)
+			return
+		}
+
+		task, err := idx.StartCompact()
+		writeTask(w, r, task, err)
+	})
+
+	// Reindex endpoint, runs as a task
+	mux.HandleFunc("/_reindex", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed)
+			return
+		}
+
+		var req ReindexRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		single, err := unsharded(idx)
+		if err != nil {
+			writeError(w, errorStatus(err, http.StatusInternalServerError), err)
+			return
+		}
+		task, err := startReindex(single, indexes, req)
+		writeTask(w, r, task, err)
+	})
+
+	// Integrity endpoints: GET /_check verifies, POST /_check/_repair repairs
+	mux.HandleFunc("/_check", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed)
+			return
+		}
+
+		report, err := idx.Verify()
+		if err != nil {
+			writeError(w, errorStatus(err, http.StatusInternalServerError), err)
+			return
+		}
+
+		json.NewEncoder(w).Encode(report)
+	})
+	mux.HandleFunc("/_check/_repair", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed)
+			return
+		}
+
+		report, err := idx.Repair()
+		if err != nil {
+			writeError(w, errorStatus(err, http.StatusInternalServerError), err)
+			return
+		}
+
+		json.NewEncoder(w).Encode(report)
+	})
+
+	// Task endpoints: GET /_tasks, GET /_tasks/{id}, POST /_tasks/{id}/_cancel
+	mux.HandleFunc("/_tasks", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed)
+			return
+		}
+
+		tasks, err := idx.Tasks()
+		if err != nil {
+			writeError(w, errorStatus(err, http.StatusInternalServerError), err)
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string][]hamfts.TaskInfo{"tasks": tasks})
+	})
+	mux.HandleFunc("/_tasks/", func(w http.ResponseWriter, r *http.Request) {
+		id, cancel := strings.CutSuffix(r.URL.Path[len("/_tasks/"):], "/_cancel")
+		switch {
+		case cancel && r.Method == http.MethodPost:
+			if err := idx.CancelTask(id); err != nil {
+				writeError(w, errorStatus(err, http.StatusInternalServerError), err)
+				return
+			}
+			fallthrough
+		case !cancel && r.Method == http.MethodGet:
+			info, err := idx.Task(id)
+			if err != nil {
+				writeError(w, errorStatus(err, http.StatusInternalServerError), err)
+				return
+			}
+			json.NewEncoder(w).Encode(info)
+
+		default:
+			writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed)
+		}
+	})
+
+	// Snapshot endpoints: GET /_snapshot lists the snapshots in the repository
+	mux.HandleFunc("/_snapshot", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed)
+			return
+		}
+
+		snapshots, err := hamfts.ListSnapshots(snapshotRepo)
+		if err != nil {
+			writeError(w, errorStatus(err, http.StatusInternalServerError), err)
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string][]*hamfts.SnapshotInfo{"snapshots": snapshots})
+	})
+
+	// PUT, GET and DELETE /_snapshot/{name}, POST /_snapshot/{name}/_restore
+	mux.HandleFunc("/_snapshot/", func(w http.ResponseWriter, r *http.Request) {
+		name, restore := strings.CutSuffix(r.URL.Path[len("/_snapshot/"):], "/_restore")
+		switch {
+		case restore && r.Method == http.MethodPost:
+			var req RestoreRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				writeError(w, http.StatusBadRequest, err)
+				return
+			}
+			if req.Target == "" {
+				writeError(w, http.StatusBadRequest, fmt.Errorf("%w: restore needs a target directory", hamfts.ErrInvalidQuery))
+				return
+			}
+			target, release, err := indexes.claim(req.Target)
+			if err != nil {
+				writeError(w, errorStatus(err, http.StatusInternalServerError), err)
+				return
+			}
+
+			err = hamfts.Restore(snapshotRepo, name, target)
+			release()
+			if err != nil {
+				writeError(w, errorStatus(err, http.StatusInternalServerError), err)
+				return
+			}
+
+			json.NewEncoder(w).Encode(map[string]string{"snapshot": name, "target": req.Target})
+
+		case restore:
+			writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed)
+
+		case r.Method == http.MethodPut:
+			single, err := unsharded(idx)
+			if err != nil {
+				writeError(w, errorStatus(err, http.StatusInternalServerError), err)
+				return
+			}
+			info, err := single.Snapshot(snapshotRepo, name)
+			if err != nil {
+				writeError(w, errorStatus(err, http.StatusInternalServerError), err)
+				return
+			}
+
+			json.NewEncoder(w).Encode(info)
+
+		case r.Method == http.MethodGet:
+			info, err := hamfts.GetSnapshot(snapshotRepo, name)
+			if err != nil {
+				writeError(w, errorStatus(err, http.StatusInternalServerError), err)
+				return
+			}
+
+			json.NewEncoder(w).Encode(info)
+
+		case r.Method == http.MethodDelete:
+			if err := hamfts.DeleteSnapshot(snapshotRepo, name); err != nil {
+				writeError(w, errorStatus(err, http.StatusInternalServerError), err)
+				return
+			}
+
+			w.WriteHeader(http.StatusNoContent)
+
+		default:
+			writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed)
+		}
 	})
 
 	// Stats endpoint
-	http.HandleFunc("/stats", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/stats", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed)
 			return
 		}
 
@@ -106,13 +801,364 @@ func main() {
 		json.NewEncoder(w).Encode(stats)
 	})
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	return mux
+}
+
+// sourceFilter reads the _source, _source_includes and _source_excludes
+// parameters. _source is either true, false or a list of fields to include.
+func sourceFilter(r *http.Request) hamfts.SourceFilter {
+	var filter hamfts.SourceFilter
+	params := r.URL.Query()
+	switch source := params.Get("_source"); source {
+	case "", "true":
+	case "false":
+		filter.Disabled = true
+	default:
+		filter.Includes = strings.Split(source, ",")
+	}
+	if includes := params.Get("_source_includes"); includes != "" {
+		filter.Includes = append(filter.Includes, strings.Split(includes, ",")...)
+	}
+	if excludes := params.Get("_source_excludes"); excludes != "" {
+		filter.Excludes = strings.Split(excludes, ",")
 	}
+	return filter
+}
 
-	log.Printf("Server starting on port %s", port)
-	if err := http.ListenAndServe(":"+port, nil); err != nil {
-		log.Fatal(err)
+// handleMoreLikeThis serves GET /documents/{id}/_mlt with optional size and
+// comma separated fields parameters
+func handleMoreLikeThis(w http.ResponseWriter, r *http.Request, idx searchIndex, id string) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed)
+		return
 	}
+
+	q := hamfts.MoreLikeThisQuery{ID: id}
+	if size := r.URL.Query().Get("size"); size != "" {
+		n, err := strconv.Atoi(size)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("%w: invalid size %q", hamfts.ErrInvalidQuery, size))
+			return
+		}
+		q.Size = n
+	}
+	if fields := r.URL.Query().Get("fields"); fields != "" {
+		q.Fields = strings.Split(fields, ",")
+	}
+
+	docs, err := idx.MoreLikeThis(q)
+	if err != nil {
+		writeError(w, errorStatus(err, http.StatusInternalServerError), err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(QueryResponse{Total: len(docs), Hits: docs})
+}
+
+// handleBulk serves POST /_bulk. The documents are added with
+// AddDocuments, so one conflicting with the mapping rejects them all.
+func handleBulk(w http.ResponseWriter, r *http.Request, idx searchIndex) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed)
+		return
+	}
+
+	var docs []*hamfts.Document
+	dec := json.NewDecoder(r.Body)
+	for line := 1; ; line++ {
+		var req BulkDocument
+		if err := dec.Decode(&req); err == io.EOF {
+			break
+		} else if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("%w: document %d: %v", hamfts.ErrInvalidQuery, line, err))
+			return
+		}
+		if req.ID == "" {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("%w: document %d has no id", hamfts.ErrInvalidQuery, line))
+			return
+		}
+		doc := hamfts.NewDocument(req.ID, req.Content)
+		if !req.CreatedAt.IsZero() {
+			doc.CreatedAt = req.CreatedAt
+		}
+		if req.Meta != nil {
+			doc.Metadata = req.Meta
+		}
+		docs = append(docs, doc)
+	}
+
+	if err := idx.AddDocuments(docs); err != nil {
+		writeError(w, errorStatus(err, http.StatusInternalServerError), err)
+		return
+	}
+	json.NewEncoder(w).Encode(BulkResponse{Indexed: len(docs)})
+}
+
+// exportFlushEvery is how many documents /_export writes between flushes
+const exportFlushEvery = 100
+
+// handleExport serves POST /_export, writing one document per line as they
+// are read, with the same _source parameters as GET /documents/{id}. Errors
+// before the first document get the usual error reply; one met later ends
+// the stream with an ErrorResponse line, the status being sent already.
+func handleExport(w http.ResponseWriter, r *http.Request, idx searchIndex) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed)
+		return
+	}
+
+	var req ExportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	q := hamfts.Query{MatchAll: &hamfts.MatchAllQuery{}}
+	if req.Query != nil {
+		q = *req.Query
+	}
+
+	filter := sourceFilter(r)
+	flusher, _ := w.(http.Flusher)
+	enc := json.NewEncoder(w)
+	written := 0
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	err := idx.Iterate(q, func(doc *hamfts.Document) error {
+		if err := enc.Encode(filter.Apply(doc)); err != nil {
+			return err
+		}
+		written++
+		if flusher != nil && written%exportFlushEvery == 0 {
+			flusher.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		if written == 0 {
+			writeError(w, errorStatus(err, http.StatusInternalServerError), err)
+			return
+		}
+		status := errorStatus(err, http.StatusInternalServerError)
+		enc.Encode(ErrorResponse{
+			Error:  ErrorDetail{Type: errorType(err, status), Reason: err.Error()},
+			Status: status,
+		})
+	}
+}
+
+// handleByQuery decodes a ByQueryRequest and starts the task
+func handleByQuery(w http.ResponseWriter, r *http.Request, start func(ByQueryRequest) (*hamfts.Task, error)) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed)
+		return
+	}
+
+	var req ByQueryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	task, err := start(req)
+	writeTask(w, r, task, err)
+}
+
+// startReindex starts an in-place reindex, or opens the destination index
+// and copies documents into it, closing it once the task is done
+func startReindex(idx *hamfts.Index, indexes *indexDirs, req ReindexRequest) (*hamfts.Task, error) {
+	if req.Dest == nil {
+		if req.Source != nil {
+			return nil, fmt.Errorf("%w: a source query needs a dest index", hamfts.ErrInvalidQuery)
+		}
+		if req.Analysis != nil {
+			return idx.StartReindexWithAnalysis(*req.Analysis)
+		}
+		return idx.StartReindex()
+	}
+	if req.Analysis != nil {
+		return nil, fmt.Errorf("%w: analysis is for reindexing in place, set dest.analysis instead", hamfts.ErrInvalidQuery)
+	}
+
+	if req.Dest.Index == "" {
+		return nil, fmt.Errorf("%w: dest.index is required", hamfts.ErrInvalidQuery)
+	}
+	dest, release, err := indexes.claim(req.Dest.Index)
+	if err != nil {
+		return nil, err
+	}
+	destIdx, err := hamfts.NewIndexWithOptions(dest, hamfts.IndexOptions{Analysis: req.Dest.Analysis})
+	if err != nil {
+		release()
+		return nil, err
+	}
+	closeDest := func() {
+		destIdx.Close()
+		release()
+	}
+	if req.Dest.Mapping != nil {
+		if err := destIdx.PutMapping(*req.Dest.Mapping); err != nil {
+			closeDest()
+			return nil, err
+		}
+	}
+
+	q := hamfts.Query{MatchAll: &hamfts.MatchAllQuery{}}
+	if req.Source != nil {
+		q = req.Source.Query
+	}
+	task, err := idx.ReindexInto(destIdx, q)
+	if err != nil {
+		closeDest()
+		return nil, err
+	}
+	go func() {
+		task.Wait()
+		closeDest()
+	}()
+	return task, nil
+}
+
+// indexDirs confines the index directories named in requests to one root and
+// tracks which of them are open, since two Index instances writing the same
+// directory would corrupt it
+type indexDirs struct {
+	root string
+
+	mu   sync.Mutex
+	open map[string]bool
+}
+
+func newIndexDirs(root string) (*indexDirs, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	return &indexDirs{root: root, open: make(map[string]bool)}, nil
+}
+
+// hold marks dir as open for the life of the process
+func (d *indexDirs) hold(dir string) error {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.open[dir] = true
+	return nil
+}
+
+// claim resolves name, relative to the root unless absolute, and marks it
+// open until release is called. Paths outside the root are invalid, and a
+// directory that is already open is a conflict.
+func (d *indexDirs) claim(name string) (dir string, release func(), err error) {
+	dir = name
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(d.root, dir)
+	}
+	dir = filepath.Clean(dir)
+	if rel, err := filepath.Rel(d.root, dir); err != nil || rel == "." || !filepath.IsLocal(rel) {
+		return "", nil, fmt.Errorf("%w: index %s is outside %s", hamfts.ErrInvalidQuery, name, d.root)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.open[dir] {
+		return "", nil, fmt.Errorf("%w: index %s is already open", hamfts.ErrConflict, name)
+	}
+	d.open[dir] = true
+	return dir, func() {
+		d.mu.Lock()
+		delete(d.open, dir)
+		d.mu.Unlock()
+	}, nil
+}
+
+// writeTask replies with the ID of a started task, or with the finished task
+// when wait_for_completion=true
+func writeTask(w http.ResponseWriter, r *http.Request, task *hamfts.Task, err error) {
+	if err != nil {
+		writeError(w, errorStatus(err, http.StatusInternalServerError), err)
+		return
+	}
+
+	if r.URL.Query().Get("wait_for_completion") == "true" {
+		json.NewEncoder(w).Encode(task.Wait())
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{"task": task.Info().ID})
+}
+
+// errMethodNotAllowed is reported for unsupported HTTP methods
+var errMethodNotAllowed = errors.New("method not allowed")
+
+// unsharded returns idx for the operations only a single Index has:
+// reindexing, snapshots and changing analysis settings
+func unsharded(idx searchIndex) (*hamfts.Index, error) {
+	single, ok := idx.(*hamfts.Index)
+	if !ok {
+		return nil, fmt.Errorf("%w on a sharded index", errors.ErrUnsupported)
+	}
+	return single, nil
+}
+
+// parseKeepAlive reads a keep alive such as "30s" or "5m"; empty is zero
+func parseKeepAlive(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid keep_alive %q", hamfts.ErrInvalidQuery, s)
+	}
+	return d, nil
+}
+
+// errorStatus maps the index's sentinel errors to HTTP status codes, and
+// anything else to fallback
+func errorStatus(err error, fallback int) int {
+	switch {
+	case errors.Is(err, hamfts.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, hamfts.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, hamfts.ErrInvalidQuery):
+		return http.StatusBadRequest
+	case errors.Is(err, hamfts.ErrCorrupt):
+		return http.StatusInternalServerError
+	case errors.Is(err, errors.ErrUnsupported):
+		return http.StatusNotImplemented
+	}
+	return fallback
+}
+
+// errorType names the kind of error in ErrorResponse
+func errorType(err error, status int) string {
+	switch {
+	case errors.Is(err, hamfts.ErrNotFound):
+		return "not_found"
+	case errors.Is(err, hamfts.ErrConflict):
+		return "conflict"
+	case errors.Is(err, hamfts.ErrInvalidQuery):
+		return "invalid_query"
+	case errors.Is(err, hamfts.ErrCorrupt):
+		return "corrupt"
+	case errors.Is(err, errors.ErrUnsupported):
+		return "unsupported"
+	case status == http.StatusMethodNotAllowed:
+		return "method_not_allowed"
+	case status < http.StatusInternalServerError:
+		return "bad_request"
+	}
+	return "internal_error"
+}
+
+// writeError replies with an ErrorResponse
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(ErrorResponse{
+		Error:  ErrorDetail{Type: errorType(err, status), Reason: err.Error()},
+		Status: status,
+	})
 }
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"hamfts/client"
+	hamfts "hamfts/elasticsearch"
+)
+
+// newTestServer serves a new index in a temporary directory
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return serveTestIndex(t, func(dir string) (searchIndex, error) { return hamfts.NewIndex(dir) })
+}
+
+// serveTestIndex serves the index open creates in a temporary directory
+func serveTestIndex(t *testing.T, open func(dir string) (searchIndex, error)) *httptest.Server {
+	t.Helper()
+	testDir, err := os.MkdirTemp("", "hamfts_test_server")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(testDir) })
+
+	idx, err := open(filepath.Join(testDir, "data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { idx.Close() })
+	indexes, err := newIndexDirs(filepath.Join(testDir, "indexes"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := indexes.hold(filepath.Join(testDir, "data")); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer(newHandler(idx, indexes, filepath.Join(testDir, "snapshots")))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestErrorResponses(t *testing.T) {
+	srv := newTestServer(t)
+
+	if resp, err := http.Post(srv.URL+"/documents", "application/json", strings.NewReader(`{"id": "1", "content": "x", "metadata": {"n": 1}}`)); err != nil {
+		t.Fatal(err)
+	} else {
+		resp.Body.Close()
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+		typ    string
+	}{
+		{"missing document", http.MethodGet, "/documents/missing", "", http.StatusNotFound, "not_found"},
+		{"mapping conflict", http.MethodPost, "/documents", `{"id": "2", "content": "x", "metadata": {"n": "text"}}`, http.StatusConflict, "conflict"},
+		{"invalid query", http.MethodPost, "/_search", `{"query": {"range": {"n": {}}}}`, http.StatusBadRequest, "invalid_query"},
+		{"malformed body", http.MethodPost, "/_search", `{`, http.StatusBadRequest, "bad_request"},
+		{"negative from", http.MethodPost, "/_search", `{"query": {"match_all": {}}, "from": -1}`, http.StatusBadRequest, "invalid_query"},
+		{"wrong method", http.MethodDelete, "/_search", "", http.StatusMethodNotAllowed, "method_not_allowed"},
+		{"reindex outside the root", http.MethodPost, "/_reindex", `{"dest": {"index": "../elsewhere"}}`, http.StatusBadRequest, "invalid_query"},
+	}
+	for _, tt := range tests {
+		req, err := http.NewRequest(tt.method, srv.URL+tt.path, strings.NewReader(tt.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var body ErrorResponse
+		err = json.NewDecoder(resp.Body).Decode(&body)
+		resp.Body.Close()
+		if err != nil {
+			t.Errorf("%s: the error body is not JSON: %v", tt.name, err)
+			continue
+		}
+		if resp.StatusCode != tt.status || body.Status != tt.status || body.Error.Type != tt.typ || body.Error.Reason == "" {
+			t.Errorf("%s: expected %d %s, got %d %+v", tt.name, tt.status, tt.typ, resp.StatusCode, body)
+		}
+	}
+}
+
+func TestDocumentEndpoints(t *testing.T) {
+	srv := newTestServer(t)
+	c := client.NewClient(srv.URL)
+
+	// IDs that mean something in a URL survive the round trip
+	ids := []string{"plain", "a/b", "what?", "50%", "x#y", "doc/_mlt"}
+	for _, id := range ids {
+		if err := c.AddDocument(id, "shared words for "+id, nil); err != nil {
+			t.Fatalf("add %q: %v", id, err)
+		}
+	}
+	for _, id := range ids {
+		doc, err := c.GetDocument(id)
+		if err != nil || doc["ID"] != id {
+			t.Errorf("get %q: got %v, %v", id, doc, err)
+		}
+		if exists, err := c.DocumentExists(id); err != nil || !exists {
+			t.Errorf("exists %q: got %v, %v", id, exists, err)
+		}
+	}
+	if hits, err := c.MoreLikeThis("a/b"); err != nil || len(hits) == 0 {
+		t.Errorf("more like this: got %v, %v", hits, err)
+	}
+
+	// HEAD answers with the status alone
+	resp, err := http.Head(srv.URL + "/documents/missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound || len(body) != 0 {
+		t.Errorf("HEAD of a missing document: got %d %q", resp.StatusCode, body)
+	}
+	if exists, err := c.DocumentExists("missing"); err != nil || exists {
+		t.Errorf("exists missing: got %v, %v", exists, err)
+	}
+
+	// Client errors match the library's
+	if _, err := c.GetDocument("missing"); !errors.Is(err, hamfts.ErrNotFound) {
+		t.Errorf("expected not found, got %v", err)
+	}
+	if err := c.DeleteDocument("a/b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DeleteDocument("a/b"); !errors.Is(err, hamfts.ErrNotFound) {
+		t.Errorf("expected deleting twice to be not found, got %v", err)
+	}
+	if err := c.PutMapping(map[string]interface{}{"properties": map[string]interface{}{"n": map[string]interface{}{"type": "long"}}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.AddDocument("bad", "x", map[string]interface{}{"n": "text"}); !errors.Is(err, hamfts.ErrConflict) {
+		t.Errorf("expected a conflict, got %v", err)
+	}
+	if _, err := c.SearchQuery(map[string]interface{}{"range": map[string]interface{}{"n": map[string]interface{}{}}}); !errors.Is(err, hamfts.ErrInvalidQuery) {
+		t.Errorf("expected an invalid query, got %v", err)
+	}
+}
+
+func TestSearchPaging(t *testing.T) {
+	srv := newTestServer(t)
+	c := client.NewClient(srv.URL)
+
+	var docs []client.BulkDocument
+	for _, id := range []string{"d1", "d2", "d3", "d4", "d5"} {
+		docs = append(docs, client.BulkDocument{ID: id, Content: "fox"})
+	}
+	if n, err := c.Bulk(docs); err != nil || n != 5 {
+		t.Fatalf("bulk: got %d, %v", n, err)
+	}
+
+	pit, err := c.OpenPointInTime("1m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.AddDocument("d6", "fox", nil); err != nil {
+		t.Fatal(err)
+	}
+	query := map[string]interface{}{"match": map[string]interface{}{"content": "fox"}}
+	hits, total, err := c.SearchQueryAt(pit, "1m", query, 3, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 5 || len(hits) != 2 || hits[0].(map[string]interface{})["ID"] != "d4" {
+		t.Errorf("expected d4 and d5 of 5, got %v of %d", hits, total)
+	}
+	if err := c.ClosePointInTime(pit); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := c.SearchQueryAt(pit, "", query, 0, 1); !errors.Is(err, hamfts.ErrNotFound) {
+		t.Errorf("expected a closed point in time to be not found, got %v", err)
+	}
+}
+
+func TestShardedEndpoints(t *testing.T) {
+	srv := serveTestIndex(t, func(dir string) (searchIndex, error) {
+		return hamfts.NewShardedIndex(dir, 3, hamfts.IndexOptions{})
+	})
+	c := client.NewClient(srv.URL)
+
+	var docs []client.BulkDocument
+	for i := 0; i < 12; i++ {
+		docs = append(docs, client.BulkDocument{ID: fmt.Sprintf("d%d", i), Content: "sharded fox", Meta: map[string]interface{}{"keep": i%2 == 0}})
+	}
+	if _, err := c.Bulk(docs); err != nil {
+		t.Fatal(err)
+	}
+
+	// Tasks run on every shard and are followed under one ID
+	wait := func(id string, err error) map[string]interface{} {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for {
+			info, err := c.GetTask(id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info["status"] != string(hamfts.TaskRunning) {
+				return info
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	deleted := wait(c.DeleteByQuery(map[string]interface{}{"term": map[string]interface{}{"keep": false}}))
+	if deleted["status"] != string(hamfts.TaskCompleted) || deleted["progress"].(map[string]interface{})["deleted"] != float64(6) {
+		t.Errorf("delete by query: got %v", deleted)
+	}
+	if compacted := wait(c.Compact()); compacted["status"] != string(hamfts.TaskCompleted) {
+		t.Errorf("compact: got %v", compacted)
+	}
+	if tasks, err := c.ListTasks(); err != nil || len(tasks) != 2 {
+		t.Errorf("expected 2 tasks, got %v, %v", tasks, err)
+	}
+	if report, err := c.Check(false); err != nil || report["documents"] != float64(6) {
+		t.Errorf("check: got %v, %v", report, err)
+	}
+	if _, err := c.ReloadSearchAnalyzers(); err != nil {
+		t.Errorf("reload search analyzers: %v", err)
+	}
+
+	// What a sharded index cannot do is reported as such
+	if _, err := c.Reindex(); !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("reindex: expected unsupported, got %v", err)
+	}
+	if _, err := c.CreateSnapshot("nightly"); !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("snapshot: expected unsupported, got %v", err)
+	}
+}